@@ -0,0 +1,109 @@
+// Command squad-emulator emulates a Squad server for integration tests of RCON tools, see package
+// emulator.
+//
+// Usage:
+//
+//	squad-emulator -password secret [-listen 127.0.0.1:21114] [-scenario scenario.json] [flags]
+//
+// Unless disabled using -autoplay=false, simulated players join and leave, create and join squads,
+// switch kits and chat. A scenario file scripts events in addition, see emulator.Scenario for the
+// format.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"squad-rcon-go/pkg/emulator"
+	"squad-rcon-go/pkg/rcon"
+	"syscall"
+	"time"
+)
+
+const passwordEnv = "SQUAD_RCON_PASSWORD"
+
+func main() {
+	var listen, password, scenarioPath string
+	var autoplay bool
+	var players int
+	var interval, matchLength time.Duration
+	var seed int64
+
+	flag.StringVar(&listen, "listen", "127.0.0.1:21114", "address to accept RCON clients on")
+	flag.StringVar(&password, "password", os.Getenv(passwordEnv), "RCON password (env "+passwordEnv+")")
+	flag.StringVar(&scenarioPath, "scenario", "", "JSON file scripting events")
+	flag.BoolVar(&autoplay, "autoplay", true, "simulate players")
+	flag.IntVar(&players, "players", 40, "number of simulated players")
+	flag.DurationVar(&interval, "interval", 2*time.Second, "time between simulated actions")
+	flag.DurationVar(&matchLength, "match-length", 30*time.Minute, "time after which simulated matches end")
+	flag.Int64Var(&seed, "seed", 0, "seed making the simulation reproducible, random if 0")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+
+	if password == "" {
+		fmt.Fprintln(os.Stderr, "-password (or "+passwordEnv+") is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var scenario emulator.Scenario
+	if scenarioPath != "" {
+		var err error
+		if scenario, err = emulator.ReadScenario(scenarioPath); err != nil {
+			logger.Fatalf("Could not read scenario: %v", err)
+		}
+	}
+
+	// The server is created after the simulation, which pushes messages only once the autoplay or
+	// scenario is started below.
+	var server *rcon.Server
+	simulation := emulator.NewSimulation(emulator.Settings{
+		Layers: scenario.Layers,
+		OnMessage: func(message string) {
+			server.Broadcast(message)
+		},
+	})
+	server = rcon.NewServer(simulation.Handler(password), rcon.ServerSettings{
+		OnError: func(err error) {
+			logger.Print(err)
+		},
+	})
+
+	if autoplay {
+		play := emulator.NewAutoplay(simulation, emulator.AutoplaySettings{
+			Interval:    interval,
+			Players:     players,
+			MatchLength: matchLength,
+			Seed:        seed,
+		})
+		defer play.Close()
+	}
+
+	if scenarioPath != "" {
+		player := emulator.NewScenarioPlayer(simulation, scenario, emulator.ScenarioPlayerSettings{
+			OnError: func(err error) {
+				logger.Printf("Scenario: %v", err)
+			},
+			OnDone: func() {
+				logger.Print("Scenario finished")
+			},
+		})
+		defer player.Close()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		_ = server.Close()
+	}()
+
+	logger.Printf("Emulating %s on %s", simulation.Layer().Name, listen)
+	if err := server.ListenAndServe(listen); !errors.Is(err, rcon.ErrServerClosed) {
+		logger.Fatalf("Could not serve: %v", err)
+	}
+}
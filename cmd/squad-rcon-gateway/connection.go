@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"squad-rcon-go/pkg/squadrcon"
+	"sync"
+)
+
+var errClosed = errors.New("gateway is shutting down")
+
+// connection is an rcon.Rcon that connects when a command is executed and there is no connection,
+// so that the gateway recovers from server restarts. Broadcasts are queued.
+type connection struct {
+	address  string
+	password string
+	settings squadrcon.Settings
+	logger   *log.Logger
+
+	broadcaster *squadrcon.Broadcaster
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// Nil while disconnected.
+	conn *squadrcon.SquadRcon
+
+	// Incremented on every connect, so that the disconnect of a replaced connection is ignored.
+	generation int
+
+	closed bool
+}
+
+func newConnection(address string, password string, settings squadrcon.Settings, logger *log.Logger) *connection {
+	c := &connection{
+		address:  address,
+		password: password,
+		settings: settings,
+		logger:   logger,
+	}
+	c.broadcaster = squadrcon.NewBroadcaster(c, settings.Broadcast)
+	return c
+}
+
+// get returns the connection, connecting if needed.
+func (c *connection) get() (*squadrcon.SquadRcon, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return nil, errClosed
+	}
+	if c.conn != nil {
+		return c.conn, nil
+	}
+
+	c.generation++
+	generation := c.generation
+
+	settings := c.settings
+	settings.OnDisconnect = func(err error) {
+		c.logger.Printf("Connection to %s lost: %v", c.address, err)
+
+		c.lock.Lock()
+		if c.generation == generation {
+			c.conn = nil
+		}
+		c.lock.Unlock()
+	}
+
+	conn, err := squadrcon.Connect(c.address, c.password, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Printf("Connected to %s", c.address)
+	c.conn = conn
+	return conn, nil
+}
+
+func (c *connection) Execute(command string) (string, error) {
+	conn, err := c.get()
+	if err != nil {
+		return "", err
+	}
+
+	return conn.Execute(command)
+}
+
+// Supports reports whether the server supports the command. All commands are assumed to be
+// supported while disconnected.
+func (c *connection) Supports(command string) bool {
+	c.lock.Lock()
+	conn := c.conn
+	c.lock.Unlock()
+
+	return conn == nil || conn.Supports(command)
+}
+
+func (c *connection) Broadcast(message string, priority squadrcon.BroadcastPriority) bool {
+	return c.broadcaster.Broadcast(message, priority)
+}
+
+func (c *connection) Close() error {
+	c.broadcaster.Close()
+
+	c.lock.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.closed = true
+	c.lock.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
@@ -0,0 +1,228 @@
+// Command squad-rcon-gateway serves the HTTP/JSON API of package gateway for a single server.
+//
+// Usage:
+//
+//	squad-rcon-gateway -address 127.0.0.1:21114 -keys keys.json [flags]
+//
+// The keys file contains the API keys as JSON, e.g.
+//
+//	[
+//	  {"Name": "website", "Key": "<random string>", "Scopes": ["read"]},
+//	  {"Name": "admin-panel", "Key": "<random string>", "Scopes": ["read", "broadcast", "kick"]}
+//	]
+//
+// Every request is written to the audit log as a line of JSON. Unless disabled using -events=false,
+// chat, notifications and roster, squad and match changes are served as live events.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"squad-rcon-go/pkg/gateway"
+	"squad-rcon-go/pkg/squadrcon"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	addressEnv  = "SQUAD_RCON_ADDRESS"
+	passwordEnv = "SQUAD_RCON_PASSWORD"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+	var address, password, listen, keysPath, auditPath string
+	var timeout time.Duration
+	var debug, events bool
+
+	flag.StringVar(&address, "address", os.Getenv(addressEnv), "address of the server, e.g. 127.0.0.1:21114 (env "+addressEnv+")")
+	flag.StringVar(&password, "password", os.Getenv(passwordEnv), "RCON password (env "+passwordEnv+")")
+	flag.StringVar(&listen, "listen", "127.0.0.1:8080", "address to serve HTTP on")
+	flag.StringVar(&keysPath, "keys", "", "JSON file containing the API keys")
+	flag.StringVar(&auditPath, "audit", "-", "file to append the audit log to, - for stdout")
+	flag.DurationVar(&timeout, "timeout", 5*time.Second, "timeout for connecting and sending commands")
+	flag.BoolVar(&events, "events", true, "serve live events, which polls the server for roster, squad and match changes")
+	flag.BoolVar(&debug, "debug", false, "log packets to stderr")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+
+	if address == "" || keysPath == "" {
+		fmt.Fprintln(os.Stderr, "Both -address (or "+addressEnv+") and -keys are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	keys, err := readKeys(keysPath)
+	if err != nil {
+		logger.Fatalf("Could not read API keys: %v", err)
+	}
+
+	audit, err := openAuditLog(auditPath)
+	if err != nil {
+		logger.Fatalf("Could not open audit log: %v", err)
+	}
+	defer audit.Close()
+
+	settings := squadrcon.Settings{
+		DialTimeout:  timeout,
+		WriteTimeout: timeout,
+		Broadcast: squadrcon.BroadcasterSettings{
+			OnError: func(err error) {
+				logger.Printf("Broadcast failed: %v", err)
+			},
+		},
+	}
+	if debug {
+		settings.Logger = log.New(os.Stderr, "rcon: ", log.LstdFlags)
+	}
+
+	var bus *squadrcon.EventBus
+	if events {
+		bus = squadrcon.NewEventBus(squadrcon.EventBusSettings{})
+		defer bus.Close()
+		settings.EventBus = bus
+	}
+
+	conn := newConnection(address, password, settings, logger)
+	defer conn.Close()
+
+	// Connect early to report configuration errors on startup. Later failures are retried on
+	// the next request.
+	if _, err := conn.get(); err != nil {
+		logger.Printf("Could not connect to %s, retrying on the next request: %v", address, err)
+	}
+
+	handlerSettings := gateway.HandlerSettings{
+		Keys:    keys,
+		OnAudit: audit.write,
+	}
+
+	if events {
+		onError := func(err error) {
+			logger.Printf("Tracking failed: %v", err)
+		}
+
+		roster := squadrcon.NewRosterTracker(conn, squadrcon.RosterTrackerSettings{EventBus: bus, OnError: onError})
+		defer roster.Close()
+		squads := squadrcon.NewSquadTracker(conn, squadrcon.SquadTrackerSettings{EventBus: bus, OnError: onError})
+		defer squads.Close()
+		match := squadrcon.NewMatchTracker(conn, squadrcon.MatchTrackerSettings{EventBus: bus, OnError: onError})
+		defer match.Close()
+
+		handlerSettings.Feed = gateway.NewFeed(bus, gateway.FeedSettings{
+			OnError: func(err error) {
+				logger.Printf("Feed failed: %v", err)
+			},
+		})
+		defer handlerSettings.Feed.Close()
+	}
+
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           gateway.NewHandler(conn, handlerSettings),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		// Live event streams only end when their feed is closed.
+		if handlerSettings.Feed != nil {
+			handlerSettings.Feed.Close()
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Printf("Serving on %s", listen)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		logger.Fatalf("Could not serve: %v", err)
+	}
+}
+
+// readKeys reads the API keys and rejects unknown scopes, which are most likely typos.
+func readKeys(path string) ([]gateway.APIKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []gateway.APIKey
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return nil, err
+	}
+
+	known := map[gateway.Scope]bool{gateway.ScopeAll: true}
+	for _, scope := range gateway.KnownScopes {
+		known[scope] = true
+	}
+
+	var errs []error
+	for i, key := range keys {
+		if key.Key == "" {
+			errs = append(errs, fmt.Errorf("key %d (%s) is empty", i, key.Name))
+		}
+		for _, scope := range key.Scopes {
+			if !known[scope] {
+				errs = append(errs, fmt.Errorf("key %d (%s) has unknown scope %q", i, key.Name, scope))
+			}
+		}
+	}
+
+	return keys, errors.Join(errs...)
+}
+
+// auditLog writes audit entries as JSON lines.
+type auditLog struct {
+	lock    sync.Mutex
+	writer  io.WriteCloser
+	encoder *json.Encoder
+}
+
+func openAuditLog(path string) (*auditLog, error) {
+	var writer io.WriteCloser = os.Stdout
+	if path != "-" {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		writer = file
+	}
+
+	return &auditLog{
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}, nil
+}
+
+func (l *auditLog) write(entry gateway.AuditEntry) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if err := l.encoder.Encode(entry); err != nil {
+		log.Printf("Could not write audit log: %v", err)
+	}
+}
+
+func (l *auditLog) Close() error {
+	if l.writer == os.Stdout {
+		return nil
+	}
+	return l.writer.Close()
+}
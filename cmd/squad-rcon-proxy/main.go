@@ -0,0 +1,124 @@
+// Command squad-rcon-proxy lets multiple RCON tools, e.g. SquadJS and bots using this library,
+// share a single connection to a Squad server.
+//
+// Usage:
+//
+//	squad-rcon-proxy -address 127.0.0.1:21114 -clients clients.json [flags]
+//
+// Tools connect to the proxy instead of the server, each using its own password. The clients file
+// contains the clients as JSON, e.g.
+//
+//	[
+//	  {"Name": "squadjs", "Password": "<random string>"},
+//	  {"Name": "stats", "Password": "<random string>", "AllowedCommands": ["ListPlayers", "ListSquads"]}
+//	]
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"squad-rcon-go/pkg/rcon"
+	"syscall"
+	"time"
+)
+
+const (
+	addressEnv  = "SQUAD_RCON_ADDRESS"
+	passwordEnv = "SQUAD_RCON_PASSWORD"
+)
+
+func main() {
+	var address, password, listen, clientsPath string
+	var timeout time.Duration
+	var debug bool
+
+	flag.StringVar(&address, "address", os.Getenv(addressEnv), "address of the server, e.g. 127.0.0.1:21114 (env "+addressEnv+")")
+	flag.StringVar(&password, "password", os.Getenv(passwordEnv), "RCON password of the server (env "+passwordEnv+")")
+	flag.StringVar(&listen, "listen", "127.0.0.1:21115", "address to accept clients on")
+	flag.StringVar(&clientsPath, "clients", "", "JSON file containing the clients")
+	flag.DurationVar(&timeout, "timeout", 5*time.Second, "timeout for connecting and sending commands")
+	flag.BoolVar(&debug, "debug", false, "log packets of the server connection to stderr")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+
+	if address == "" || clientsPath == "" {
+		fmt.Fprintln(os.Stderr, "Both -address (or "+addressEnv+") and -clients are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	clients, err := readClients(clientsPath)
+	if err != nil {
+		logger.Fatalf("Could not read clients: %v", err)
+	}
+
+	settings := rcon.ProxySettings{
+		Upstream: rcon.Settings{
+			DialTimeout:  timeout,
+			WriteTimeout: timeout,
+			OnDisconnect: func(err error) {
+				logger.Printf("Connection to %s lost, reconnecting: %v", address, err)
+			},
+		},
+		Clients:      clients,
+		WriteTimeout: timeout,
+		OnError: func(err error) {
+			logger.Print(err)
+		},
+	}
+	if debug {
+		settings.Upstream.Logger = log.New(os.Stderr, "rcon: ", log.LstdFlags)
+	}
+
+	proxy, err := rcon.NewProxy(address, password, settings)
+	if err != nil {
+		logger.Fatalf("Could not connect to %s: %v", address, err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		_ = proxy.Close()
+	}()
+
+	logger.Printf("Connected to %s, accepting clients on %s", address, listen)
+	if err := proxy.ListenAndServe(listen); !errors.Is(err, rcon.ErrProxyClosed) {
+		logger.Fatalf("Could not serve: %v", err)
+	}
+}
+
+// readClients reads the clients and rejects empty and duplicate passwords, which would make
+// clients indistinguishable.
+func readClients(path string) ([]rcon.ProxyClient, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var clients []rcon.ProxyClient
+	if err := json.Unmarshal(content, &clients); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	passwords := make(map[string]string)
+	for i, client := range clients {
+		if client.Password == "" {
+			errs = append(errs, fmt.Errorf("client %d (%s) has no password", i, client.Name))
+			continue
+		}
+		if other, exists := passwords[client.Password]; exists {
+			errs = append(errs, fmt.Errorf("clients %s and %s have the same password", other, client.Name))
+		}
+		passwords[client.Password] = client.Name
+	}
+
+	return clients, errors.Join(errs...)
+}
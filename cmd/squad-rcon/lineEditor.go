@@ -0,0 +1,376 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// errInterrupted is returned by readLine when the user presses Ctrl+C.
+var errInterrupted = errors.New("interrupted")
+
+// Keys handled by the line editor.
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyBackspace = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// lineEditor reads lines from the terminal with cursor movement, history and tab completion. Text
+// can be printed above the line being edited, e.g. for incoming chat messages. When the input is
+// not a terminal, lines are read without editing.
+type lineEditor struct {
+	input   *bufio.Reader
+	output  io.Writer
+	fd      int
+	history *history
+
+	// complete returns the candidates for the word before the cursor. Optional.
+	complete func(line string, word string) []string
+
+	// Lock to be used before writing to output or accessing the fields below.
+	lock sync.Mutex
+
+	prompt string
+	buffer []rune
+	cursor int
+
+	// True while readLine is editing a line in raw mode.
+	editing bool
+
+	// The history entry shown, len(history.entries) when editing a new line.
+	historyIndex int
+
+	// The line being edited before browsing history.
+	draft []rune
+}
+
+func newLineEditor(history *history) *lineEditor {
+	return &lineEditor{
+		input:   bufio.NewReader(os.Stdin),
+		output:  os.Stdout,
+		fd:      int(os.Stdin.Fd()),
+		history: history,
+	}
+}
+
+// readLine shows the prompt and returns the entered line. Returns io.EOF when the input ends or
+// the user presses Ctrl+D on an empty line, and errInterrupted on Ctrl+C.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	if !isTerminal(e.fd) {
+		return e.readPlainLine(prompt)
+	}
+
+	state, err := makeRaw(e.fd)
+	if err != nil {
+		return e.readPlainLine(prompt)
+	}
+	defer restoreTerminal(e.fd, state)
+
+	e.lock.Lock()
+	e.prompt = prompt
+	e.buffer = nil
+	e.cursor = 0
+	e.historyIndex = len(e.history.entries)
+	e.draft = nil
+	e.editing = true
+	e.redraw()
+	e.lock.Unlock()
+
+	defer func() {
+		e.lock.Lock()
+		e.editing = false
+		e.lock.Unlock()
+	}()
+
+	for {
+		key, _, err := e.input.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		line, done, err := e.handleKey(key)
+		if done || err != nil {
+			return line, err
+		}
+	}
+}
+
+func (e *lineEditor) readPlainLine(prompt string) (string, error) {
+	e.lock.Lock()
+	fmt.Fprint(e.output, prompt)
+	e.lock.Unlock()
+
+	line, err := e.input.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// handleKey applies a key press. Returns true with the line when the line is complete.
+func (e *lineEditor) handleKey(key rune) (string, bool, error) {
+	if key == keyTab {
+		e.completeWord()
+		return "", false, nil
+	}
+
+	var escape rune
+	if key == keyEscape {
+		escape = e.readEscape()
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	switch key {
+	case keyEnter, keyLineFeed:
+		line := string(e.buffer)
+		fmt.Fprint(e.output, "\r\n")
+		e.editing = false
+		return line, true, nil
+	case keyCtrlC:
+		fmt.Fprint(e.output, "^C\r\n")
+		e.editing = false
+		return "", true, errInterrupted
+	case keyCtrlD:
+		if len(e.buffer) == 0 {
+			fmt.Fprint(e.output, "\r\n")
+			e.editing = false
+			return "", true, io.EOF
+		}
+		e.deleteAt(e.cursor)
+	case keyBackspace, keyDelete:
+		if e.cursor > 0 {
+			e.cursor--
+			e.deleteAt(e.cursor)
+		}
+	case keyCtrlA:
+		e.cursor = 0
+	case keyCtrlE:
+		e.cursor = len(e.buffer)
+	case keyCtrlB:
+		e.moveCursor(-1)
+	case keyCtrlF:
+		e.moveCursor(1)
+	case keyCtrlK:
+		e.buffer = e.buffer[:e.cursor]
+	case keyCtrlU:
+		e.buffer = append([]rune(nil), e.buffer[e.cursor:]...)
+		e.cursor = 0
+	case keyCtrlW:
+		start := e.cursor
+		for start > 0 && unicode.IsSpace(e.buffer[start-1]) {
+			start--
+		}
+		for start > 0 && !unicode.IsSpace(e.buffer[start-1]) {
+			start--
+		}
+		e.buffer = append(e.buffer[:start], e.buffer[e.cursor:]...)
+		e.cursor = start
+	case keyCtrlL:
+		fmt.Fprint(e.output, "\x1b[H\x1b[2J")
+	case keyCtrlP:
+		e.browseHistory(-1)
+	case keyCtrlN:
+		e.browseHistory(1)
+	case keyEscape:
+		e.handleEscape(escape)
+	default:
+		if unicode.IsPrint(key) {
+			e.insert([]rune{key})
+		}
+	}
+
+	e.redraw()
+	return "", false, nil
+}
+
+// readEscape reads the remainder of the escape sequences of arrow, home, end and delete keys and
+// returns the key of the sequence, 0 for other sequences. Must be called while not locked, as it
+// blocks until the remainder is typed, which for a lone Esc is the next key press.
+func (e *lineEditor) readEscape() rune {
+	introducer, _, err := e.input.ReadRune()
+	if err != nil || (introducer != '[' && introducer != 'O') {
+		return 0
+	}
+
+	key, _, err := e.input.ReadRune()
+	if err != nil {
+		return 0
+	}
+
+	switch key {
+	case '1', '3', '4', '7', '8':
+		// Sequences such as `ESC [ 3 ~`.
+		if terminator, _, err := e.input.ReadRune(); err != nil || terminator != '~' {
+			return 0
+		}
+	}
+
+	return key
+}
+
+// handleEscape applies the key of an escape sequence returned by readEscape.
+func (e *lineEditor) handleEscape(key rune) {
+	switch key {
+	case 'A':
+		e.browseHistory(-1)
+	case 'B':
+		e.browseHistory(1)
+	case 'C':
+		e.moveCursor(1)
+	case 'D':
+		e.moveCursor(-1)
+	case 'H', '1', '7':
+		e.cursor = 0
+	case 'F', '4', '8':
+		e.cursor = len(e.buffer)
+	case '3':
+		e.deleteAt(e.cursor)
+	}
+}
+
+func (e *lineEditor) insert(runes []rune) {
+	tail := append(append([]rune(nil), runes...), e.buffer[e.cursor:]...)
+	e.buffer = append(e.buffer[:e.cursor], tail...)
+	e.cursor += len(runes)
+}
+
+func (e *lineEditor) deleteAt(index int) {
+	if index < len(e.buffer) {
+		e.buffer = append(e.buffer[:index], e.buffer[index+1:]...)
+	}
+}
+
+func (e *lineEditor) moveCursor(delta int) {
+	e.cursor = max(0, min(len(e.buffer), e.cursor+delta))
+}
+
+func (e *lineEditor) browseHistory(delta int) {
+	index := e.historyIndex + delta
+	if index < 0 || index > len(e.history.entries) {
+		return
+	}
+
+	if e.historyIndex == len(e.history.entries) {
+		e.draft = e.buffer
+	}
+
+	e.historyIndex = index
+	if index == len(e.history.entries) {
+		e.buffer = e.draft
+	} else {
+		e.buffer = []rune(e.history.entries[index])
+	}
+	e.cursor = len(e.buffer)
+}
+
+// completeWord completes the word before the cursor. A single candidate is inserted, multiple
+// candidates are completed up to their common prefix and listed if there is no common prefix.
+func (e *lineEditor) completeWord() {
+	e.lock.Lock()
+	line := string(e.buffer[:e.cursor])
+	e.lock.Unlock()
+
+	if e.complete == nil {
+		return
+	}
+
+	word := line[strings.LastIndexAny(line, " \t")+1:]
+	candidates := e.complete(line, word)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	switch {
+	case len(candidates) == 0:
+		fmt.Fprint(e.output, "\a")
+	case len(candidates) == 1:
+		e.replaceWord(word, candidates[0]+" ")
+	default:
+		prefix := commonPrefix(candidates)
+		if len([]rune(prefix)) > len([]rune(word)) {
+			e.replaceWord(word, prefix)
+		} else {
+			e.printAboveLocked(strings.Join(candidates, "  "))
+		}
+	}
+
+	e.redraw()
+}
+
+// replaceWord replaces the word before the cursor. Completion is case-insensitive, so the typed
+// word is replaced rather than extended.
+func (e *lineEditor) replaceWord(word string, replacement string) {
+	start := e.cursor - len([]rune(word))
+	e.buffer = append(e.buffer[:start], e.buffer[e.cursor:]...)
+	e.cursor = start
+	e.insert([]rune(replacement))
+}
+
+// printAbove prints text above the line being edited, or as is when no line is being edited.
+func (e *lineEditor) printAbove(text string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.printAboveLocked(text)
+	if e.editing {
+		e.redraw()
+	}
+}
+
+func (e *lineEditor) printAboveLocked(text string) {
+	text = strings.TrimRight(text, "\n")
+	if e.editing {
+		// Raw mode does not translate input, but output processing still translates `\n`.
+		fmt.Fprint(e.output, "\r\x1b[K"+text+"\n")
+	} else {
+		fmt.Fprintln(e.output, text)
+	}
+}
+
+// redraw shows the prompt and buffer and positions the cursor. Must be called while locked.
+func (e *lineEditor) redraw() {
+	fmt.Fprint(e.output, "\r\x1b[K"+e.prompt+string(e.buffer))
+	if back := len(e.buffer) - e.cursor; back > 0 {
+		fmt.Fprintf(e.output, "\x1b[%dD", back)
+	}
+}
+
+// commonPrefix returns the longest case-insensitive common prefix, using the case of the first
+// candidate.
+func commonPrefix(candidates []string) string {
+	prefix := []rune(candidates[0])
+	for _, candidate := range candidates[1:] {
+		runes := []rune(candidate)
+		length := 0
+		for length < len(prefix) && length < len(runes) &&
+			unicode.ToLower(prefix[length]) == unicode.ToLower(runes[length]) {
+			length++
+		}
+		prefix = prefix[:length]
+	}
+	return string(prefix)
+}
@@ -0,0 +1,190 @@
+// Command squad-rcon is an interactive shell for Squad's RCON, and runs single commands for use in
+// scripts.
+//
+// Usage:
+//
+//	squad-rcon [flags]                            start the interactive shell
+//	squad-rcon [flags] <subcommand> [arguments]   run a subcommand and exit
+//
+// Subcommands print tables by default, or the parsed response with --json or --csv. See
+// `squad-rcon -h` for the list of subcommands and exit codes.
+//
+// The address and password can also be passed using the SQUAD_RCON_ADDRESS and
+// SQUAD_RCON_PASSWORD environment variables, which keeps the password out of the process list.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"squad-rcon-go/pkg/rcon"
+	"squad-rcon-go/pkg/squadrcon"
+	"time"
+)
+
+const (
+	addressEnv  = "SQUAD_RCON_ADDRESS"
+	passwordEnv = "SQUAD_RCON_PASSWORD"
+	historyEnv  = "SQUAD_RCON_HISTORY"
+)
+
+// Exit codes.
+const (
+	exitSuccess = 0
+
+	// The server rejected or failed to run the command.
+	exitCommandFailure = 1
+
+	// Invalid flags or arguments.
+	exitUsage = 2
+
+	exitConnectionFailure = 3
+	exitAuthFailure       = 4
+
+	// The response of the server could not be parsed.
+	exitParseError = 5
+)
+
+// errConnect is wrapped by errors of failed connection attempts.
+var errConnect = errors.New("could not connect")
+
+// connectionSettings contains everything needed to (re)connect.
+type connectionSettings struct {
+	address  string
+	password string
+	timeout  time.Duration
+	logger   *log.Logger
+	recorder *rcon.Recorder
+}
+
+func (s connectionSettings) connect(
+	onMessage func(message squadrcon.ServerMessage),
+	onDisconnect func(err error),
+) (*squadrcon.SquadRcon, error) {
+	conn, err := squadrcon.Connect(s.address, s.password, squadrcon.Settings{
+		DialTimeout:     s.timeout,
+		Logger:          s.logger,
+		OnDisconnect:    onDisconnect,
+		OnServerMessage: onMessage,
+		Recorder:        s.recorder,
+		WriteTimeout:    s.timeout,
+	})
+	if err != nil {
+		return nil, connectFailed(s.address, err)
+	}
+
+	return conn, nil
+}
+
+// connectFailed wraps an error of connecting to address.
+func connectFailed(address string, err error) error {
+	return fmt.Errorf("%w to %s: %w", errConnect, address, err)
+}
+
+func main() {
+	var settings connectionSettings
+	var historyPath, recordPath string
+	var debug bool
+
+	flag.StringVar(&settings.address, "address", os.Getenv(addressEnv), "address of the server, e.g. 127.0.0.1:21114 (env "+addressEnv+")")
+	flag.StringVar(&settings.password, "password", os.Getenv(passwordEnv), "RCON password (env "+passwordEnv+")")
+	flag.DurationVar(&settings.timeout, "timeout", 5*time.Second, "timeout for connecting and sending commands")
+	flag.StringVar(&historyPath, "history", defaultHistoryPath(), "file to persist command history in, empty to disable (env "+historyEnv+")")
+	flag.BoolVar(&debug, "debug", false, "log packets to stderr")
+	flag.StringVar(&recordPath, "record", "", "file to record all packets to as JSON lines, for replaying them later")
+	flag.Usage = printUsage
+	flag.Parse()
+
+	if debug {
+		settings.logger = log.New(os.Stderr, "rcon: ", log.LstdFlags)
+	}
+
+	if settings.address == "" {
+		fmt.Fprintln(os.Stderr, "No address given, use -address or "+addressEnv)
+		os.Exit(exitUsage)
+	}
+
+	if recordPath != "" {
+		recorder, err := rcon.CreateRecording(recordPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not create recording: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		settings.recorder = recorder
+	}
+
+	os.Exit(run(settings, historyPath))
+}
+
+// run runs the subcommand or the shell and returns the exit code. Separate from main, so the
+// recording is closed before exiting.
+func run(settings connectionSettings, historyPath string) int {
+	if settings.recorder != nil {
+		defer settings.recorder.Close()
+	}
+
+	if flag.NArg() > 0 {
+		return runSubcommand(settings, flag.Args())
+	}
+
+	history, err := loadHistory(historyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not load history: %v\n", err)
+	}
+
+	return runShell(settings, history)
+}
+
+func printUsage() {
+	output := flag.CommandLine.Output()
+	fmt.Fprintln(output, "Usage:")
+	fmt.Fprintln(output, "  squad-rcon [flags]                            start the interactive shell")
+	fmt.Fprintln(output, "  squad-rcon [flags] <subcommand> [arguments]   run a subcommand and exit")
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Flags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(output)
+	printSubcommands(output)
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Exit codes:")
+	t := newTable(output, "  CODE", "MEANING")
+	t.row("  0", "success")
+	t.row("  1", "the server failed to run the command")
+	t.row("  2", "invalid flags or arguments")
+	t.row("  3", "could not connect")
+	t.row("  4", "incorrect password")
+	t.row("  5", "the response could not be parsed, the parsed part is printed")
+	t.flush()
+}
+
+func defaultHistoryPath() string {
+	if path, exists := os.LookupEnv(historyEnv); exists {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".squad_rcon_history")
+}
+
+// exitCodeForError returns the exit code for an error of connecting or of running a subcommand.
+func exitCodeForError(err error) int {
+	switch {
+	case err == nil:
+		return exitSuccess
+	case errors.Is(err, rcon.ErrIncorrectPassword):
+		return exitAuthFailure
+	case errors.Is(err, errConnect):
+		return exitConnectionFailure
+	case errors.Is(err, errParse):
+		return exitParseError
+	default:
+		return exitCommandFailure
+	}
+}
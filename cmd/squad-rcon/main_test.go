@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"squad-rcon-go/pkg/rcon"
+	"squad-rcon-go/pkg/squadrcon"
+	"testing"
+)
+
+func TestExitCodeForError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		exitCode int
+	}{
+		{"success", nil, exitSuccess},
+		{"incorrect password", connectFailed("127.0.0.1:21114", rcon.ErrIncorrectPassword), exitAuthFailure},
+		{"connection refused", connectFailed("127.0.0.1:21114", errors.New("connection refused")), exitConnectionFailure},
+		{"parse error", parseFailed(squadrcon.ErrResponseIsNotPlayerList), exitParseError},
+		{"parse and write error", errors.Join(errors.New("broken pipe"), parseFailed(squadrcon.ErrResponseIsNotSquadList)), exitParseError},
+		{"command failure", rcon.ErrConnectionClosed, exitCommandFailure},
+	}
+
+	for _, test := range tests {
+		if exitCode := exitCodeForError(test.err); exitCode != test.exitCode {
+			t.Errorf("%s: got exit code %d, expected %d", test.name, exitCode, test.exitCode)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"squad-rcon-go/pkg/rcon"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Commands handled by the shell itself.
+var builtins = []struct {
+	name string
+	help string
+}{
+	{"help", "show this help"},
+	{"raw", "toggle formatting of known responses as tables"},
+	{"reconnect", "close the connection and connect again"},
+	{"exit", "leave the shell, as does Ctrl+D"},
+}
+
+// ANSI colors of the chat channels.
+var chatColors = map[squadrcon.ChatChannel]string{
+	squadrcon.ChatAll:   "\x1b[37m",
+	squadrcon.ChatTeam:  "\x1b[36m",
+	squadrcon.ChatSquad: "\x1b[32m",
+	squadrcon.ChatAdmin: "\x1b[31m",
+}
+
+const (
+	colorNotification = "\x1b[33m"
+	colorReset        = "\x1b[0m"
+)
+
+type shell struct {
+	settings connectionSettings
+	editor   *lineEditor
+	colors   bool
+
+	// Signalled when the connection is lost.
+	disconnected chan struct{}
+
+	// Closed when the shell exits.
+	done chan struct{}
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// Nil while disconnected.
+	conn *squadrcon.SquadRcon
+
+	// Command names used for tab completion.
+	commands []string
+
+	// False if responses are printed without formatting.
+	formatted bool
+}
+
+// runShell connects and reads commands until the user exits. Returns the exit code.
+func runShell(settings connectionSettings, history *history) int {
+	s := &shell{
+		settings:     settings,
+		editor:       newLineEditor(history),
+		colors:       isTerminal(int(os.Stdout.Fd())),
+		disconnected: make(chan struct{}, 1),
+		done:         make(chan struct{}),
+		formatted:    true,
+	}
+	s.editor.complete = s.complete
+
+	if err := s.connect(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitCodeForError(err)
+	}
+	defer s.close()
+
+	go s.reconnectLoop()
+
+	s.print(fmt.Sprintf("Connected to %s. Type help for help, Tab completes commands.", settings.address))
+
+	for {
+		line, err := s.editor.readLine(s.prompt())
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
+		if err != nil {
+			return exitSuccess
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if err := history.add(line); err != nil {
+			s.editor.printAbove(fmt.Sprintf("Could not save history: %v", err))
+		}
+
+		if exit := s.run(line); exit {
+			return exitSuccess
+		}
+	}
+}
+
+// run runs a line. Returns true if the shell should exit.
+func (s *shell) run(line string) bool {
+	switch strings.ToLower(line) {
+	case "exit", "quit":
+		return true
+	case "help":
+		s.printHelp()
+		return false
+	case "raw":
+		s.lock.Lock()
+		s.formatted = !s.formatted
+		formatted := s.formatted
+		s.lock.Unlock()
+
+		if formatted {
+			s.print("Known responses are formatted as tables.")
+		} else {
+			s.print("Responses are printed as received.")
+		}
+		return false
+	case "reconnect":
+		s.lock.Lock()
+		conn := s.conn
+		s.conn = nil
+		s.lock.Unlock()
+
+		if conn != nil {
+			_ = conn.Close()
+		}
+		if err := s.connect(); err != nil {
+			s.print(err.Error())
+			signalDisconnected(s.disconnected)
+		} else {
+			s.print("Reconnected.")
+		}
+		return false
+	}
+
+	s.lock.Lock()
+	conn := s.conn
+	formatted := s.formatted
+	s.lock.Unlock()
+
+	if conn == nil {
+		s.print("Not connected, reconnecting in the background.")
+		return false
+	}
+
+	response, err := conn.Execute(line)
+	if err != nil {
+		s.print(fmt.Sprintf("Error: %v", err))
+		return false
+	}
+
+	if formatted {
+		if table, ok := formatResponse(line, response); ok {
+			s.print(table)
+			return false
+		}
+	}
+
+	if response != "" {
+		s.print(response)
+	}
+	return false
+}
+
+func (s *shell) connect() error {
+	conn, err := s.settings.connect(s.onServerMessage, func(err error) {
+		s.lock.Lock()
+		s.conn = nil
+		s.lock.Unlock()
+
+		s.editor.printAbove(fmt.Sprintf("Connection lost: %v. Reconnecting...", err))
+		signalDisconnected(s.disconnected)
+	})
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if commands, known := conn.Commands(); known {
+		for _, command := range commands {
+			names = append(names, command.Name)
+		}
+	}
+
+	s.lock.Lock()
+	s.conn = conn
+	if names != nil {
+		s.commands = names
+	}
+	s.lock.Unlock()
+
+	return nil
+}
+
+// reconnectLoop reconnects after the connection is lost, with increasing delays between
+// attempts.
+func (s *shell) reconnectLoop() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.disconnected:
+		}
+
+		delay := minReconnectDelay
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-time.After(delay):
+			}
+
+			err := s.connect()
+			if err == nil {
+				s.editor.printAbove("Reconnected.")
+				break
+			}
+
+			// Retrying does not fix an incorrect password.
+			if errors.Is(err, rcon.ErrIncorrectPassword) {
+				s.editor.printAbove(fmt.Sprintf("Reconnecting failed: %v. Use reconnect to try again.", err))
+				break
+			}
+
+			delay = min(delay*2, maxReconnectDelay)
+			s.editor.printAbove(fmt.Sprintf("Reconnecting failed: %v. Retrying in %s.", err, delay))
+		}
+	}
+}
+
+func (s *shell) close() {
+	close(s.done)
+
+	s.lock.Lock()
+	conn := s.conn
+	s.conn = nil
+	s.lock.Unlock()
+
+	if conn != nil {
+		_ = conn.Close()
+	}
+}
+
+func (s *shell) prompt() string {
+	s.lock.Lock()
+	connected := s.conn != nil
+	s.lock.Unlock()
+
+	if !connected {
+		return "(disconnected) > "
+	}
+	return s.settings.address + " > "
+}
+
+// onServerMessage shows chat and notifications above the prompt.
+func (s *shell) onServerMessage(message squadrcon.ServerMessage) {
+	text := message.GetRaw()
+	color := colorNotification
+
+	if chat, ok := message.(squadrcon.ChatMessage); ok {
+		text = fmt.Sprintf("[%s] %s: %s", chat.Channel, chat.PlayerName, chat.Message)
+		color = chatColors[chat.Channel]
+	}
+
+	if s.colors && color != "" {
+		text = color + text + colorReset
+	}
+
+	s.editor.printAbove(text)
+}
+
+// complete returns the commands starting with word. Only the first word is completed.
+func (s *shell) complete(line string, word string) []string {
+	if strings.TrimSpace(line) != word {
+		return nil
+	}
+
+	s.lock.Lock()
+	names := append([]string(nil), s.commands...)
+	s.lock.Unlock()
+
+	for _, builtin := range builtins {
+		names = append(names, builtin.name)
+	}
+
+	var candidates []string
+	for _, name := range names {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(word)) {
+			candidates = append(candidates, name)
+		}
+	}
+
+	sort.Strings(candidates)
+	return candidates
+}
+
+func (s *shell) printHelp() {
+	var builder strings.Builder
+	builder.WriteString("Lines are sent to the server as RCON commands, except for:\n")
+	t := newTable(&builder, "COMMAND", "DESCRIPTION")
+	for _, builtin := range builtins {
+		t.row(builtin.name, builtin.help)
+	}
+	t.flush()
+	builder.WriteString("\nUse ListCommands 1 to list the commands of the server.")
+
+	s.print(builder.String())
+}
+
+// print prints text without interfering with incoming messages.
+func (s *shell) print(text string) {
+	s.editor.printAbove(text)
+}
+
+func signalDisconnected(channel chan struct{}) {
+	select {
+	case channel <- struct{}{}:
+	default:
+	}
+}
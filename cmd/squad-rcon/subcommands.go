@@ -0,0 +1,388 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"squad-rcon-go/pkg/rcon"
+	"squad-rcon-go/pkg/squadrcon"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type outputFormat int
+
+const (
+	formatTable outputFormat = iota
+	formatJSON
+	formatCSV
+)
+
+// errParse is wrapped by errors of responses that could not be parsed.
+var errParse = errors.New("failed to parse response")
+
+// subcommand runs once and exits.
+type subcommand struct {
+	name      string
+	arguments string
+	help      string
+
+	// True if the subcommand supports --csv.
+	csv bool
+
+	// minArguments contains the number of required positional arguments.
+	minArguments int
+
+	run func(conn rcon.Rcon, arguments []string, output io.Writer, format outputFormat) error
+}
+
+var subcommands = []subcommand{
+	{
+		name:         "exec",
+		arguments:    "<command> [arguments]",
+		help:         "run an RCON command and print the response",
+		minArguments: 1,
+		run:          runExec,
+	},
+	{
+		name: "players",
+		help: "list active and recently disconnected players",
+		csv:  true,
+		run:  runPlayers,
+	},
+	{
+		name: "squads",
+		help: "list teams and squads",
+		csv:  true,
+		run:  runSquads,
+	},
+	{
+		name: "map",
+		help: "show the current and next layer",
+		csv:  true,
+		run:  runMap,
+	},
+	{
+		name: "info",
+		help: "show server information",
+		csv:  true,
+		run:  runInfo,
+	},
+}
+
+func findSubcommand(name string) (subcommand, bool) {
+	for _, command := range subcommands {
+		if command.name == name {
+			return command, true
+		}
+	}
+	return subcommand{}, false
+}
+
+func printSubcommands(output io.Writer) {
+	fmt.Fprintln(output, "Subcommands, each accepting --json and, except for exec, --csv:")
+	t := newTable(output, "  NAME", "ARGUMENTS", "DESCRIPTION")
+	for _, command := range subcommands {
+		t.row("  "+command.name, command.arguments, command.help)
+	}
+	t.flush()
+}
+
+// runSubcommand runs the subcommand named by the first argument. Returns the exit code.
+func runSubcommand(settings connectionSettings, arguments []string) int {
+	command, exists := findSubcommand(arguments[0])
+	if !exists {
+		fmt.Fprintf(os.Stderr, "Unknown subcommand %q\n\n", arguments[0])
+		printSubcommands(os.Stderr)
+		return exitUsage
+	}
+
+	flags := flag.NewFlagSet(command.name, flag.ContinueOnError)
+	jsonOutput := flags.Bool("json", false, "print the response as JSON")
+	csvOutput := false
+	if command.csv {
+		flags.BoolVar(&csvOutput, "csv", false, "print the response as CSV")
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: squad-rcon [flags] %s [--json] %s\n\n%s.\n\n", command.name, command.arguments, command.help)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(arguments[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitSuccess
+		}
+		return exitUsage
+	}
+
+	if *jsonOutput && csvOutput {
+		fmt.Fprintln(os.Stderr, "Only one of --json and --csv can be used")
+		return exitUsage
+	}
+	if flags.NArg() < command.minArguments {
+		flags.Usage()
+		return exitUsage
+	}
+
+	format := formatTable
+	switch {
+	case *jsonOutput:
+		format = formatJSON
+	case csvOutput:
+		format = formatCSV
+	}
+
+	conn, err := settings.connect(nil, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitCodeForError(err)
+	}
+	defer conn.Close()
+
+	if err := command.run(conn, flags.Args(), os.Stdout, format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitCodeForError(err)
+	}
+	return exitSuccess
+}
+
+// parseFailed wraps a parse error. Parsers return what they could parse along with the error,
+// which is printed before failing.
+func parseFailed(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", errParse, err)
+}
+
+func runExec(conn rcon.Rcon, arguments []string, output io.Writer, format outputFormat) error {
+	command := strings.Join(arguments, " ")
+	response, err := conn.Execute(command)
+	if err != nil {
+		return err
+	}
+
+	if format == formatJSON {
+		return writeJSON(output, struct {
+			Command  string
+			Response string
+		}{command, response})
+	}
+
+	if response != "" {
+		fmt.Fprintln(output, strings.TrimRight(response, "\n"))
+	}
+	return nil
+}
+
+func runPlayers(conn rcon.Rcon, _ []string, output io.Writer, format outputFormat) error {
+	response, err := conn.Execute("ListPlayers")
+	if err != nil {
+		return err
+	}
+	list, parseErr := squadrcon.ParsePlayersList(response)
+
+	switch format {
+	case formatJSON:
+		list.ActivePlayers = emptyIfNil(list.ActivePlayers)
+		list.DisconnectedPlayers = emptyIfNil(list.DisconnectedPlayers)
+		err = writeJSON(output, list)
+	case formatCSV:
+		err = writePlayerListCSV(output, list)
+	default:
+		writePlayerList(output, list)
+	}
+
+	return errors.Join(err, parseFailed(parseErr))
+}
+
+func runSquads(conn rcon.Rcon, _ []string, output io.Writer, format outputFormat) error {
+	response, err := conn.Execute("ListSquads")
+	if err != nil {
+		return err
+	}
+	list, parseErr := squadrcon.ParseSquadList(response)
+
+	switch format {
+	case formatJSON:
+		list.Teams = emptyIfNil(list.Teams)
+		list.Squads = emptyIfNil(list.Squads)
+		err = writeJSON(output, list)
+	case formatCSV:
+		err = writeSquadListCSV(output, list)
+	default:
+		writeSquadList(output, list)
+	}
+
+	return errors.Join(err, parseFailed(parseErr))
+}
+
+// currentAndNextMap is printed by the map subcommand.
+type currentAndNextMap struct {
+	Current squadrcon.LayerInfo
+	Next    squadrcon.LayerInfo
+}
+
+func runMap(conn rcon.Rcon, _ []string, output io.Writer, format outputFormat) error {
+	currentResponse, err := conn.Execute("ShowCurrentMap")
+	if err != nil {
+		return err
+	}
+	nextResponse, err := conn.Execute("ShowNextMap")
+	if err != nil {
+		return err
+	}
+
+	var maps currentAndNextMap
+	var currentErr, nextErr error
+	maps.Current, currentErr = squadrcon.ParseCurrentMap(currentResponse)
+	maps.Next, nextErr = squadrcon.ParseNextMap(nextResponse)
+
+	switch format {
+	case formatJSON:
+		err = writeJSON(output, maps)
+	case formatCSV:
+		err = writeMapsCSV(output, maps)
+	default:
+		writeMaps(output, maps)
+	}
+
+	return errors.Join(err, parseFailed(currentErr), parseFailed(nextErr))
+}
+
+func runInfo(conn rcon.Rcon, _ []string, output io.Writer, format outputFormat) error {
+	response, err := conn.Execute("ShowServerInfo")
+	if err != nil {
+		return err
+	}
+
+	info, err := squadrcon.ParseServerInfo(response)
+	if err != nil {
+		return parseFailed(err)
+	}
+
+	switch format {
+	case formatJSON:
+		return writeJSON(output, info)
+	case formatCSV:
+		return writeServerInfoCSV(output, info)
+	default:
+		writeServerInfo(output, info)
+		return nil
+	}
+}
+
+// emptyIfNil makes JSON output contain an empty array instead of null, which is easier to handle
+// in scripts.
+func emptyIfNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
+
+func writeJSON(output io.Writer, value any) error {
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// writePlayerListCSV writes a row per player. Active players have an empty disconnect time,
+// disconnected players have empty team, squad, leader and kit columns.
+func writePlayerListCSV(output io.Writer, list squadrcon.PlayerList) error {
+	writer := csv.NewWriter(output)
+	_ = writer.Write([]string{"match_id", "name", "steam_id", "eos_id", "team", "squad", "squad_lead", "kit", "disconnected_at"})
+
+	for _, player := range list.ActivePlayers {
+		_ = writer.Write([]string{
+			strconv.Itoa(player.MatchId),
+			player.Name,
+			player.SteamId.String(),
+			player.EosId.String(),
+			strconv.Itoa(player.TeamIndex),
+			strconv.Itoa(player.SquadIndex),
+			strconv.FormatBool(player.IsSquadLead),
+			player.Kit,
+			"",
+		})
+	}
+
+	for _, player := range list.DisconnectedPlayers {
+		_ = writer.Write([]string{
+			strconv.Itoa(player.MatchId),
+			player.Name,
+			player.SteamId.String(),
+			player.EosId.String(),
+			"",
+			"",
+			"",
+			"",
+			player.DisconnectTime.Format(time.RFC3339),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeSquadListCSV writes a row per squad, including the faction of its team.
+func writeSquadListCSV(output io.Writer, list squadrcon.SquadList) error {
+	factions := make(map[int]string, len(list.Teams))
+	for _, team := range list.Teams {
+		factions[team.Index] = team.Faction
+	}
+
+	writer := csv.NewWriter(output)
+	_ = writer.Write([]string{"team", "faction", "squad", "name", "size", "locked", "creator_name", "creator_steam_id", "creator_eos_id"})
+
+	for _, squad := range list.Squads {
+		_ = writer.Write([]string{
+			strconv.Itoa(squad.TeamIndex),
+			factions[squad.TeamIndex],
+			strconv.Itoa(squad.Id),
+			squad.Name,
+			strconv.Itoa(squad.Size),
+			strconv.FormatBool(squad.Locked),
+			squad.CreatorName,
+			squad.CreatorSteamId.String(),
+			squad.CreatorEosId.String(),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeMapsCSV(output io.Writer, maps currentAndNextMap) error {
+	writer := csv.NewWriter(output)
+	_ = writer.Write([]string{"map", "level", "layer", "factions"})
+	_ = writer.Write([]string{"current", maps.Current.Level, maps.Current.Layer, strings.Join(maps.Current.Factions, " ")})
+	_ = writer.Write([]string{"next", maps.Next.Level, maps.Next.Layer, strings.Join(maps.Next.Factions, " ")})
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeServerInfoCSV(output io.Writer, info squadrcon.ServerInfo) error {
+	writer := csv.NewWriter(output)
+	_ = writer.Write([]string{"field", "value"})
+	_ = writer.Write([]string{"server_name", info.ServerName})
+	_ = writer.Write([]string{"game_version", info.GameVersion})
+	_ = writer.Write([]string{"player_count", strconv.Itoa(info.PlayerCount)})
+	_ = writer.Write([]string{"max_players", strconv.Itoa(info.MaxPlayers)})
+	_ = writer.Write([]string{"public_queue", strconv.Itoa(info.PublicQueue)})
+	_ = writer.Write([]string{"reserved_queue", strconv.Itoa(info.ReservedQueue)})
+	_ = writer.Write([]string{"current_layer", info.CurrentLayer})
+	_ = writer.Write([]string{"next_layer", info.NextLayer})
+	_ = writer.Write([]string{"team_one", info.TeamOne})
+	_ = writer.Write([]string{"team_two", info.TeamTwo})
+	_ = writer.Write([]string{"play_time_seconds", strconv.Itoa(int(info.PlayTime.Seconds()))})
+
+	writer.Flush()
+	return writer.Error()
+}
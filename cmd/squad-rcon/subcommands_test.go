@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"squad-rcon-go/pkg/rcon"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testPlayers = "----- Active Players -----\n" +
+		"ID: 0 | Online IDs: EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561197999957991 | Name: ✯RAIDR✯Jon | Team ID: 1 | Squad ID: 1 | Is Leader: True | Role: USA_SL_01\n" +
+		"ID: 1 | Online IDs: EOS: 0002b2c3d4e5f60718293a4b5c6d7e8f | Name: Some, Name | Team ID: 2 | Squad ID: N/A | Is Leader: False | Role: RGF_Rifleman_01\n" +
+		"----- Recently Disconnected Players [Max of 15] -----\n"
+
+	testDisconnectedPlayer = "ID: 2 | Online IDs: EOS: 0002c10186d9414496bf20d22d3860ba steam: 76561197989362395 | Since Disconnect: 01m.04s | Name: creaman\n"
+
+	testSquads = "----- Active Squads -----\n" +
+		"Team ID: 1 (United States Army)\n" +
+		"ID: 1 | Name: INF | Size: 1 | Locked: True | Creator Name: ✯RAIDR✯Jon | Creator Online IDs: EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561197999957991\n" +
+		"Team ID: 2 (Russian Ground Forces)\n"
+)
+
+// fakeRcon answers commands with canned responses and fails all other commands.
+type fakeRcon struct {
+	responses map[string]string
+	commands  []string
+}
+
+func (r *fakeRcon) Execute(command string) (string, error) {
+	r.commands = append(r.commands, command)
+
+	response, exists := r.responses[command]
+	if !exists {
+		return "", rcon.ErrConnectionClosed
+	}
+	return response, nil
+}
+
+func (r *fakeRcon) Close() error {
+	return nil
+}
+
+// runWithFormat runs the subcommand against a connection answering command with response.
+func runWithFormat(t *testing.T, name string, command string, response string, format outputFormat) (string, error) {
+	t.Helper()
+
+	subcommand, exists := findSubcommand(name)
+	if !exists {
+		t.Fatalf("unknown subcommand %s", name)
+	}
+
+	conn := &fakeRcon{responses: map[string]string{command: response}}
+	var output bytes.Buffer
+	err := subcommand.run(conn, nil, &output, format)
+
+	if !reflect.DeepEqual(conn.commands, []string{command}) {
+		t.Errorf("got commands %q, expected %s", conn.commands, command)
+	}
+	return output.String(), err
+}
+
+func readCSV(t *testing.T, output string) [][]string {
+	t.Helper()
+
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("could not read CSV %q: %v", output, err)
+	}
+	return records
+}
+
+func TestRunPlayers(t *testing.T) {
+	output, err := runWithFormat(t, "players", "ListPlayers", testPlayers+testDisconnectedPlayer, formatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records := readCSV(t, output)
+	if len(records) != 4 {
+		t.Fatalf("got %q, expected a header and 3 players", records)
+	}
+
+	disconnectedAt, err := time.Parse(time.RFC3339, records[3][8])
+	if since := time.Since(disconnectedAt); err != nil || since < 63*time.Second || since > 66*time.Second {
+		t.Errorf("got disconnect time %q, expected 64 seconds ago", records[3][8])
+	}
+	records[3][8] = ""
+
+	expected := [][]string{
+		{"match_id", "name", "steam_id", "eos_id", "team", "squad", "squad_lead", "kit", "disconnected_at"},
+		{"0", "✯RAIDR✯Jon", "76561197999957991", "0002a10186d9414496bf20d22d3860ba", "1", "1", "true", "USA_SL_01", ""},
+		{"1", "Some, Name", "", "0002b2c3d4e5f60718293a4b5c6d7e8f", "2", "0", "false", "RGF_Rifleman_01", ""},
+		{"2", "creaman", "76561197989362395", "0002c10186d9414496bf20d22d3860ba", "", "", "", "", ""},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("got %q, expected %q", records, expected)
+	}
+
+	output, err = runWithFormat(t, "players", "ListPlayers", testPlayers, formatJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		`"SteamId": "76561197999957991"`,
+		`"EosId": "0002b2c3d4e5f60718293a4b5c6d7e8f"`,
+		`"DisconnectedPlayers": []`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("got %s, expected it to contain %s", output, expected)
+		}
+	}
+
+	output, err = runWithFormat(t, "players", "ListPlayers", testPlayers, formatTable)
+	if err != nil || !strings.HasPrefix(output, "Active players (2)\n") || !strings.Contains(output, "Some, Name") {
+		t.Errorf("got %q, %v, expected a table of 2 players", output, err)
+	}
+}
+
+func TestRunSquads(t *testing.T) {
+	output, err := runWithFormat(t, "squads", "ListSquads", testSquads, formatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := [][]string{
+		{"team", "faction", "squad", "name", "size", "locked", "creator_name", "creator_steam_id", "creator_eos_id"},
+		{"1", "United States Army", "1", "INF", "1", "true", "✯RAIDR✯Jon", "76561197999957991", "0002a10186d9414496bf20d22d3860ba"},
+	}
+	if records := readCSV(t, output); !reflect.DeepEqual(records, expected) {
+		t.Errorf("got %q, expected %q", records, expected)
+	}
+
+	output, err = runWithFormat(t, "squads", "ListSquads", "----- Active Squads -----\n", formatJSON)
+	if err != nil || !strings.Contains(output, `"Teams": []`) || !strings.Contains(output, `"Squads": []`) {
+		t.Errorf("got %s, %v, expected empty arrays", output, err)
+	}
+
+	output, err = runWithFormat(t, "squads", "ListSquads", testSquads, formatTable)
+	if err != nil || !strings.HasPrefix(output, "Team 1: United States Army\n") || !strings.Contains(output, "Team 2: Russian Ground Forces\n") {
+		t.Errorf("got %q, %v, expected a table per team", output, err)
+	}
+}
+
+func TestRunParseErrors(t *testing.T) {
+	// The parsed part is printed before failing.
+	output, err := runWithFormat(t, "players", "ListPlayers", testPlayers+"ID: 3 | garbage\n", formatCSV)
+	if !errors.Is(err, errParse) {
+		t.Errorf("got %v, expected %v", err, errParse)
+	}
+	if records := readCSV(t, output); len(records) != 3 {
+		t.Errorf("got %q, expected the parsed players", records)
+	}
+
+	if _, err := runWithFormat(t, "squads", "ListSquads", "Unknown command", formatJSON); !errors.Is(err, errParse) {
+		t.Errorf("got %v, expected %v", err, errParse)
+	}
+
+	// Errors of the connection are not parse errors.
+	conn := &fakeRcon{}
+	if err := runPlayers(conn, nil, &bytes.Buffer{}, formatTable); !errors.Is(err, rcon.ErrConnectionClosed) || errors.Is(err, errParse) {
+		t.Errorf("got %v, expected %v", err, rcon.ErrConnectionClosed)
+	}
+}
+
+func TestRunSubcommandUsage(t *testing.T) {
+	// The server is not contacted for invalid arguments.
+	settings := connectionSettings{address: "127.0.0.1:0", timeout: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		arguments []string
+		exitCode  int
+	}{
+		{"json and csv", []string{"players", "--json", "--csv"}, exitUsage},
+		{"csv of exec", []string{"exec", "--csv", "ListPlayers"}, exitUsage},
+		{"missing argument", []string{"exec"}, exitUsage},
+		{"unknown subcommand", []string{"teams"}, exitUsage},
+		{"unknown flag", []string{"squads", "--xml"}, exitUsage},
+		{"help", []string{"squads", "-h"}, exitSuccess},
+	}
+
+	for _, test := range tests {
+		if exitCode := runSubcommand(settings, test.arguments); exitCode != test.exitCode {
+			t.Errorf("%s: got exit code %d, expected %d", test.name, exitCode, test.exitCode)
+		}
+	}
+}
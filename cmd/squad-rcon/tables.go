@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"squad-rcon-go/pkg/squadrcon"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// table writes aligned columns.
+type table struct {
+	writer *tabwriter.Writer
+}
+
+func newTable(output io.Writer, header ...string) *table {
+	t := &table{
+		writer: tabwriter.NewWriter(output, 0, 0, 2, ' ', 0),
+	}
+	t.row(header...)
+	return t
+}
+
+func (t *table) row(columns ...string) {
+	fmt.Fprintln(t.writer, strings.Join(columns, "\t"))
+}
+
+func (t *table) flush() {
+	_ = t.writer.Flush()
+}
+
+// formatResponse returns the response of the command as tables if the command has a known
+// response format. Returns false if the response cannot be formatted.
+func formatResponse(command string, response string) (string, bool) {
+	name, _, _ := strings.Cut(strings.TrimSpace(command), " ")
+	var builder strings.Builder
+
+	switch strings.ToLower(name) {
+	case "listplayers":
+		list, err := squadrcon.ParsePlayersList(response)
+		if err != nil {
+			return "", false
+		}
+		writePlayerList(&builder, list)
+	case "listsquads":
+		list, err := squadrcon.ParseSquadList(response)
+		if err != nil {
+			return "", false
+		}
+		writeSquadList(&builder, list)
+	case "showcurrentmap":
+		layer, err := squadrcon.ParseCurrentMap(response)
+		if err != nil {
+			return "", false
+		}
+		writeLayerInfo(&builder, layer)
+	case "shownextmap":
+		layer, err := squadrcon.ParseNextMap(response)
+		if err != nil {
+			return "", false
+		}
+		writeLayerInfo(&builder, layer)
+	case "showserverinfo":
+		info, err := squadrcon.ParseServerInfo(response)
+		if err != nil {
+			return "", false
+		}
+		writeServerInfo(&builder, info)
+	case "listcommands":
+		commands, err := squadrcon.ParseCommandList(response)
+		if err != nil {
+			return "", false
+		}
+		writeCommands(&builder, commands)
+	default:
+		return "", false
+	}
+
+	return builder.String(), true
+}
+
+func writePlayerList(output io.Writer, list squadrcon.PlayerList) {
+	fmt.Fprintf(output, "Active players (%d)\n", len(list.ActivePlayers))
+	t := newTable(output, "ID", "NAME", "TEAM", "SQUAD", "LEAD", "KIT", "STEAM ID", "EOS ID")
+	for _, player := range list.ActivePlayers {
+		t.row(
+			strconv.Itoa(player.MatchId),
+			player.Name,
+			strconv.Itoa(player.TeamIndex),
+			optionalInt(player.SquadIndex),
+			yesNo(player.IsSquadLead),
+			player.Kit,
+			player.SteamId.String(),
+			player.EosId.String(),
+		)
+	}
+	t.flush()
+
+	if len(list.DisconnectedPlayers) == 0 {
+		return
+	}
+
+	fmt.Fprintf(output, "\nRecently disconnected (%d)\n", len(list.DisconnectedPlayers))
+	t = newTable(output, "ID", "NAME", "DISCONNECTED", "STEAM ID", "EOS ID")
+	for _, player := range list.DisconnectedPlayers {
+		t.row(
+			strconv.Itoa(player.MatchId),
+			player.Name,
+			player.DisconnectTime.Format(time.TimeOnly),
+			player.SteamId.String(),
+			player.EosId.String(),
+		)
+	}
+	t.flush()
+}
+
+func writeSquadList(output io.Writer, list squadrcon.SquadList) {
+	for index, team := range list.Teams {
+		if index > 0 {
+			fmt.Fprintln(output)
+		}
+		fmt.Fprintf(output, "Team %d: %s\n", team.Index, team.Faction)
+
+		t := newTable(output, "ID", "NAME", "SIZE", "LOCKED", "CREATOR", "CREATOR ID")
+		for _, squad := range list.Squads {
+			if squad.TeamIndex != team.Index {
+				continue
+			}
+
+			creatorId := squad.CreatorSteamId.String()
+			if creatorId == "" {
+				creatorId = squad.CreatorEosId.String()
+			}
+
+			t.row(
+				strconv.Itoa(squad.Id),
+				squad.Name,
+				strconv.Itoa(squad.Size),
+				yesNo(squad.Locked),
+				squad.CreatorName,
+				creatorId,
+			)
+		}
+		t.flush()
+	}
+}
+
+func writeLayerInfo(output io.Writer, layer squadrcon.LayerInfo) {
+	t := newTable(output, "LEVEL", "LAYER", "FACTIONS")
+	t.row(layer.Level, layer.Layer, strings.Join(layer.Factions, " vs "))
+	t.flush()
+}
+
+func writeMaps(output io.Writer, maps currentAndNextMap) {
+	t := newTable(output, "MAP", "LEVEL", "LAYER", "FACTIONS")
+	t.row("current", maps.Current.Level, maps.Current.Layer, strings.Join(maps.Current.Factions, " vs "))
+	t.row("next", maps.Next.Level, maps.Next.Layer, strings.Join(maps.Next.Factions, " vs "))
+	t.flush()
+}
+
+func writeServerInfo(output io.Writer, info squadrcon.ServerInfo) {
+	t := newTable(output, "FIELD", "VALUE")
+	t.row("Server name", info.ServerName)
+	t.row("Game version", info.GameVersion)
+	t.row("Players", fmt.Sprintf("%d/%d", info.PlayerCount, info.MaxPlayers))
+	t.row("Queue", fmt.Sprintf("%d public, %d reserved", info.PublicQueue, info.ReservedQueue))
+	t.row("Current layer", info.CurrentLayer)
+	t.row("Next layer", info.NextLayer)
+	t.row("Teams", info.TeamOne+" vs "+info.TeamTwo)
+	t.row("Play time", info.PlayTime.String())
+	t.flush()
+}
+
+func writeCommands(output io.Writer, commands []squadrcon.Command) {
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+
+	t := newTable(output, "COMMAND", "ARGUMENTS", "HELP")
+	for _, command := range commands {
+		arguments := make([]string, len(command.Arguments))
+		for i, argument := range command.Arguments {
+			arguments[i] = "<" + argument + ">"
+		}
+		t.row(command.Name, strings.Join(arguments, " "), command.Help)
+	}
+	t.flush()
+}
+
+func optionalInt(value int) string {
+	if value == 0 {
+		return "-"
+	}
+	return strconv.Itoa(value)
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,22 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package main
+
+import "errors"
+
+type terminalState struct{}
+
+var errRawModeUnsupported = errors.New("raw terminal mode is not supported on this platform")
+
+// makeRaw is not supported, the line editor falls back to reading whole lines.
+func makeRaw(fd int) (*terminalState, error) {
+	return nil, errRawModeUnsupported
+}
+
+func restoreTerminal(fd int, state *terminalState) error {
+	return nil
+}
+
+func isTerminal(fd int) bool {
+	return false
+}
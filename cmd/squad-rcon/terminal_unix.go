@@ -0,0 +1,53 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminalState is the terminal configuration before entering raw mode.
+type terminalState struct {
+	termios syscall.Termios
+}
+
+// makeRaw puts the terminal in raw mode, in which input is passed on per key without echo.
+// Output processing is kept, so `\n` still starts a new line.
+func makeRaw(fd int) (*terminalState, error) {
+	var termios syscall.Termios
+	if err := ioctlTermios(fd, ioctlGetTermios, &termios); err != nil {
+		return nil, err
+	}
+
+	state := &terminalState{termios: termios}
+
+	termios.Iflag &^= syscall.BRKINT | syscall.ICRNL | syscall.INPCK | syscall.ISTRIP | syscall.IXON
+	termios.Cflag |= syscall.CS8
+	termios.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.IEXTEN | syscall.ISIG
+	termios.Cc[syscall.VMIN] = 1
+	termios.Cc[syscall.VTIME] = 0
+
+	if err := ioctlTermios(fd, ioctlSetTermios, &termios); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func restoreTerminal(fd int, state *terminalState) error {
+	return ioctlTermios(fd, ioctlSetTermios, &state.termios)
+}
+
+func isTerminal(fd int) bool {
+	var termios syscall.Termios
+	return ioctlTermios(fd, ioctlGetTermios, &termios) == nil
+}
+
+func ioctlTermios(fd int, request uintptr, termios *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, uintptr(unsafe.Pointer(termios)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
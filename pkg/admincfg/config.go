@@ -0,0 +1,420 @@
+package admincfg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"squad-rcon-go/pkg/cfgfile"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidGroupLine = errors.New("invalid Group line")
+	ErrInvalidAdminLine = errors.New("invalid Admin line")
+	ErrGroupNotFound    = errors.New("group not found")
+)
+
+// Group is a `Group=<name>:<permission>,<permission>` line.
+type Group struct {
+	Name        string
+	Permissions PermissionSet
+
+	// Comment is the text after `//` on the same line, without the slashes.
+	Comment string
+}
+
+// Admin is an `Admin=<id>:<group>` line. Exactly one of SteamId and EosId is set.
+type Admin struct {
+	SteamId squadrcon.SteamID64
+	EosId   squadrcon.EOSID
+	Group   string
+
+	// Comment is the text after `//` on the same line, without the slashes. Commonly contains the
+	// name of the admin.
+	Comment string
+}
+
+// Id returns the ID of the admin as written in the config.
+func (a Admin) Id() string {
+	if a.SteamId != 0 {
+		return a.SteamId.String()
+	}
+	return a.EosId.String()
+}
+
+// entry is a line of the config. Lines other than Group and Admin lines, including comments and
+// invalid lines, are kept as is.
+type entry struct {
+	raw   string
+	group *Group
+	admin *Admin
+
+	// The formatted line at the time of parsing. The raw line is written as long as the entry
+	// formats the same, which preserves its original spacing and permission order.
+	original string
+}
+
+func (e *entry) String() string {
+	switch {
+	case e.group != nil:
+		if formatted := formatGroup(*e.group); formatted != e.original {
+			return formatted
+		}
+	case e.admin != nil:
+		if formatted := formatAdmin(*e.admin); formatted != e.original {
+			return formatted
+		}
+	}
+	return e.raw
+}
+
+// Config is the content of an Admins.cfg file. It is safe for concurrent use.
+type Config struct {
+	// Lock to be used before accessing entries.
+	lock sync.RWMutex
+
+	entries []*entry
+
+	// Written after every line, see cfgfile.JoinLines.
+	lineEnding string
+}
+
+// ReadFile reads and parses an Admins.cfg file.
+func ReadFile(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse parses the content of an Admins.cfg file. Invalid lines are kept as is and reported in the
+// returned error, the config is returned regardless.
+func Parse(reader io.Reader) (*Config, error) {
+	lines, lineEnding, err := cfgfile.ReadLines(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{lineEnding: lineEnding}
+	var errs []error
+
+	for i, line := range lines {
+		entry, err := parseLine(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", i+1, err))
+		}
+		config.entries = append(config.entries, entry)
+	}
+
+	return config, errors.Join(errs...)
+}
+
+func parseLine(line string) (*entry, error) {
+	e := &entry{raw: line}
+
+	content, comment := splitComment(line)
+	content = strings.TrimSpace(content)
+
+	key, value, found := strings.Cut(content, "=")
+	if !found {
+		return e, nil
+	}
+
+	switch strings.TrimSpace(key) {
+	case "Group":
+		group, err := parseGroup(value, comment)
+		if err != nil {
+			return e, err
+		}
+		e.group = &group
+		e.original = formatGroup(group)
+	case "Admin":
+		admin, err := parseAdmin(value, comment)
+		if err != nil {
+			return e, err
+		}
+		e.admin = &admin
+		e.original = formatAdmin(admin)
+	}
+
+	return e, nil
+}
+
+func parseGroup(value string, comment string) (Group, error) {
+	name, permissions, found := strings.Cut(value, ":")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return Group{}, fmt.Errorf("%w: %s", ErrInvalidGroupLine, value)
+	}
+
+	group := Group{
+		Name:        name,
+		Permissions: NewPermissionSet(),
+		Comment:     comment,
+	}
+
+	for _, permission := range strings.Split(permissions, ",") {
+		if permission = strings.TrimSpace(permission); permission != "" {
+			group.Permissions.Add(Permission(permission))
+		}
+	}
+
+	return group, nil
+}
+
+func parseAdmin(value string, comment string) (Admin, error) {
+	id, group, found := strings.Cut(value, ":")
+	id = strings.TrimSpace(id)
+	group = strings.TrimSpace(group)
+	if !found || id == "" || group == "" {
+		return Admin{}, fmt.Errorf("%w: %s", ErrInvalidAdminLine, value)
+	}
+
+	admin := Admin{
+		Group:   group,
+		Comment: comment,
+	}
+
+	var err error
+	if steamId, steamErr := squadrcon.ParseSteamID64(id); steamErr == nil {
+		admin.SteamId = steamId
+	} else if admin.EosId, err = squadrcon.ParseEOSID(id); err != nil {
+		return Admin{}, fmt.Errorf("%w: %s is neither a Steam ID nor an EOS ID", ErrInvalidAdminLine, id)
+	}
+
+	return admin, nil
+}
+
+// splitComment returns the content of the line and the trimmed comment after `//`, if any.
+func splitComment(line string) (string, string) {
+	content, comment, found := strings.Cut(line, "//")
+	if !found {
+		return line, ""
+	}
+	return content, strings.TrimSpace(comment)
+}
+
+func formatGroup(group Group) string {
+	permissions := make([]string, 0, len(group.Permissions))
+	for _, permission := range group.Permissions.List() {
+		permissions = append(permissions, string(permission))
+	}
+
+	return withComment(fmt.Sprintf("Group=%s:%s", group.Name, strings.Join(permissions, ",")), group.Comment)
+}
+
+func formatAdmin(admin Admin) string {
+	return withComment(fmt.Sprintf("Admin=%s:%s", admin.Id(), admin.Group), admin.Comment)
+}
+
+func withComment(line string, comment string) string {
+	if comment == "" {
+		return line
+	}
+	return line + " // " + comment
+}
+
+// WriteTo writes the config in the Admins.cfg format. Unchanged lines are written as they were
+// read, using the line ending of the parsed file.
+func (c *Config) WriteTo(writer io.Writer) (int64, error) {
+	n, err := io.WriteString(writer, c.String())
+	return int64(n), err
+}
+
+// WriteFile writes the config to a file, replacing its content.
+func (c *Config) WriteFile(path string) error {
+	return os.WriteFile(path, []byte(c.String()), 0644)
+}
+
+func (c *Config) String() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	lines := make([]string, 0, len(c.entries))
+	for _, entry := range c.entries {
+		lines = append(lines, entry.String())
+	}
+	return cfgfile.JoinLines(lines, c.lineEnding)
+}
+
+// Groups returns all groups in the order they are defined.
+func (c *Config) Groups() []Group {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var groups []Group
+	for _, entry := range c.entries {
+		if entry.group != nil {
+			groups = append(groups, copyGroup(*entry.group))
+		}
+	}
+	return groups
+}
+
+// Group returns the group with the name. Group names are case-sensitive.
+func (c *Config) Group(name string) (Group, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if entry := c.findGroup(name); entry != nil {
+		return copyGroup(*entry.group), true
+	}
+	return Group{}, false
+}
+
+// SetGroup replaces the group with the same name, or adds it after the last group.
+func (c *Config) SetGroup(group Group) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	group = copyGroup(group)
+
+	if existing := c.findGroup(group.Name); existing != nil {
+		existing.group = &group
+		return
+	}
+
+	insertAt := 0
+	for index, entry := range c.entries {
+		if entry.group != nil {
+			insertAt = index + 1
+		}
+	}
+
+	c.insert(insertAt, &entry{group: &group})
+}
+
+// RemoveGroup removes the group and all admins of the group. Returns ErrGroupNotFound if there is
+// no such group.
+func (c *Config) RemoveGroup(name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.findGroup(name) == nil {
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, name)
+	}
+
+	c.removeWhere(func(entry *entry) bool {
+		return (entry.group != nil && entry.group.Name == name) ||
+			(entry.admin != nil && entry.admin.Group == name)
+	})
+
+	return nil
+}
+
+// Admins returns all admins in the order they are defined. Players that are in multiple groups are
+// returned once per group.
+func (c *Config) Admins() []Admin {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var admins []Admin
+	for _, entry := range c.entries {
+		if entry.admin != nil {
+			admins = append(admins, *entry.admin)
+		}
+	}
+	return admins
+}
+
+// AddAdmin adds the admin at the end of the config, unless the player is already in the group.
+// Returns ErrGroupNotFound if the group does not exist.
+func (c *Config) AddAdmin(admin Admin) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.findGroup(admin.Group) == nil {
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, admin.Group)
+	}
+
+	for _, entry := range c.entries {
+		if entry.admin != nil && entry.admin.Group == admin.Group && entry.admin.Id() == admin.Id() {
+			return nil
+		}
+	}
+
+	c.entries = append(c.entries, &entry{admin: &admin})
+	return nil
+}
+
+// RemoveAdmin removes the player from the group, or from all groups if group is empty. Returns
+// whether the player was removed from any group.
+func (c *Config) RemoveAdmin(id string, group string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.removeWhere(func(entry *entry) bool {
+		return entry.admin != nil &&
+			entry.admin.Id() == id &&
+			(group == "" || entry.admin.Group == group)
+	})
+}
+
+// Permissions returns the combined permissions of all groups the player is in. Either ID can be
+// empty.
+func (c *Config) Permissions(steamId squadrcon.SteamID64, eosId squadrcon.EOSID) PermissionSet {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	permissions := NewPermissionSet()
+	for _, entry := range c.entries {
+		if entry.admin == nil || !matchesPlayer(*entry.admin, steamId, eosId) {
+			continue
+		}
+
+		if group := c.findGroup(entry.admin.Group); group != nil {
+			permissions = permissions.Union(group.group.Permissions)
+		}
+	}
+
+	return permissions
+}
+
+// HasPermission returns whether any group of the player grants the permission.
+func (c *Config) HasPermission(steamId squadrcon.SteamID64, eosId squadrcon.EOSID, permission Permission) bool {
+	return c.Permissions(steamId, eosId).Has(permission)
+}
+
+func matchesPlayer(admin Admin, steamId squadrcon.SteamID64, eosId squadrcon.EOSID) bool {
+	return (steamId != 0 && admin.SteamId == steamId) || (eosId != "" && admin.EosId == eosId)
+}
+
+func (c *Config) findGroup(name string) *entry {
+	for _, entry := range c.entries {
+		if entry.group != nil && entry.group.Name == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+func (c *Config) insert(index int, e *entry) {
+	c.entries = append(c.entries, nil)
+	copy(c.entries[index+1:], c.entries[index:])
+	c.entries[index] = e
+}
+
+func (c *Config) removeWhere(remove func(entry *entry) bool) bool {
+	kept := c.entries[:0]
+	removed := false
+	for _, entry := range c.entries {
+		if remove(entry) {
+			removed = true
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+	c.entries = kept
+	return removed
+}
+
+func copyGroup(group Group) Group {
+	group.Permissions = NewPermissionSet().Union(group.Permissions)
+	return group
+}
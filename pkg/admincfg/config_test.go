@@ -0,0 +1,179 @@
+package admincfg
+
+import (
+	"errors"
+	"reflect"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"testing"
+)
+
+const sampleConfig = `// Groups
+Group=Admin:changemap, pause,balance,chat,kick,ban,cameraman,teamchange,forceteamchange,canseeadminchat
+Group=Whitelist:reserve // Supporters
+
+Admin=76561197999957991:Admin // Jon
+Admin=0002a10186d9414496bf20d22d3860ba:Whitelist
+Admin=76561197989362395:Whitelist //creaman
+`
+
+func TestParse(t *testing.T) {
+	config, err := Parse(strings.NewReader(sampleConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	groups := config.Groups()
+	if len(groups) != 2 || groups[0].Name != "Admin" || groups[1].Name != "Whitelist" || groups[1].Comment != "Supporters" {
+		t.Fatalf("got groups %+v", groups)
+	}
+	if !groups[0].Permissions.Has(PermissionPause) || groups[0].Permissions.Has(PermissionReserve) {
+		t.Errorf("got permissions %v", groups[0].Permissions.List())
+	}
+
+	expected := []Admin{
+		{SteamId: 76561197999957991, Group: "Admin", Comment: "Jon"},
+		{EosId: "0002a10186d9414496bf20d22d3860ba", Group: "Whitelist"},
+		{SteamId: 76561197989362395, Group: "Whitelist", Comment: "creaman"},
+	}
+	if admins := config.Admins(); !reflect.DeepEqual(admins, expected) {
+		t.Errorf("got admins %+v, expected %+v", admins, expected)
+	}
+}
+
+func TestParseInvalidLines(t *testing.T) {
+	content := "Group=:kick\nAdmin=12:Admin\nAdmin=76561197999957991\nGroup=Admin:kick\n"
+	config, err := Parse(strings.NewReader(content))
+
+	if !errors.Is(err, ErrInvalidGroupLine) || !errors.Is(err, ErrInvalidAdminLine) {
+		t.Errorf("got %v, expected invalid group and admin lines", err)
+	}
+	if groups := config.Groups(); len(groups) != 1 {
+		t.Errorf("expected the valid group to be parsed, got %+v", groups)
+	}
+
+	// Invalid lines are kept as is.
+	if config.String() != content {
+		t.Errorf("got %q, expected %q", config.String(), content)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "LF", content: sampleConfig},
+		{name: "CRLF", content: strings.ReplaceAll(sampleConfig, "\n", "\r\n")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := Parse(strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if config.String() != test.content {
+				t.Errorf("got %q, expected %q", config.String(), test.content)
+			}
+
+			// Changed and added lines use the line ending of the file.
+			lineEnding := "\n"
+			if strings.Contains(test.content, "\r\n") {
+				lineEnding = "\r\n"
+			}
+
+			if err := config.AddAdmin(Admin{SteamId: 76561197960265730, Group: "Admin"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			config.RemoveAdmin("0002a10186d9414496bf20d22d3860ba", "")
+
+			expected := strings.Replace(test.content, "Admin=0002a10186d9414496bf20d22d3860ba:Whitelist"+lineEnding, "", 1) +
+				"Admin=76561197960265730:Admin" + lineEnding
+			if config.String() != expected {
+				t.Errorf("got %q, expected %q", config.String(), expected)
+			}
+		})
+	}
+}
+
+func TestMixedLineEndings(t *testing.T) {
+	// The first line was added by an editor using LF, the other lines use CRLF.
+	content := "// Admins\n" + strings.ReplaceAll(strings.TrimPrefix(sampleConfig, "// Groups\n"), "\n", "\r\n")
+	config, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := config.AddAdmin(Admin{SteamId: 76561197960265730, Group: "Admin"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "// Admins\r\n" + strings.ReplaceAll(strings.TrimPrefix(sampleConfig, "// Groups\n"), "\n", "\r\n") +
+		"Admin=76561197960265730:Admin\r\n"
+	if config.String() != expected {
+		t.Errorf("got %q, expected the line ending of the majority of the lines %q", config.String(), expected)
+	}
+}
+
+func TestSetGroup(t *testing.T) {
+	config, err := Parse(strings.NewReader(sampleConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config.SetGroup(Group{Name: "Moderator", Permissions: NewPermissionSet(PermissionKick, PermissionChat)})
+	group, _ := config.Group("Whitelist")
+	group.Permissions.Add(PermissionStartVote)
+	config.SetGroup(group)
+
+	expected := strings.Replace(sampleConfig, "Group=Whitelist:reserve // Supporters\n",
+		"Group=Whitelist:startvote,reserve // Supporters\nGroup=Moderator:chat,kick\n", 1)
+	if config.String() != expected {
+		t.Errorf("got %q, expected %q", config.String(), expected)
+	}
+
+	if err := config.AddAdmin(Admin{SteamId: 76561197999957991, Group: "Unknown"}); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("got %v, expected %v", err, ErrGroupNotFound)
+	}
+
+	if err := config.RemoveGroup("Whitelist"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if admins := config.Admins(); len(admins) != 1 || admins[0].Group != "Admin" {
+		t.Errorf("expected the admins of the group to be removed, got %+v", admins)
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	config, err := Parse(strings.NewReader(sampleConfig + "Admin=76561197989362395:Admin\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		steamId    squadrcon.SteamID64
+		eosId      squadrcon.EOSID
+		permission Permission
+		expected   bool
+	}{
+		{name: "group permission", steamId: 76561197999957991, permission: PermissionKick, expected: true},
+		{name: "missing permission", steamId: 76561197999957991, permission: PermissionReserve},
+		{name: "EOS ID", eosId: "0002a10186d9414496bf20d22d3860ba", permission: PermissionReserve, expected: true},
+		{name: "multiple groups", steamId: 76561197989362395, permission: PermissionReserve, expected: true},
+		{name: "case-insensitive", steamId: 76561197989362395, permission: "KICK", expected: true},
+		{name: "unknown player", steamId: 76561197960265730, permission: PermissionReserve},
+		{name: "no IDs", permission: PermissionReserve},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			has := config.HasPermission(test.steamId, test.eosId, test.permission)
+			if has != test.expected {
+				t.Errorf("got %t, expected %t", has, test.expected)
+			}
+		})
+	}
+}
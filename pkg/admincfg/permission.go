@@ -0,0 +1,137 @@
+package admincfg
+
+import (
+	"sort"
+	"strings"
+)
+
+// Permission is a named permission that can be granted to a group.
+type Permission string
+
+const (
+	PermissionStartVote       Permission = "startvote"
+	PermissionChangeMap       Permission = "changemap"
+	PermissionPause           Permission = "pause"
+	PermissionCheat           Permission = "cheat"
+	PermissionPrivate         Permission = "private"
+	PermissionBalance         Permission = "balance"
+	PermissionChat            Permission = "chat"
+	PermissionKick            Permission = "kick"
+	PermissionBan             Permission = "ban"
+	PermissionConfig          Permission = "config"
+	PermissionCameraman       Permission = "cameraman"
+	PermissionImmune          Permission = "immune"
+	PermissionManageServer    Permission = "manageserver"
+	PermissionFeatureTest     Permission = "featuretest"
+	PermissionReserve         Permission = "reserve"
+	PermissionDemos           Permission = "demos"
+	PermissionClientDemos     Permission = "clientdemos"
+	PermissionDebug           Permission = "debug"
+	PermissionTeamChange      Permission = "teamchange"
+	PermissionForceTeamChange Permission = "forceteamchange"
+	PermissionCanSeeAdminChat Permission = "canseeadminchat"
+)
+
+// KnownPermissions contains the permissions known to the server, in the order they are documented.
+var KnownPermissions = []Permission{
+	PermissionStartVote,
+	PermissionChangeMap,
+	PermissionPause,
+	PermissionCheat,
+	PermissionPrivate,
+	PermissionBalance,
+	PermissionChat,
+	PermissionKick,
+	PermissionBan,
+	PermissionConfig,
+	PermissionCameraman,
+	PermissionImmune,
+	PermissionManageServer,
+	PermissionFeatureTest,
+	PermissionReserve,
+	PermissionDemos,
+	PermissionClientDemos,
+	PermissionDebug,
+	PermissionTeamChange,
+	PermissionForceTeamChange,
+	PermissionCanSeeAdminChat,
+}
+
+// IsKnown returns whether the server knows the permission. Unknown permissions are kept when
+// reading and writing a config, but the server ignores them.
+func (p Permission) IsKnown() bool {
+	return permissionOrder(p) < len(KnownPermissions)
+}
+
+// PermissionSet is a set of permissions. Permissions are compared case-insensitively, like the
+// server does.
+type PermissionSet map[Permission]struct{}
+
+func NewPermissionSet(permissions ...Permission) PermissionSet {
+	set := make(PermissionSet, len(permissions))
+	for _, permission := range permissions {
+		set.Add(permission)
+	}
+	return set
+}
+
+func (s PermissionSet) Has(permission Permission) bool {
+	_, exists := s[normalizePermission(permission)]
+	return exists
+}
+
+// Add adds the permission. The set must not be nil.
+func (s PermissionSet) Add(permission Permission) {
+	s[normalizePermission(permission)] = struct{}{}
+}
+
+func (s PermissionSet) Remove(permission Permission) {
+	delete(s, normalizePermission(permission))
+}
+
+// Union returns a new set with the permissions of both sets.
+func (s PermissionSet) Union(other PermissionSet) PermissionSet {
+	union := make(PermissionSet, len(s)+len(other))
+	for permission := range s {
+		union[permission] = struct{}{}
+	}
+	for permission := range other {
+		union[permission] = struct{}{}
+	}
+	return union
+}
+
+// List returns the permissions, known permissions first in documented order, followed by unknown
+// permissions in alphabetical order.
+func (s PermissionSet) List() []Permission {
+	permissions := make([]Permission, 0, len(s))
+	for permission := range s {
+		permissions = append(permissions, permission)
+	}
+
+	sort.Slice(permissions, func(i, j int) bool {
+		orderI, orderJ := permissionOrder(permissions[i]), permissionOrder(permissions[j])
+		if orderI != orderJ {
+			return orderI < orderJ
+		}
+		return permissions[i] < permissions[j]
+	})
+
+	return permissions
+}
+
+func normalizePermission(permission Permission) Permission {
+	return Permission(strings.ToLower(strings.TrimSpace(string(permission))))
+}
+
+// permissionOrder returns the index of the permission in KnownPermissions, or the length of
+// KnownPermissions for unknown permissions.
+func permissionOrder(permission Permission) int {
+	permission = normalizePermission(permission)
+	for index, known := range KnownPermissions {
+		if known == permission {
+			return index
+		}
+	}
+	return len(KnownPermissions)
+}
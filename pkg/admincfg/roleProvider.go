@@ -0,0 +1,15 @@
+package admincfg
+
+import (
+	"squad-rcon-go/pkg/chatcommands"
+	"squad-rcon-go/pkg/squadrcon"
+)
+
+// RoleProvider returns the config as chatcommands.RoleProvider. The permissions of chat commands
+// are matched against the permissions of the config, e.g. a command with permission `kick` can be
+// used by all players in a group with the kick permission.
+func (c *Config) RoleProvider() chatcommands.RoleProvider {
+	return chatcommands.RoleProviderFunc(func(steamId squadrcon.SteamID64, eosId squadrcon.EOSID, permission chatcommands.Permission) bool {
+		return c.HasPermission(steamId, eosId, Permission(permission))
+	})
+}
@@ -0,0 +1,190 @@
+package bancfg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"squad-rcon-go/pkg/squadrcon"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidBanLine = errors.New("invalid ban line")
+)
+
+// Ban is a line of Bans.cfg, e.g.
+// `Jon [SteamID 76561197999957991] Banned:76561197989362395:1700000000 //Team killing`.
+type Ban struct {
+	// AdminName and AdminId identify the admin that issued the ban. Both are empty for bans
+	// written without admin.
+	AdminName string
+	AdminId   string
+
+	// Exactly one of SteamId and EosId is set.
+	SteamId squadrcon.SteamID64
+	EosId   squadrcon.EOSID
+
+	// Expires is zero for permanent bans.
+	Expires time.Time
+
+	Reason string
+}
+
+// Id returns the ID of the banned player.
+func (b Ban) Id() string {
+	if b.SteamId != 0 {
+		return b.SteamId.String()
+	}
+	return b.EosId.String()
+}
+
+func (b Ban) IsPermanent() bool {
+	return b.Expires.IsZero()
+}
+
+// IsExpired returns whether the ban is no longer in effect at the time.
+func (b Ban) IsExpired(at time.Time) bool {
+	return !b.IsPermanent() && !at.Before(b.Expires)
+}
+
+// String formats the ban as a Bans.cfg line.
+func (b Ban) String() string {
+	var expires int64
+	if !b.IsPermanent() {
+		expires = b.Expires.Unix()
+	}
+
+	line := fmt.Sprintf("%s:%d", b.Id(), expires)
+
+	if b.AdminId != "" || b.AdminName != "" {
+		idType := "SteamID"
+		if _, err := squadrcon.ParseSteamID64(b.AdminId); err != nil && b.AdminId != "" {
+			idType = "EOSID"
+		}
+		line = fmt.Sprintf("%s [%s %s] Banned:%s", b.AdminName, idType, b.AdminId, line)
+	}
+
+	if b.Reason != "" {
+		line += " //" + b.Reason
+	}
+
+	return line
+}
+
+var banRegex = regexp.MustCompile(`^(?:(.*?) ?\[(?:SteamID|EOSID) ?([^\]]*)\] ?Banned:)?([0-9a-fA-F]+):(\d+)\s*(?://(.*))?$`)
+
+const (
+	_ = iota
+	banAdminName
+	banAdminId
+	banBannedId
+	banExpires
+	banReason
+)
+
+// ReadFile reads and parses a Bans.cfg file.
+func ReadFile(path string) ([]Ban, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse parses the content of a Bans.cfg file. Empty lines and comments are skipped. Invalid lines
+// are reported in the returned error, the valid bans are returned regardless.
+func Parse(reader io.Reader) ([]Ban, error) {
+	var bans []Ban
+	var errs []error
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		ban, err := ParseBan(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", lineNumber, err))
+			continue
+		}
+		bans = append(bans, ban)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return bans, errors.Join(errs...)
+}
+
+// ParseBan parses a single Bans.cfg line.
+func ParseBan(line string) (Ban, error) {
+	matches := banRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if matches == nil {
+		return Ban{}, fmt.Errorf("%w: %s", ErrInvalidBanLine, line)
+	}
+
+	ban := Ban{
+		AdminName: strings.TrimSpace(matches[banAdminName]),
+		AdminId:   strings.TrimSpace(matches[banAdminId]),
+		Reason:    strings.TrimSpace(matches[banReason]),
+	}
+
+	bannedId := matches[banBannedId]
+	if steamId, err := squadrcon.ParseSteamID64(bannedId); err == nil {
+		ban.SteamId = steamId
+	} else if eosId, err := squadrcon.ParseEOSID(bannedId); err == nil {
+		ban.EosId = eosId
+	} else {
+		return Ban{}, fmt.Errorf("%w: %s is neither a Steam ID nor an EOS ID", ErrInvalidBanLine, bannedId)
+	}
+
+	expires, err := strconv.ParseInt(matches[banExpires], 10, 64)
+	if err != nil {
+		return Ban{}, fmt.Errorf("%w: %s", ErrInvalidBanLine, err)
+	}
+	if expires != 0 {
+		ban.Expires = time.Unix(expires, 0).UTC()
+	}
+
+	return ban, nil
+}
+
+// Format formats the bans in the Bans.cfg format.
+func Format(bans []Ban) string {
+	var builder strings.Builder
+	for _, ban := range bans {
+		builder.WriteString(ban.String())
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// WriteFile writes the bans to a file, replacing its content.
+func WriteFile(path string, bans []Ban) error {
+	return os.WriteFile(path, []byte(Format(bans)), 0644)
+}
+
+// RemoveExpired splits the bans into bans that are still in effect at the time and expired bans.
+func RemoveExpired(bans []Ban, at time.Time) (active []Ban, expired []Ban) {
+	for _, ban := range bans {
+		if ban.IsExpired(at) {
+			expired = append(expired, ban)
+		} else {
+			active = append(active, ban)
+		}
+	}
+	return
+}
@@ -0,0 +1,130 @@
+package bancfg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBan(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Ban
+	}{
+		{
+			line: "Jon [SteamID 76561197999957991] Banned:76561197989362395:1700000000 //Team killing",
+			expected: Ban{
+				AdminName: "Jon",
+				AdminId:   "76561197999957991",
+				SteamId:   76561197989362395,
+				Expires:   time.Unix(1700000000, 0).UTC(),
+				Reason:    "Team killing",
+			},
+		},
+		{
+			line:     "76561197989362395:0",
+			expected: Ban{SteamId: 76561197989362395},
+		},
+		{
+			line: "Jon [EOSID 0002a10186d9414496bf20d22d3860ba] Banned:0002b2c3d4e5f60718293a4b5c6d7e8f:0 // Cheating",
+			expected: Ban{
+				AdminName: "Jon",
+				AdminId:   "0002a10186d9414496bf20d22d3860ba",
+				EosId:     "0002b2c3d4e5f60718293a4b5c6d7e8f",
+				Reason:    "Cheating",
+			},
+		},
+		{
+			line: "[TWS] Jon | Main [SteamID 76561197999957991] Banned:76561197989362395:0 //Reason with // slashes",
+			expected: Ban{
+				AdminName: "[TWS] Jon | Main",
+				AdminId:   "76561197999957991",
+				SteamId:   76561197989362395,
+				Reason:    "Reason with // slashes",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			ban, err := ParseBan(test.line)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(ban, test.expected) {
+				t.Errorf("got %+v, expected %+v", ban, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseBanInvalid(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"76561197989362395",
+		"76561197989362395:never",
+		"12:0",
+		"Banned:76561197989362395:0",
+	} {
+		if _, err := ParseBan(line); !errors.Is(err, ErrInvalidBanLine) {
+			t.Errorf("%q: got %v, expected %v", line, err, ErrInvalidBanLine)
+		}
+	}
+}
+
+func TestBanString(t *testing.T) {
+	for _, line := range []string{
+		"Jon [SteamID 76561197999957991] Banned:76561197989362395:1700000000 //Team killing",
+		"76561197989362395:0",
+		"Jon [EOSID 0002a10186d9414496bf20d22d3860ba] Banned:0002b2c3d4e5f60718293a4b5c6d7e8f:0 //Cheating",
+	} {
+		ban, err := ParseBan(line)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", line, err)
+		}
+
+		if ban.String() != line {
+			t.Errorf("got %q, expected %q", ban.String(), line)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	content := "// Bans\r\n" +
+		"Jon [SteamID 76561197999957991] Banned:76561197989362395:1700000000 //Team killing\r\n" +
+		"\r\n" +
+		"invalid\r\n" +
+		"76561197960265730:0\r\n"
+
+	bans, err := Parse(strings.NewReader(content))
+	if !errors.Is(err, ErrInvalidBanLine) || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("got %v, expected an invalid ban on line 4", err)
+	}
+
+	if len(bans) != 2 || bans[0].Reason != "Team killing" || bans[1].SteamId != 76561197960265730 {
+		t.Errorf("got %+v", bans)
+	}
+
+	expected := "Jon [SteamID 76561197999957991] Banned:76561197989362395:1700000000 //Team killing\n76561197960265730:0\n"
+	if formatted := Format(bans); formatted != expected {
+		t.Errorf("got %q, expected %q", formatted, expected)
+	}
+}
+
+func TestRemoveExpired(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	bans := []Ban{
+		{SteamId: 76561197999957991, Expires: now.Add(-time.Second)},
+		{SteamId: 76561197989362395, Expires: now},
+		{SteamId: 76561197960265730, Expires: now.Add(time.Second)},
+		{SteamId: 76561197960265731},
+	}
+
+	active, expired := RemoveExpired(bans, now)
+	if !reflect.DeepEqual(active, bans[2:]) || !reflect.DeepEqual(expired, bans[:2]) {
+		t.Errorf("got active %+v, expired %+v", active, expired)
+	}
+}
@@ -0,0 +1,99 @@
+package bancfg
+
+// MergePolicy decides which ban is kept when multiple lists ban the same player.
+type MergePolicy int
+
+const (
+	// MergeLongest keeps the ban that expires last. Permanent bans take precedence.
+	MergeLongest MergePolicy = iota
+
+	// MergeShortest keeps the ban that expires first.
+	MergeShortest
+
+	// MergeFirst keeps the ban of the list passed first, so lists can be passed by priority.
+	MergeFirst
+)
+
+// Conflict describes a player banned by multiple lists with different bans.
+type Conflict struct {
+	Id string
+
+	// Bans contains the bans of the player in the order of the lists.
+	Bans []Ban
+
+	// Chosen is the ban kept according to the merge policy.
+	Chosen Ban
+}
+
+// Merge combines ban lists into a single list containing one ban per player. The order of the
+// first occurrence of each player is kept. Bans that are identical in all lists are not reported
+// as conflict.
+func Merge(policy MergePolicy, lists ...[]Ban) ([]Ban, []Conflict) {
+	var order []string
+	candidates := make(map[string][]Ban)
+
+	for _, list := range lists {
+		for _, ban := range list {
+			id := ban.Id()
+			if _, exists := candidates[id]; !exists {
+				order = append(order, id)
+			}
+			candidates[id] = append(candidates[id], ban)
+		}
+	}
+
+	merged := make([]Ban, 0, len(order))
+	var conflicts []Conflict
+
+	for _, id := range order {
+		bans := candidates[id]
+		chosen := bans[0]
+		conflicting := false
+
+		for _, ban := range bans[1:] {
+			if !ban.equal(chosen) {
+				conflicting = true
+			}
+			if policy.prefers(ban, chosen) {
+				chosen = ban
+			}
+		}
+
+		merged = append(merged, chosen)
+		if conflicting {
+			conflicts = append(conflicts, Conflict{
+				Id:     id,
+				Bans:   bans,
+				Chosen: chosen,
+			})
+		}
+	}
+
+	return merged, conflicts
+}
+
+// prefers returns whether the candidate replaces the current ban.
+func (p MergePolicy) prefers(candidate Ban, current Ban) bool {
+	switch p {
+	case MergeLongest:
+		if current.IsPermanent() {
+			return false
+		}
+		return candidate.IsPermanent() || candidate.Expires.After(current.Expires)
+	case MergeShortest:
+		if candidate.IsPermanent() {
+			return false
+		}
+		return current.IsPermanent() || candidate.Expires.Before(current.Expires)
+	default:
+		return false
+	}
+}
+
+func (b Ban) equal(other Ban) bool {
+	return b.AdminName == other.AdminName &&
+		b.AdminId == other.AdminId &&
+		b.Id() == other.Id() &&
+		b.Expires.Equal(other.Expires) &&
+		b.Reason == other.Reason
+}
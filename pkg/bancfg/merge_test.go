@@ -0,0 +1,48 @@
+package bancfg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	short := Ban{SteamId: 76561197999957991, Expires: now.Add(time.Hour), Reason: "short"}
+	long := Ban{SteamId: 76561197999957991, Expires: now.Add(24 * time.Hour), Reason: "long"}
+	permanent := Ban{SteamId: 76561197999957991, Reason: "permanent"}
+	other := Ban{SteamId: 76561197989362395, Expires: now}
+
+	tests := []struct {
+		name      string
+		policy    MergePolicy
+		lists     [][]Ban
+		expected  []Ban
+		conflicts int
+	}{
+		{name: "longest", policy: MergeLongest, lists: [][]Ban{{short, other}, {long}}, expected: []Ban{long, other}, conflicts: 1},
+		{name: "longest permanent", policy: MergeLongest, lists: [][]Ban{{long}, {permanent}, {short}}, expected: []Ban{permanent}, conflicts: 1},
+		{name: "shortest", policy: MergeShortest, lists: [][]Ban{{permanent}, {long}, {short}}, expected: []Ban{short}, conflicts: 1},
+		{name: "first", policy: MergeFirst, lists: [][]Ban{{long}, {short}}, expected: []Ban{long}, conflicts: 1},
+		{name: "identical", policy: MergeLongest, lists: [][]Ban{{other}, {other}}, expected: []Ban{other}},
+		{name: "order of first occurrence", policy: MergeFirst, lists: [][]Ban{{other}, {short, other}}, expected: []Ban{other, short}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, conflicts := Merge(test.policy, test.lists...)
+			if !reflect.DeepEqual(merged, test.expected) {
+				t.Errorf("got %+v, expected %+v", merged, test.expected)
+			}
+
+			if len(conflicts) != test.conflicts {
+				t.Fatalf("got conflicts %+v, expected %d", conflicts, test.conflicts)
+			}
+			for _, conflict := range conflicts {
+				if !reflect.DeepEqual(conflict.Chosen, merged[0]) || conflict.Id != merged[0].Id() {
+					t.Errorf("got conflict %+v, expected %+v to be chosen", conflict, merged[0])
+				}
+			}
+		})
+	}
+}
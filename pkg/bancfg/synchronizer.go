@@ -0,0 +1,218 @@
+package bancfg
+
+import (
+	"errors"
+	"fmt"
+	"squad-rcon-go/pkg/rcon"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Interval returns the remaining length of the ban at the time as accepted by AdminBan, e.g.
+// `3d`. Returns `0` for permanent bans and an empty string for expired bans.
+func (b Ban) Interval(at time.Time) string {
+	if b.IsPermanent() {
+		return "0"
+	}
+
+	if b.IsExpired(at) {
+		return ""
+	}
+
+	// Rounded up so the ban does not end early.
+	minutes := int64((b.Expires.Sub(at) + time.Minute - 1) / time.Minute)
+
+	switch {
+	case minutes%(24*60) == 0:
+		return fmt.Sprintf("%dd", minutes/(24*60))
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// Apply bans the player live using AdminBan, for the remaining length of the ban at the time.
+// Expired bans are skipped.
+func Apply(rcon rcon.Rcon, ban Ban, at time.Time) error {
+	interval := ban.Interval(at)
+	if interval == "" {
+		return nil
+	}
+
+	return squadrcon.AdminBan(rcon, ban.Id(), interval, ban.Reason)
+}
+
+// Server is a server taking part in ban synchronisation.
+type Server struct {
+	// Id is used in reasons and errors, e.g. the ServerId of the event bus.
+	Id string
+
+	Rcon rcon.Rcon
+
+	// EventBus of the server, on which bans issued on the server are received. Optional, bans
+	// of servers without event bus are not propagated, but bans of other servers are applied.
+	EventBus *squadrcon.EventBus
+}
+
+// Propagation describes a ban issued on one server being applied to another.
+type Propagation struct {
+	Time     time.Time
+	From     string
+	To       string
+	Id       string
+	Interval string
+
+	// Err is set if AdminBan failed.
+	Err error
+}
+
+const (
+	defaultPropagationReason = "Banned on %s"
+	defaultSuppressWindow    = time.Minute
+)
+
+type SynchronizerSettings struct {
+	// Reason is the ban reason used for propagated bans. It may contain `%s`, which is replaced
+	// with the ID of the server the ban was issued on. Defaults to "Banned on %s".
+	Reason string
+
+	// SuppressWindow is the period after propagating a ban during which bans of the same player
+	// are not propagated again. This prevents the confirmations of propagated bans from being
+	// propagated back. Defaults to 1 minute.
+	SuppressWindow time.Duration
+
+	// OnPropagation is called for every ban applied to another server. Optional.
+	OnPropagation func(propagation Propagation)
+}
+
+// Synchronizer propagates bans issued on one server to all other servers.
+type Synchronizer struct {
+	servers       []Server
+	settings      SynchronizerSettings
+	subscriptions []*squadrcon.Subscription[squadrcon.Event]
+
+	// Lock to be used before accessing propagated.
+	lock sync.Mutex
+
+	// The last time a ban of each player was propagated, keyed by player ID.
+	propagated map[string]time.Time
+}
+
+// NewSynchronizer creates a Synchronizer that propagates bans until Close is called.
+func NewSynchronizer(servers []Server, settings SynchronizerSettings) *Synchronizer {
+	if settings.Reason == "" {
+		settings.Reason = defaultPropagationReason
+	}
+
+	if settings.SuppressWindow <= 0 {
+		settings.SuppressWindow = defaultSuppressWindow
+	}
+
+	s := &Synchronizer{
+		servers:    servers,
+		settings:   settings,
+		propagated: make(map[string]time.Time),
+	}
+
+	for _, server := range servers {
+		if server.EventBus == nil {
+			continue
+		}
+
+		from := server.Id
+		s.subscriptions = append(s.subscriptions, server.EventBus.SubscribeFunc(squadrcon.SubscribeOptions{
+			Topics: []squadrcon.Topic{squadrcon.TopicNotification},
+		}, func(event squadrcon.Event) {
+			if message, ok := event.Payload.(squadrcon.PlayerBannedMessage); ok {
+				s.HandleBan(from, message)
+			}
+		}))
+	}
+
+	return s
+}
+
+// HandleBan propagates a ban issued on a server to all other servers.
+func (s *Synchronizer) HandleBan(from string, message squadrcon.PlayerBannedMessage) {
+	id := message.SteamId.String()
+	if message.SteamId == 0 {
+		id = message.EosId.String()
+	}
+
+	now := time.Now()
+	if !s.claim(id, now) {
+		return
+	}
+
+	reason := s.settings.Reason
+	if strings.Contains(reason, "%s") {
+		reason = fmt.Sprintf(reason, from)
+	}
+
+	for _, server := range s.servers {
+		if server.Id == from {
+			continue
+		}
+
+		err := squadrcon.AdminBan(server.Rcon, id, message.Interval, reason)
+
+		if s.settings.OnPropagation != nil {
+			s.settings.OnPropagation(Propagation{
+				Time:     now,
+				From:     from,
+				To:       server.Id,
+				Id:       id,
+				Interval: message.Interval,
+				Err:      err,
+			})
+		}
+	}
+}
+
+// ApplyAll applies the bans to all servers. Returns the errors of all failed bans.
+func (s *Synchronizer) ApplyAll(bans []Ban, at time.Time) error {
+	var errs []error
+
+	for _, ban := range bans {
+		// Suppresses the confirmations of these bans from being propagated.
+		s.claim(ban.Id(), time.Now())
+
+		for _, server := range s.servers {
+			if err := Apply(server.Rcon, ban, at); err != nil {
+				errs = append(errs, fmt.Errorf("failed to ban %s on %s: %w", ban.Id(), server.Id, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close stops propagating bans.
+func (s *Synchronizer) Close() {
+	for _, subscription := range s.subscriptions {
+		subscription.Unsubscribe()
+	}
+}
+
+// claim records the propagation of a ban of the player. Returns false if a ban of the player was
+// propagated within the suppress window.
+func (s *Synchronizer) claim(id string, at time.Time) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if last, exists := s.propagated[id]; exists && at.Sub(last) < s.settings.SuppressWindow {
+		return false
+	}
+
+	for key, last := range s.propagated {
+		if at.Sub(last) >= s.settings.SuppressWindow {
+			delete(s.propagated, key)
+		}
+	}
+
+	s.propagated[id] = at
+	return true
+}
@@ -0,0 +1,60 @@
+// Package cfgfile reads and writes the lines of the plain text config files of a Squad server, such
+// as Admins.cfg and Server.cfg, so that files are written with the line ending they were read with.
+package cfgfile
+
+import (
+	"io"
+	"strings"
+)
+
+// Line endings.
+const (
+	LF   = "\n"
+	CRLF = "\r\n"
+)
+
+// ReadLines returns the lines of the reader without line endings, and the line ending used by the
+// majority of the lines, LF if the lines are split evenly. The last line may be unterminated.
+func ReadLines(reader io.Reader) ([]string, string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", err
+	}
+
+	content := string(data)
+	lineEnding := DetectLineEnding(content)
+	if content == "" {
+		return nil, lineEnding, nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, LF), LF)
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	return lines, lineEnding, nil
+}
+
+// DetectLineEnding returns the line ending used by the majority of the lines of content, LF if the
+// lines are split evenly.
+func DetectLineEnding(content string) string {
+	crlfCount := strings.Count(content, CRLF)
+	if lfCount := strings.Count(content, LF) - crlfCount; crlfCount > lfCount {
+		return CRLF
+	}
+	return LF
+}
+
+// JoinLines terminates every line with the line ending, LF if empty.
+func JoinLines(lines []string, lineEnding string) string {
+	if lineEnding == "" {
+		lineEnding = LF
+	}
+
+	var builder strings.Builder
+	for _, line := range lines {
+		builder.WriteString(line)
+		builder.WriteString(lineEnding)
+	}
+	return builder.String()
+}
@@ -0,0 +1,57 @@
+package cfgfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadLines(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		lines      []string
+		lineEnding string
+	}{
+		{name: "empty", content: "", lineEnding: LF},
+		{name: "LF", content: "a\nb\n", lines: []string{"a", "b"}, lineEnding: LF},
+		{name: "CRLF", content: "a\r\nb\r\n", lines: []string{"a", "b"}, lineEnding: CRLF},
+		{name: "unterminated last line", content: "a\r\nb", lines: []string{"a", "b"}, lineEnding: CRLF},
+		{name: "empty lines", content: "\r\na\r\n\r\n", lines: []string{"", "a", ""}, lineEnding: CRLF},
+		{name: "mostly CRLF", content: "a\nb\r\nc\r\n", lines: []string{"a", "b", "c"}, lineEnding: CRLF},
+		{name: "mostly LF", content: "a\r\nb\nc\n", lines: []string{"a", "b", "c"}, lineEnding: LF},
+		{name: "evenly split", content: "a\r\nb\n", lines: []string{"a", "b"}, lineEnding: LF},
+		{name: "carriage return within line", content: "a\rb\n", lines: []string{"a\rb"}, lineEnding: LF},
+	}
+
+	for _, test := range tests {
+		lines, lineEnding, err := ReadLines(strings.NewReader(test.content))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(lines, test.lines) || lineEnding != test.lineEnding {
+			t.Errorf("%s: got %q, %q, expected %q, %q", test.name, lines, lineEnding, test.lines, test.lineEnding)
+		}
+	}
+}
+
+func TestJoinLines(t *testing.T) {
+	tests := []struct {
+		lines      []string
+		lineEnding string
+		expected   string
+	}{
+		{nil, CRLF, ""},
+		{[]string{"a", ""}, "", "a\n\n"},
+		{[]string{"a", "b"}, LF, "a\nb\n"},
+		{[]string{"a", "b"}, CRLF, "a\r\nb\r\n"},
+	}
+
+	for _, test := range tests {
+		if joined := JoinLines(test.lines, test.lineEnding); joined != test.expected {
+			t.Errorf("JoinLines(%q, %q) = %q, expected %q", test.lines, test.lineEnding, joined, test.expected)
+		}
+	}
+}
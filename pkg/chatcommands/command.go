@@ -0,0 +1,157 @@
+package chatcommands
+
+import (
+	"errors"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrUsage can be returned by handlers to reply with the usage of the command.
+	ErrUsage = errors.New("invalid command usage")
+)
+
+// Permission is required to use a command. The meaning of a permission is up to the RoleProvider.
+type Permission string
+
+// RoleProvider decides which players hold which permissions.
+type RoleProvider interface {
+	HasPermission(steamId squadrcon.SteamID64, eosId squadrcon.EOSID, permission Permission) bool
+}
+
+// RoleProviderFunc adapts a function to a RoleProvider.
+type RoleProviderFunc func(steamId squadrcon.SteamID64, eosId squadrcon.EOSID, permission Permission) bool
+
+func (f RoleProviderFunc) HasPermission(steamId squadrcon.SteamID64, eosId squadrcon.EOSID, permission Permission) bool {
+	return f(steamId, eosId, permission)
+}
+
+// Command is a chat command such as `!rtv`.
+type Command struct {
+	// Name is used to invoke the command, without prefix. Names are case-insensitive.
+	Name string
+
+	// Aliases are alternative names.
+	Aliases []string
+
+	// Usage describes the arguments, e.g. `<player> <reason>`. Shown when the command is misused.
+	Usage string
+
+	Description string
+
+	// Permission is required to use the command. Everyone can use the command if empty.
+	Permission Permission
+
+	// Channels the command can be used in. The command can be used in all channels if empty.
+	Channels []squadrcon.ChatChannel
+
+	// MinArguments is the amount of arguments required, fewer arguments result in a usage reply.
+	MinArguments int
+
+	// Cooldown is the time after each use during which the command cannot be used by anyone.
+	Cooldown time.Duration
+
+	// PlayerCooldown is the time after each use during which the command cannot be used by the
+	// same player.
+	PlayerCooldown time.Duration
+
+	Handler func(context *Context) error
+}
+
+func (c *Command) allowsChannel(channel squadrcon.ChatChannel) bool {
+	if len(c.Channels) == 0 {
+		return true
+	}
+
+	for _, allowed := range c.Channels {
+		if allowed == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Context is passed to command handlers.
+type Context struct {
+	router *Router
+
+	// Message is the chat message that invoked the command.
+	Message squadrcon.ChatMessage
+
+	Command *Command
+
+	// Invocation is the name or alias used to invoke the command, in lowercase.
+	Invocation string
+
+	// Arguments are the words after the command name. Words can be grouped using double quotes.
+	Arguments []string
+}
+
+// Argument returns the argument at index, or an empty string if there is no such argument.
+func (c *Context) Argument(index int) string {
+	if index < 0 || index >= len(c.Arguments) {
+		return ""
+	}
+
+	return c.Arguments[index]
+}
+
+// Rest returns the arguments from index on, joined by spaces. Useful for trailing free text such
+// as reasons.
+func (c *Context) Rest(index int) string {
+	if index < 0 || index >= len(c.Arguments) {
+		return ""
+	}
+
+	return strings.Join(c.Arguments[index:], " ")
+}
+
+// Reply sends a warning with the message to the player that invoked the command.
+func (c *Context) Reply(message string) error {
+	return c.router.reply(c.Message, message)
+}
+
+// Broadcast shows the message to all players.
+func (c *Context) Broadcast(message string) error {
+	return c.router.broadcast(message)
+}
+
+// HasPermission returns whether the player that invoked the command holds the permission.
+func (c *Context) HasPermission(permission Permission) bool {
+	return c.router.hasPermission(c.Message, permission)
+}
+
+// parseArguments splits text into words. Double quotes group words, e.g. `"Some Name" reason`
+// results in `Some Name` and `reason`. An unterminated quote extends to the end of the text.
+func parseArguments(text string) []string {
+	var arguments []string
+	var current strings.Builder
+	inQuotes := false
+	inWord := false
+
+	for _, r := range text {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			inWord = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if inWord {
+				arguments = append(arguments, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		arguments = append(arguments, current.String())
+	}
+
+	return arguments
+}
@@ -0,0 +1,294 @@
+package chatcommands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"squad-rcon-go/pkg/rcon"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDuplicateCommand = errors.New("command name already registered")
+	ErrInvalidCommand   = errors.New("command requires a name and handler")
+	ErrUnknownSender    = errors.New("chat message has no Steam ID or EOS ID of the sender")
+)
+
+const (
+	defaultPrefix = "!"
+)
+
+type RouterSettings struct {
+	// Prefix that messages must start with to be handled as command. Defaults to `!`.
+	Prefix string
+
+	// Roles decides who holds the permissions of commands. Optional, commands requiring a
+	// permission cannot be used if nil.
+	Roles RoleProvider
+
+	// ReplyUnknown replies to messages that start with the prefix but do not match a command.
+	ReplyUnknown bool
+
+	// EventBus is used to receive chat messages. Optional, messages can be passed to
+	// HandleMessage instead.
+	EventBus *squadrcon.EventBus
+
+	// OnError is called when a handler or reply fails. Optional.
+	OnError func(err error)
+}
+
+// broadcaster is implemented by squadrcon.SquadRcon, whose queued broadcasts are preferred over
+// AdminBroadcast.
+type broadcaster interface {
+	Broadcast(message string, priority squadrcon.BroadcastPriority) bool
+}
+
+// Router dispatches chat messages to the registered commands.
+type Router struct {
+	rcon         rcon.Rcon
+	settings     RouterSettings
+	subscription *squadrcon.Subscription[squadrcon.Event]
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// Commands keyed by lowercase name and aliases.
+	commands map[string]*Command
+
+	// The last use of each command.
+	lastUse map[*Command]time.Time
+
+	// The last use of each command by each player, keyed by command and
+	// squadrcon.ChatMessage.SenderKey.
+	lastPlayerUse map[*Command]map[string]time.Time
+}
+
+// NewRouter creates a Router. If an event bus is configured, chat messages are received from it
+// until Close is called.
+func NewRouter(rcon rcon.Rcon, settings RouterSettings) *Router {
+	if settings.Prefix == "" {
+		settings.Prefix = defaultPrefix
+	}
+
+	r := &Router{
+		rcon:          rcon,
+		settings:      settings,
+		commands:      make(map[string]*Command),
+		lastUse:       make(map[*Command]time.Time),
+		lastPlayerUse: make(map[*Command]map[string]time.Time),
+	}
+
+	if settings.EventBus != nil {
+		r.subscription = settings.EventBus.SubscribeFunc(squadrcon.SubscribeOptions{
+			Topics: []squadrcon.Topic{squadrcon.TopicChat},
+		}, func(event squadrcon.Event) {
+			if message, ok := event.Payload.(squadrcon.ChatMessage); ok {
+				r.HandleMessage(message)
+			}
+		})
+	}
+
+	return r
+}
+
+// Register adds a command. Returns ErrDuplicateCommand if its name or one of its aliases is
+// already in use.
+func (r *Router) Register(command Command) error {
+	if command.Name == "" || command.Handler == nil {
+		return ErrInvalidCommand
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	names := append([]string{command.Name}, command.Aliases...)
+	for _, name := range names {
+		if _, exists := r.commands[strings.ToLower(name)]; exists {
+			return fmt.Errorf("%w: %s", ErrDuplicateCommand, name)
+		}
+	}
+
+	for _, name := range names {
+		r.commands[strings.ToLower(name)] = &command
+	}
+
+	return nil
+}
+
+// Commands returns the registered commands ordered by name.
+func (r *Router) Commands() []Command {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	seen := make(map[*Command]struct{})
+	var commands []Command
+	for _, command := range r.commands {
+		if _, exists := seen[command]; !exists {
+			seen[command] = struct{}{}
+			commands = append(commands, *command)
+		}
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+
+	return commands
+}
+
+// HandleMessage runs the command of a chat message. Returns false if the message is not a
+// command.
+func (r *Router) HandleMessage(message squadrcon.ChatMessage) bool {
+	text := strings.TrimSpace(message.Message)
+	if !strings.HasPrefix(text, r.settings.Prefix) {
+		return false
+	}
+
+	arguments := parseArguments(strings.TrimPrefix(text, r.settings.Prefix))
+	if len(arguments) == 0 {
+		return false
+	}
+
+	// Replies and cooldowns require the ID of the sender.
+	if message.SenderKey() == "" {
+		r.reportError(fmt.Errorf("%w: %s", ErrUnknownSender, message.PlayerName))
+		return true
+	}
+
+	invocation := strings.ToLower(arguments[0])
+
+	r.lock.Lock()
+	command, exists := r.commands[invocation]
+	r.lock.Unlock()
+
+	if !exists {
+		if r.settings.ReplyUnknown {
+			r.replyOrReport(message, fmt.Sprintf("Unknown command %s%s", r.settings.Prefix, invocation))
+		}
+		return true
+	}
+
+	// Commands are silently ignored in other channels so that e.g. admin commands are not
+	// revealed in all chat.
+	if !command.allowsChannel(message.Channel) {
+		return true
+	}
+
+	if command.Permission != "" && !r.hasPermission(message, command.Permission) {
+		r.replyOrReport(message, fmt.Sprintf("You are not allowed to use %s%s", r.settings.Prefix, invocation))
+		return true
+	}
+
+	context := &Context{
+		router:     r,
+		Message:    message,
+		Command:    command,
+		Invocation: invocation,
+		Arguments:  arguments[1:],
+	}
+
+	if len(context.Arguments) < command.MinArguments {
+		r.replyOrReport(message, r.usage(context))
+		return true
+	}
+
+	if remaining := r.claimCooldown(command, message, time.Now()); remaining > 0 {
+		r.replyOrReport(message, fmt.Sprintf(
+			"%s%s can be used again in %s",
+			r.settings.Prefix,
+			invocation,
+			remaining.Round(time.Second),
+		))
+		return true
+	}
+
+	if err := command.Handler(context); err != nil {
+		if errors.Is(err, ErrUsage) {
+			r.replyOrReport(message, r.usage(context))
+		} else {
+			r.reportError(fmt.Errorf("command %s failed: %w", command.Name, err))
+		}
+	}
+
+	return true
+}
+
+// Close stops receiving messages from the event bus.
+func (r *Router) Close() {
+	if r.subscription != nil {
+		r.subscription.Unsubscribe()
+	}
+}
+
+// claimCooldown records a use of the command. Returns the remaining cooldown instead if the
+// command cannot be used yet.
+func (r *Router) claimCooldown(command *Command, message squadrcon.ChatMessage, now time.Time) time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := message.SenderKey()
+
+	var remaining time.Duration
+	if last, used := r.lastUse[command]; used {
+		remaining = max(remaining, command.Cooldown-now.Sub(last))
+	}
+	if last, used := r.lastPlayerUse[command][key]; used {
+		remaining = max(remaining, command.PlayerCooldown-now.Sub(last))
+	}
+
+	if remaining > 0 {
+		return remaining
+	}
+
+	r.lastUse[command] = now
+	if r.lastPlayerUse[command] == nil {
+		r.lastPlayerUse[command] = make(map[string]time.Time)
+	}
+	r.lastPlayerUse[command][key] = now
+
+	return 0
+}
+
+func (r *Router) usage(context *Context) string {
+	return strings.TrimSpace(fmt.Sprintf("Usage: %s%s %s", r.settings.Prefix, context.Invocation, context.Command.Usage))
+}
+
+func (r *Router) hasPermission(message squadrcon.ChatMessage, permission Permission) bool {
+	if permission == "" {
+		return true
+	}
+
+	if r.settings.Roles == nil {
+		return false
+	}
+
+	return r.settings.Roles.HasPermission(message.SteamId, message.EosId, permission)
+}
+
+func (r *Router) reply(message squadrcon.ChatMessage, reply string) error {
+	return squadrcon.AdminWarn(r.rcon, message.SenderKey(), reply)
+}
+
+func (r *Router) broadcast(message string) error {
+	if broadcaster, ok := r.rcon.(broadcaster); ok {
+		broadcaster.Broadcast(message, squadrcon.BroadcastPriorityNormal)
+		return nil
+	}
+
+	return squadrcon.AdminBroadcast(r.rcon, message)
+}
+
+func (r *Router) replyOrReport(message squadrcon.ChatMessage, reply string) {
+	if err := r.reply(message, reply); err != nil {
+		r.reportError(err)
+	}
+}
+
+func (r *Router) reportError(err error) {
+	if r.settings.OnError != nil {
+		r.settings.OnError(err)
+	}
+}
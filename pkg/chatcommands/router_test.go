@@ -0,0 +1,276 @@
+package chatcommands
+
+import (
+	"errors"
+	"reflect"
+	"squad-rcon-go/pkg/squadrcon"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRcon records the executed commands.
+type fakeRcon struct {
+	lock     sync.Mutex
+	commands []string
+}
+
+func (r *fakeRcon) Execute(command string) (string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.commands = append(r.commands, command)
+	return "", nil
+}
+
+func (r *fakeRcon) Close() error {
+	return nil
+}
+
+// Commands returns the executed commands and forgets them.
+func (r *fakeRcon) Commands() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	commands := r.commands
+	r.commands = nil
+	return commands
+}
+
+const (
+	adminSteamId  = squadrcon.SteamID64(76561197999957991)
+	playerSteamId = squadrcon.SteamID64(76561197989362395)
+	playerEosId   = squadrcon.EOSID("0002a10386f3487ba4b12b5e5f6a6a1a")
+)
+
+func chat(channel squadrcon.ChatChannel, steamId squadrcon.SteamID64, text string) squadrcon.ChatMessage {
+	return squadrcon.ChatMessage{Channel: channel, SteamId: steamId, PlayerName: "player", Message: text}
+}
+
+func newTestRouter(rcon *fakeRcon, errs *[]error) *Router {
+	return NewRouter(rcon, RouterSettings{
+		Roles: RoleProviderFunc(func(steamId squadrcon.SteamID64, eosId squadrcon.EOSID, permission Permission) bool {
+			return steamId == adminSteamId && permission == "admin"
+		}),
+		ReplyUnknown: true,
+		OnError: func(err error) {
+			*errs = append(*errs, err)
+		},
+	})
+}
+
+func TestRouterDispatch(t *testing.T) {
+	rcon := &fakeRcon{}
+	var errs []error
+	router := newTestRouter(rcon, &errs)
+
+	var contexts []*Context
+	err := router.Register(Command{
+		Name:         "kick",
+		Aliases:      []string{"k"},
+		Usage:        "<player> <reason>",
+		Permission:   "admin",
+		Channels:     []squadrcon.ChatChannel{squadrcon.ChatAdmin},
+		MinArguments: 1,
+		Handler: func(context *Context) error {
+			contexts = append(contexts, context)
+			return context.Reply("Kicked " + context.Argument(0))
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not register: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		message  squadrcon.ChatMessage
+		handled  bool
+		commands []string
+	}{
+		{
+			name:    "not a command",
+			message: chat(squadrcon.ChatAdmin, adminSteamId, "kick Jon"),
+		},
+		{
+			name:     "alias and quoted argument",
+			message:  chat(squadrcon.ChatAdmin, adminSteamId, `!K "Some Name" teamkilling`),
+			handled:  true,
+			commands: []string{"AdminWarn 76561197999957991 Kicked Some Name"},
+		},
+		{
+			name:    "other channel",
+			message: chat(squadrcon.ChatAll, adminSteamId, "!kick Jon"),
+			handled: true,
+		},
+		{
+			name:     "missing permission",
+			message:  chat(squadrcon.ChatAdmin, playerSteamId, "!kick Jon"),
+			handled:  true,
+			commands: []string{"AdminWarn 76561197989362395 You are not allowed to use !kick"},
+		},
+		{
+			name:     "missing arguments",
+			message:  chat(squadrcon.ChatAdmin, adminSteamId, "!kick"),
+			handled:  true,
+			commands: []string{"AdminWarn 76561197999957991 Usage: !kick <player> <reason>"},
+		},
+		{
+			name:     "unknown command",
+			message:  chat(squadrcon.ChatAll, playerSteamId, "!rtv"),
+			handled:  true,
+			commands: []string{"AdminWarn 76561197989362395 Unknown command !rtv"},
+		},
+		{
+			name:     "EOS ID only",
+			message:  squadrcon.ChatMessage{Channel: squadrcon.ChatAll, EosId: playerEosId, Message: "!rtv"},
+			handled:  true,
+			commands: []string{"AdminWarn 0002a10386f3487ba4b12b5e5f6a6a1a Unknown command !rtv"},
+		},
+	}
+
+	for _, test := range tests {
+		if handled := router.HandleMessage(test.message); handled != test.handled {
+			t.Errorf("%s: HandleMessage() = %v, expected %v", test.name, handled, test.handled)
+		}
+		if commands := rcon.Commands(); !reflect.DeepEqual(commands, test.commands) {
+			t.Errorf("%s: got %q, expected %q", test.name, commands, test.commands)
+		}
+	}
+
+	if len(contexts) != 1 || contexts[0].Invocation != "k" || !reflect.DeepEqual(contexts[0].Arguments, []string{"Some Name", "teamkilling"}) {
+		t.Errorf("got %+v, expected the handler to be called once", contexts)
+	}
+	if len(errs) != 0 {
+		t.Errorf("got errors %v", errs)
+	}
+}
+
+func TestRouterCooldowns(t *testing.T) {
+	rcon := &fakeRcon{}
+	var errs []error
+	router := newTestRouter(rcon, &errs)
+
+	uses := 0
+	_ = router.Register(Command{
+		Name:           "global",
+		Cooldown:       time.Hour,
+		PlayerCooldown: time.Minute,
+		Handler:        func(context *Context) error { uses++; return nil },
+	})
+	_ = router.Register(Command{
+		Name:           "player",
+		PlayerCooldown: time.Hour,
+		Handler:        func(context *Context) error { uses++; return nil },
+	})
+
+	router.HandleMessage(chat(squadrcon.ChatAll, adminSteamId, "!global"))
+	router.HandleMessage(chat(squadrcon.ChatAll, playerSteamId, "!global"))
+	if uses != 1 {
+		t.Errorf("got %d uses, expected the cooldown to apply to all players", uses)
+	}
+	if commands := rcon.Commands(); len(commands) != 1 || commands[0] != "AdminWarn 76561197989362395 !global can be used again in 1h0m0s" {
+		t.Errorf("got %q, expected a cooldown reply", commands)
+	}
+
+	router.HandleMessage(chat(squadrcon.ChatAll, adminSteamId, "!player"))
+	router.HandleMessage(chat(squadrcon.ChatAll, adminSteamId, "!player"))
+	router.HandleMessage(chat(squadrcon.ChatAll, playerSteamId, "!player"))
+	if uses != 3 {
+		t.Errorf("got %d uses, expected the player cooldown to apply per player", uses)
+	}
+}
+
+func TestRouterUnknownSender(t *testing.T) {
+	rcon := &fakeRcon{}
+	var errs []error
+	router := newTestRouter(rcon, &errs)
+
+	called := false
+	_ = router.Register(Command{Name: "rtv", Handler: func(context *Context) error { called = true; return nil }})
+
+	if !router.HandleMessage(squadrcon.ChatMessage{Channel: squadrcon.ChatAll, PlayerName: "nobody", Message: "!rtv"}) {
+		t.Error("expected the message to be handled")
+	}
+	if called {
+		t.Error("expected the handler not to be called")
+	}
+	if commands := rcon.Commands(); len(commands) != 0 {
+		t.Errorf("got %q, expected no commands", commands)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrUnknownSender) {
+		t.Errorf("got %v, expected %v", errs, ErrUnknownSender)
+	}
+}
+
+func TestRouterHandlerErrors(t *testing.T) {
+	rcon := &fakeRcon{}
+	var errs []error
+	router := newTestRouter(rcon, &errs)
+
+	failure := errors.New("failure")
+	_ = router.Register(Command{Name: "usage", Usage: "<map>", Handler: func(context *Context) error { return ErrUsage }})
+	_ = router.Register(Command{Name: "fail", Handler: func(context *Context) error { return failure }})
+
+	router.HandleMessage(chat(squadrcon.ChatAll, playerSteamId, "!usage"))
+	if commands := rcon.Commands(); len(commands) != 1 || commands[0] != "AdminWarn 76561197989362395 Usage: !usage <map>" {
+		t.Errorf("got %q, expected a usage reply", commands)
+	}
+
+	router.HandleMessage(chat(squadrcon.ChatAll, playerSteamId, "!fail"))
+	if len(errs) != 1 || !errors.Is(errs[0], failure) {
+		t.Errorf("got %v, expected the handler error to be reported", errs)
+	}
+}
+
+func TestRouterRegister(t *testing.T) {
+	router := NewRouter(&fakeRcon{}, RouterSettings{})
+	handler := func(context *Context) error { return nil }
+
+	if err := router.Register(Command{Name: "rtv", Aliases: []string{"vote"}, Handler: handler}); err != nil {
+		t.Fatalf("could not register: %v", err)
+	}
+
+	tests := []struct {
+		command Command
+		err     error
+	}{
+		{Command{Name: "RTV", Handler: handler}, ErrDuplicateCommand},
+		{Command{Name: "map", Aliases: []string{"Vote"}, Handler: handler}, ErrDuplicateCommand},
+		{Command{Name: "map"}, ErrInvalidCommand},
+		{Command{Handler: handler}, ErrInvalidCommand},
+		{Command{Name: "map", Handler: handler}, nil},
+	}
+
+	for _, test := range tests {
+		if err := router.Register(test.command); !errors.Is(err, test.err) {
+			t.Errorf("Register(%+v) = %v, expected %v", test.command, err, test.err)
+		}
+	}
+
+	var names []string
+	for _, command := range router.Commands() {
+		names = append(names, command.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"map", "rtv"}) {
+		t.Errorf("got %v, expected map and rtv", names)
+	}
+}
+
+func TestParseArguments(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"", nil},
+		{"  kick  Jon  ", []string{"kick", "Jon"}},
+		{`kick "✯RAIDR✯ Jon" team killing`, []string{"kick", "✯RAIDR✯ Jon", "team", "killing"}},
+		{`kick ""`, []string{"kick", ""}},
+		{`kick "unterminated name`, []string{"kick", "unterminated name"}},
+	}
+
+	for _, test := range tests {
+		if arguments := parseArguments(test.text); !reflect.DeepEqual(arguments, test.expected) {
+			t.Errorf("parseArguments(%q) = %q, expected %q", test.text, arguments, test.expected)
+		}
+	}
+}
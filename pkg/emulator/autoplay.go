@@ -0,0 +1,186 @@
+package emulator
+
+import (
+	"fmt"
+	"math/rand"
+	"squad-rcon-go/pkg/squadrcon"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAutoplayInterval    = 2 * time.Second
+	defaultAutoplayPlayers     = 40
+	defaultAutoplayMatchLength = 30 * time.Minute
+
+	// Account numbers of generated players start here, so they do not collide with real ones.
+	autoplaySteamIdBase squadrcon.SteamID64 = 76561198900000000
+)
+
+var (
+	autoplayNames = []string{
+		"Jon", "creaman", "Kovalenko", "Smithy", "Dutch", "Ghost", "Medic4Life", "Baguette",
+		"Nomad", "Viper", "Tank", "Bravo", "Schnitzel", "Maple", "Kiwi", "Raven",
+	}
+	autoplayTags    = []string{"", "", "✯RAIDR✯", "[TWS] ", "[BB] ", "=ANZ= "}
+	autoplaySquads  = []string{"ALPHA", "BRAVO", "CHARLIE", "INF", "ARMOR", "LOGI", "MORTARS", "HELI"}
+	autoplayChat    = []string{"need ammo", "rally is down", "who has logi?", "gg", "push A", "o7", "medic!"}
+	autoplayChannel = []squadrcon.ChatChannel{squadrcon.ChatAll, squadrcon.ChatAll, squadrcon.ChatTeam, squadrcon.ChatSquad}
+)
+
+type AutoplaySettings struct {
+	// Interval is the time between simulated actions. Defaults to 2 seconds.
+	Interval time.Duration
+
+	// Players is the number of players the server fills up to. Players keep joining and leaving
+	// around this number. Defaults to 40.
+	Players int
+
+	// MatchLength is the time after which the match ends and the next layer is played. Defaults
+	// to 30 minutes.
+	MatchLength time.Duration
+
+	// Seed makes the simulation reproducible. A random seed is used if zero.
+	Seed int64
+
+	// OnError is called when a simulated action fails. Optional.
+	OnError func(err error)
+}
+
+// Autoplay simulates players on a Simulation: players join and leave, create and join squads,
+// switch kits and chat, and matches end after the configured length.
+type Autoplay struct {
+	simulation *Simulation
+	settings   AutoplaySettings
+	random     *rand.Rand
+
+	// Number of players generated so far, used for unique Steam IDs.
+	generated int
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewAutoplay starts simulating players. Call Close to stop.
+func NewAutoplay(simulation *Simulation, settings AutoplaySettings) *Autoplay {
+	if settings.Interval <= 0 {
+		settings.Interval = defaultAutoplayInterval
+	}
+
+	if settings.Players <= 0 {
+		settings.Players = defaultAutoplayPlayers
+	}
+
+	if settings.MatchLength <= 0 {
+		settings.MatchLength = defaultAutoplayMatchLength
+	}
+
+	if settings.Seed == 0 {
+		settings.Seed = time.Now().UnixNano()
+	}
+
+	a := &Autoplay{
+		simulation: simulation,
+		settings:   settings,
+		random:     rand.New(rand.NewSource(settings.Seed)),
+		done:       make(chan struct{}),
+	}
+
+	go a.run()
+
+	return a
+}
+
+// Close stops the simulation. Players stay connected.
+func (a *Autoplay) Close() {
+	a.stopOnce.Do(func() {
+		close(a.done)
+	})
+}
+
+func (a *Autoplay) run() {
+	ticker := time.NewTicker(a.settings.Interval)
+	defer ticker.Stop()
+
+	matchEnd := time.NewTimer(a.settings.MatchLength)
+	defer matchEnd.Stop()
+
+	for {
+		select {
+		case <-a.done:
+			return
+		case <-matchEnd.C:
+			a.simulation.EndMatch()
+			matchEnd.Reset(a.settings.MatchLength)
+		case <-ticker.C:
+			if err := a.step(); err != nil && a.settings.OnError != nil {
+				a.settings.OnError(err)
+			}
+		}
+	}
+}
+
+// step performs a random action. Joining is more likely while the server has fewer players than
+// configured, leaving while it has more.
+func (a *Autoplay) step() error {
+	players := a.simulation.Players().ActivePlayers
+	fill := float64(len(players)) / float64(a.settings.Players)
+
+	roll := a.random.Float64()
+	switch {
+	case len(players) == 0 || roll < 0.3*(1-fill)+0.05:
+		return a.join()
+	case roll < 0.4:
+		return a.simulation.Leave(selector(a.pick(players)))
+	case roll < 0.55:
+		player := a.pick(players)
+		_, err := a.simulation.CreateSquad(selector(player), autoplaySquads[a.random.Intn(len(autoplaySquads))])
+		return err
+	case roll < 0.75:
+		return a.joinSquad(a.pick(players))
+	case roll < 0.85:
+		player := a.pick(players)
+		if player.IsSquadLead {
+			return nil
+		}
+		faction := a.simulation.Layer().Factions[player.TeamIndex-1]
+		return a.simulation.SetKit(selector(player), kit(faction, kitRoles[a.random.Intn(len(kitRoles))]))
+	default:
+		player := a.pick(players)
+		channel := autoplayChannel[a.random.Intn(len(autoplayChannel))]
+		return a.simulation.Chat(selector(player), channel, autoplayChat[a.random.Intn(len(autoplayChat))])
+	}
+}
+
+func (a *Autoplay) join() error {
+	a.generated++
+	name := autoplayTags[a.random.Intn(len(autoplayTags))] + autoplayNames[a.random.Intn(len(autoplayNames))] +
+		fmt.Sprint(a.generated)
+
+	_, err := a.simulation.Join(name, autoplaySteamIdBase+squadrcon.SteamID64(a.generated), 0)
+	return err
+}
+
+// joinSquad moves the player into a random unlocked squad of their team.
+func (a *Autoplay) joinSquad(player squadrcon.ActivePlayer) error {
+	var candidates []squadrcon.Squad
+	for _, squad := range a.simulation.Squads().Squads {
+		if squad.TeamIndex == player.TeamIndex && squad.Id != player.SquadIndex && !squad.Locked {
+			candidates = append(candidates, squad)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	return a.simulation.JoinSquad(selector(player), candidates[a.random.Intn(len(candidates))].Id)
+}
+
+func (a *Autoplay) pick(players []squadrcon.ActivePlayer) squadrcon.ActivePlayer {
+	return players[a.random.Intn(len(players))]
+}
+
+// selector returns a selector that matches exactly the player.
+func selector(player squadrcon.ActivePlayer) string {
+	return player.SteamId.String()
+}
@@ -0,0 +1,374 @@
+package emulator
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"squad-rcon-go/pkg/rcon"
+	"squad-rcon-go/pkg/squadrcon"
+	"strconv"
+	"strings"
+)
+
+// command is an RCON command of the emulated server.
+type command struct {
+	name string
+
+	// arguments as listed by ListCommands, e.g. `<NameOrSteamId> <KickReason>`.
+	arguments string
+
+	help string
+
+	// execute returns the response. The simulation is locked while it is called, messages to push
+	// are returned separately so they can be pushed after unlocking.
+	execute func(s *Simulation, arguments string) (response string, messages []string)
+}
+
+// commands is initialized in init, since ListCommands refers to it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"ListCommands", "[ShowDetails]", "Prints out the information for all commands in the game.", (*Simulation).listCommands},
+		{"ListPlayers", "", "List player ids with associated player name and SteamId", (*Simulation).listPlayers},
+		{"ListSquads", "", "List Squads by their Team ID", (*Simulation).listSquads},
+		{"ShowCurrentMap", "", "Show the current map", (*Simulation).showCurrentMap},
+		{"ShowNextMap", "", "Show the next map", (*Simulation).showNextMap},
+		{"ListLayers", "", "List available layers", (*Simulation).listLayers},
+		{"ListLevels", "", "List available levels", (*Simulation).listLevels},
+		{"ShowServerInfo", "", "Show the server info", (*Simulation).showServerInfo},
+		{"AdminBroadcast", "<Message>", "Send a message to all players", (*Simulation).adminBroadcast},
+		{"AdminWarn", "<NameOrSteamId> <WarnReason>", "Warns a player with a message", (*Simulation).adminWarn},
+		{"AdminWarnById", "<PlayerId> <WarnReason>", "Warns a player with a message", (*Simulation).adminWarn},
+		{"AdminKick", "<NameOrSteamId> <KickReason>", "Kicks a player from the server", (*Simulation).adminKick},
+		{"AdminKickById", "<PlayerId> <KickReason>", "Kicks a player from the server", (*Simulation).adminKick},
+		{"AdminBan", "<NameOrSteamId> <BanLength> <BanReason>", "Bans a player from the server for a length of time", (*Simulation).adminBan},
+		{"AdminBanById", "<PlayerId> <BanLength> <BanReason>", "Bans a player from the server for a length of time", (*Simulation).adminBan},
+		{"AdminForceTeamChange", "<NameOrSteamId>", "Changes a player's team", (*Simulation).adminForceTeamChange},
+		{"AdminForceTeamChangeById", "<PlayerId>", "Changes a player's team", (*Simulation).adminForceTeamChange},
+		{"AdminRemovePlayerFromSquad", "<PlayerName>", "Remove a player from their squad without kicking them", (*Simulation).adminRemovePlayerFromSquad},
+		{"AdminRemovePlayerFromSquadById", "<PlayerId>", "Remove a player from their squad without kicking them", (*Simulation).adminRemovePlayerFromSquad},
+		{"AdminDisbandSquad", "<TeamNumber = [1|2]> <SquadIndex>", "Disbands the specified Squad", (*Simulation).adminDisbandSquad},
+		{"AdminChangeLayer", "<LayerName>", "Change the layer and travel to it immediately", (*Simulation).adminChangeLayer},
+		{"AdminSetNextLayer", "<LayerName>", "Set the next layer to travel to after this match ends", (*Simulation).adminSetNextLayer},
+		{"AdminEndMatch", "", "Tell the server to immediately end the match", (*Simulation).adminEndMatch},
+		{"AdminRestartMatch", "", "Tell the server to restart the match", (*Simulation).adminRestartMatch},
+	}
+}
+
+// Execute returns the response to the RCON command.
+func (s *Simulation) Execute(commandLine string) string {
+	name, arguments, _ := strings.Cut(strings.TrimSpace(commandLine), " ")
+	arguments = strings.TrimSpace(arguments)
+
+	for _, command := range commands {
+		if !strings.EqualFold(command.name, name) {
+			continue
+		}
+
+		s.lock.Lock()
+		response, messages := command.execute(s, arguments)
+		s.lock.Unlock()
+
+		for _, message := range messages {
+			s.push(message)
+		}
+		return response
+	}
+
+	return fmt.Sprintf("Unknown command: %s", name)
+}
+
+// Handler returns a handler for rcon.Server that accepts clients using the password.
+func (s *Simulation) Handler(password string) rcon.ServerHandler {
+	return handler{simulation: s, password: password}
+}
+
+type handler struct {
+	simulation *Simulation
+	password   string
+}
+
+func (h handler) Authenticate(session *rcon.ServerSession, password string) error {
+	if subtle.ConstantTimeCompare([]byte(password), []byte(h.password)) != 1 {
+		return rcon.ErrIncorrectPassword
+	}
+	return nil
+}
+
+func (h handler) Execute(session *rcon.ServerSession, command string) (string, error) {
+	return h.simulation.Execute(command), nil
+}
+
+func (s *Simulation) listCommands(arguments string) (string, []string) {
+	var builder strings.Builder
+	for _, command := range commands {
+		builder.WriteString(command.name)
+		if command.arguments != "" {
+			builder.WriteString(" " + command.arguments)
+		}
+		builder.WriteString(" (" + command.help + ")\n")
+	}
+	return builder.String(), nil
+}
+
+func (s *Simulation) listPlayers(arguments string) (string, []string) {
+	var builder strings.Builder
+	builder.WriteString("----- Active Players -----\n")
+	for _, player := range s.players {
+		squadId := "N/A"
+		if player.SquadIndex != 0 {
+			squadId = strconv.Itoa(player.SquadIndex)
+		}
+
+		fmt.Fprintf(
+			&builder,
+			"ID: %d | SteamID: %s | Name: %s | Team ID: %d | Squad ID: %s | Is Leader: %s | Role: %s\n",
+			player.MatchId,
+			player.SteamId,
+			player.Name,
+			player.TeamIndex,
+			squadId,
+			formatBool(player.IsSquadLead),
+			player.Kit,
+		)
+	}
+
+	fmt.Fprintf(&builder, "----- Recently Disconnected Players [Max of %d] -----\n", maxDisconnectedPlayers)
+	now := s.settings.Now()
+	for _, player := range s.disconnected {
+		since := now.Sub(player.DisconnectTime)
+		fmt.Fprintf(
+			&builder,
+			"ID: %d | SteamID: %s | Since Disconnect: %02dm.%02ds | Name: %s\n",
+			player.MatchId,
+			player.SteamId,
+			int(since.Minutes()),
+			int(since.Seconds())%60,
+			player.Name,
+		)
+	}
+	return builder.String(), nil
+}
+
+func (s *Simulation) listSquads(arguments string) (string, []string) {
+	var builder strings.Builder
+	builder.WriteString("----- Active Squads -----\n")
+	for _, team := range s.teams() {
+		fmt.Fprintf(&builder, "Team ID: %d (%s)\n", team.Index, team.Faction)
+		for _, squad := range s.squads {
+			if squad.TeamIndex != team.Index {
+				continue
+			}
+
+			fmt.Fprintf(
+				&builder,
+				"ID: %d | Name: %s | Size: %d | Locked: %s | Creator Name: %s | Creator Steam ID: %s\n",
+				squad.Id,
+				squad.Name,
+				squad.Size,
+				formatBool(squad.Locked),
+				squad.CreatorName,
+				squad.CreatorSteamId,
+			)
+		}
+	}
+	return builder.String(), nil
+}
+
+func (s *Simulation) showCurrentMap(arguments string) (string, []string) {
+	return "Current level is " + formatLayer(s.layer), nil
+}
+
+func (s *Simulation) showNextMap(arguments string) (string, []string) {
+	return "Next level is " + formatLayer(s.nextLayer), nil
+}
+
+func (s *Simulation) listLayers(arguments string) (string, []string) {
+	var builder strings.Builder
+	builder.WriteString("List of available layers :\n")
+	for _, layer := range s.settings.Layers {
+		builder.WriteString(layer.Name + "\n")
+	}
+	return builder.String(), nil
+}
+
+func (s *Simulation) listLevels(arguments string) (string, []string) {
+	var builder strings.Builder
+	builder.WriteString("List of available levels :\n")
+	listed := make(map[string]bool)
+	for _, layer := range s.settings.Layers {
+		if !listed[layer.Level] {
+			listed[layer.Level] = true
+			builder.WriteString(layer.Level + "\n")
+		}
+	}
+	return builder.String(), nil
+}
+
+func (s *Simulation) showServerInfo(arguments string) (string, []string) {
+	// Squad reports most numbers as strings.
+	info := map[string]any{
+		"ServerName_s":    s.settings.ServerName,
+		"GameVersion_s":   "v7.0.0.0",
+		"MaxPlayers":      s.settings.MaxPlayers,
+		"PlayerCount_I":   strconv.Itoa(len(s.players)),
+		"PublicQueue_I":   "0",
+		"ReservedQueue_I": "0",
+		"MapName_s":       s.layer.Name,
+		"NextLayer_s":     s.nextLayer.Name,
+		"TeamOne_s":       s.layer.Factions[0].Id,
+		"TeamTwo_s":       s.layer.Factions[1].Id,
+		"PLAYTIME_I":      strconv.Itoa(int(s.settings.Now().Sub(s.matchStart).Seconds())),
+	}
+
+	response, err := json.Marshal(info)
+	if err != nil {
+		return err.Error(), nil
+	}
+	return string(response), nil
+}
+
+func (s *Simulation) adminBroadcast(arguments string) (string, []string) {
+	return "Message broadcasted", nil
+}
+
+func (s *Simulation) adminWarn(arguments string) (string, []string) {
+	selector, message, _ := strings.Cut(arguments, " ")
+	player, err := s.resolve(selector)
+	if err != nil {
+		return couldNotFind(selector, err), nil
+	}
+
+	// Squad confirms admin actions both as response and as pushed message.
+	confirmation := fmt.Sprintf("Remote admin has warned player %s. Message was \"%s\"", player.Name, strings.TrimSpace(message))
+	return confirmation, []string{confirmation}
+}
+
+func (s *Simulation) adminKick(arguments string) (string, []string) {
+	selector, _, _ := strings.Cut(arguments, " ")
+	player, err := s.resolve(selector)
+	if err != nil {
+		return couldNotFind(selector, err), nil
+	}
+
+	s.disconnect(player)
+	confirmation := fmt.Sprintf("Kicked player %d. [steamid=%s] %s", player.MatchId, player.SteamId, player.Name)
+	return confirmation, []string{confirmation}
+}
+
+func (s *Simulation) adminBan(arguments string) (string, []string) {
+	fields := strings.Fields(arguments)
+	if len(fields) < 2 {
+		return "Usage: AdminBan <NameOrSteamId> <BanLength> <BanReason>", nil
+	}
+
+	player, err := s.resolve(fields[0])
+	if err != nil {
+		return couldNotFind(fields[0], err), nil
+	}
+
+	s.ban(player, fields[1])
+	confirmation := fmt.Sprintf("Banned player %d. [steamid=%s] %s for interval %s", player.MatchId, player.SteamId, player.Name, fields[1])
+	return confirmation, []string{confirmation}
+}
+
+func (s *Simulation) adminForceTeamChange(arguments string) (string, []string) {
+	player, err := s.resolve(arguments)
+	if err != nil {
+		return couldNotFind(arguments, err), nil
+	}
+
+	player = s.changeTeam(player)
+	return fmt.Sprintf("Forced team change for player %d. [steamid=%s] %s", player.MatchId, player.SteamId, player.Name), nil
+}
+
+func (s *Simulation) adminRemovePlayerFromSquad(arguments string) (string, []string) {
+	player, err := s.resolve(arguments)
+	if err != nil {
+		return couldNotFind(arguments, err), nil
+	}
+
+	if player.SquadIndex == 0 {
+		return fmt.Sprintf("Player %s is not in a squad", player.Name), nil
+	}
+
+	s.leaveSquad(player)
+	return fmt.Sprintf("Player %d (%s) was removed from squad", player.MatchId, player.Name), nil
+}
+
+func (s *Simulation) adminDisbandSquad(arguments string) (string, []string) {
+	fields := strings.Fields(arguments)
+	if len(fields) != 2 {
+		return "Usage: AdminDisbandSquad <TeamNumber = [1|2]> <SquadIndex>", nil
+	}
+
+	team, teamErr := strconv.Atoi(fields[0])
+	squadId, squadErr := strconv.Atoi(fields[1])
+	index := -1
+	if teamErr == nil && squadErr == nil {
+		index = s.squadIndex(team, squadId)
+	}
+	if index < 0 {
+		return fmt.Sprintf("Could not find squad %s on team %s", fields[1], fields[0]), nil
+	}
+
+	squad := s.squads[index]
+	for _, player := range s.players {
+		if player.TeamIndex == team && player.SquadIndex == squadId {
+			s.leaveSquad(player)
+		}
+	}
+	return fmt.Sprintf("Remote admin disbanded squad %d on team %d, named \"%s\"", squad.Id, team, squad.Name), nil
+}
+
+func (s *Simulation) adminChangeLayer(arguments string) (string, []string) {
+	layer, found := findLayer(s.settings.Layers, arguments)
+	if !found {
+		return fmt.Sprintf("Could not find layer %s", arguments), nil
+	}
+
+	s.startMatch(layer)
+	return "Changed layer to " + layer.Name, nil
+}
+
+func (s *Simulation) adminSetNextLayer(arguments string) (string, []string) {
+	layer, found := findLayer(s.settings.Layers, arguments)
+	if !found {
+		return fmt.Sprintf("Could not find layer %s", arguments), nil
+	}
+
+	s.nextLayer = layer
+	return "Set next layer to " + layer.Name, nil
+}
+
+func (s *Simulation) adminEndMatch(arguments string) (string, []string) {
+	s.startMatch(s.nextLayer)
+	return "Match ended", nil
+}
+
+func (s *Simulation) adminRestartMatch(arguments string) (string, []string) {
+	s.restartMatch()
+	return "Game restarted", nil
+}
+
+// formatLayer returns e.g. `Narva, layer is Narva_AAS_v1, factions USA RGF`.
+func formatLayer(layer Layer) string {
+	return fmt.Sprintf("%s, layer is %s, factions %s %s", layer.Level, layer.Name, layer.Factions[0].Id, layer.Factions[1].Id)
+}
+
+func formatBool(value bool) string {
+	if value {
+		return "True"
+	}
+	return "False"
+}
+
+func couldNotFind(selector string, err error) string {
+	var ambiguous *squadrcon.AmbiguousPlayerError
+	if errors.As(err, &ambiguous) {
+		return fmt.Sprintf("Multiple players match %s", selector)
+	}
+	return fmt.Sprintf("Could not find player %s", selector)
+}
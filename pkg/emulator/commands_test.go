@@ -0,0 +1,204 @@
+package emulator
+
+import (
+	"reflect"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClock is a clock for Settings.Now that only moves when advanced.
+type testClock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+func (c *testClock) Advance(duration time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(duration)
+}
+
+// newTestSimulation returns a simulation with two players in a squad on team 1 and one player
+// without squad on team 2.
+func newTestSimulation(t *testing.T) (*Simulation, *testClock, *[]string) {
+	t.Helper()
+
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	messages := &[]string{}
+	simulation := NewSimulation(Settings{
+		Now: clock.Now,
+		OnMessage: func(message string) {
+			*messages = append(*messages, message)
+		},
+	})
+
+	players := []struct {
+		name    string
+		steamId squadrcon.SteamID64
+		team    int
+	}{
+		{"✯RAIDR✯Jon", 76561197999957991, 1},
+		{"creaman", 76561197989362395, 1},
+		{"Some Name | With Bar", 76561198000000001, 2},
+	}
+	for _, player := range players {
+		if _, err := simulation.Join(player.name, player.steamId, player.team); err != nil {
+			t.Fatalf("could not join %s: %v", player.name, err)
+		}
+	}
+
+	if _, err := simulation.CreateSquad("76561197999957991", "INF | MIC"); err != nil {
+		t.Fatalf("could not create squad: %v", err)
+	}
+	if err := simulation.JoinSquad("creaman", 1); err != nil {
+		t.Fatalf("could not join squad: %v", err)
+	}
+	if err := simulation.LockSquad(1, 1, true); err != nil {
+		t.Fatalf("could not lock squad: %v", err)
+	}
+
+	*messages = nil
+	return simulation, clock, messages
+}
+
+func TestListPlayersRoundTrip(t *testing.T) {
+	simulation, clock, _ := newTestSimulation(t)
+
+	if response := simulation.Execute("AdminKickById 1 AFK"); !strings.HasPrefix(response, "Kicked player 1.") {
+		t.Fatalf("got %q, expected creaman to be kicked", response)
+	}
+	clock.Advance(90 * time.Second)
+
+	players, err := squadrcon.ParsePlayersList(simulation.Execute("ListPlayers"))
+	if err != nil {
+		t.Fatalf("could not parse ListPlayers: %v", err)
+	}
+
+	expected := simulation.Players()
+	if !reflect.DeepEqual(players.ActivePlayers, expected.ActivePlayers) {
+		t.Errorf("got active players %+v, expected %+v", players.ActivePlayers, expected.ActivePlayers)
+	}
+
+	if len(players.DisconnectedPlayers) != 1 {
+		t.Fatalf("got %+v, expected creaman to be disconnected", players.DisconnectedPlayers)
+	}
+	disconnected := players.DisconnectedPlayers[0]
+	if disconnected.MatchId != 1 || disconnected.SteamId != 76561197989362395 || disconnected.Name != "creaman" {
+		t.Errorf("got %+v, expected creaman", disconnected)
+	}
+	if since := time.Since(disconnected.DisconnectTime); since < 89*time.Second || since > 95*time.Second {
+		t.Errorf("got disconnected since %s, expected 90s", since)
+	}
+}
+
+func TestListSquadsRoundTrip(t *testing.T) {
+	simulation, _, _ := newTestSimulation(t)
+
+	squads, err := squadrcon.ParseSquadList(simulation.Execute("ListSquads"))
+	if err != nil {
+		t.Fatalf("could not parse ListSquads: %v", err)
+	}
+
+	expected := simulation.Squads()
+	if !reflect.DeepEqual(squads, expected) {
+		t.Errorf("got %+v, expected %+v", squads, expected)
+	}
+	if len(squads.Squads) != 1 || squads.Squads[0].Size != 2 || !squads.Squads[0].Locked {
+		t.Errorf("got %+v, expected a locked squad of two", squads.Squads)
+	}
+}
+
+func TestShowMapRoundTrip(t *testing.T) {
+	simulation, _, _ := newTestSimulation(t)
+
+	current, err := squadrcon.ParseCurrentMap(simulation.Execute("ShowCurrentMap"))
+	if err != nil {
+		t.Fatalf("could not parse ShowCurrentMap: %v", err)
+	}
+	expected := squadrcon.LayerInfo{Level: "Narva", Layer: "Narva_AAS_v1", Factions: []string{"USA", "RGF"}}
+	if !current.Equal(expected) {
+		t.Errorf("got %+v, expected %+v", current, expected)
+	}
+
+	next, err := squadrcon.ParseNextMap(simulation.Execute("ShowNextMap"))
+	if err != nil {
+		t.Fatalf("could not parse ShowNextMap: %v", err)
+	}
+	expected = squadrcon.LayerInfo{Level: "Gorodok", Layer: "Gorodok_RAAS_v1", Factions: []string{"CAF", "RGF"}}
+	if !next.Equal(expected) {
+		t.Errorf("got %+v, expected %+v", next, expected)
+	}
+
+	info, err := squadrcon.ParseServerInfo(simulation.Execute("ShowServerInfo"))
+	if err != nil {
+		t.Fatalf("could not parse ShowServerInfo: %v", err)
+	}
+	if info.CurrentLayer != "Narva_AAS_v1" || info.NextLayer != "Gorodok_RAAS_v1" || info.PlayerCount != 3 {
+		t.Errorf("got %+v, expected Narva with 3 players", info)
+	}
+}
+
+func TestAdminKick(t *testing.T) {
+	simulation, _, messages := newTestSimulation(t)
+
+	response := simulation.Execute("AdminKick creaman Stop")
+	expected := "Kicked player 1. [steamid=76561197989362395] creaman"
+	if response != expected {
+		t.Errorf("got %q, expected %q", response, expected)
+	}
+	if !reflect.DeepEqual(*messages, []string{expected}) {
+		t.Errorf("got messages %q, expected the confirmation to be pushed", *messages)
+	}
+
+	players := simulation.Players()
+	for _, player := range players.ActivePlayers {
+		if player.Name == "creaman" {
+			t.Errorf("expected creaman to be removed, got %+v", players.ActivePlayers)
+		}
+	}
+	if len(players.DisconnectedPlayers) != 1 || players.DisconnectedPlayers[0].Name != "creaman" {
+		t.Errorf("got %+v, expected creaman to be listed as disconnected", players.DisconnectedPlayers)
+	}
+	if squads := simulation.Squads().Squads; len(squads) != 1 || squads[0].Size != 1 {
+		t.Errorf("got %+v, expected the squad to shrink", squads)
+	}
+
+	if response := simulation.Execute("AdminKick nobody"); response != "Could not find player nobody" {
+		t.Errorf("got %q, expected an unknown player", response)
+	}
+}
+
+func TestAdminChangeLayer(t *testing.T) {
+	simulation, _, _ := newTestSimulation(t)
+
+	if response := simulation.Execute("AdminChangeLayer fallujah_invasion_v1"); response != "Changed layer to Fallujah_Invasion_v1" {
+		t.Fatalf("got %q, expected the layer to change", response)
+	}
+
+	if squads := simulation.Squads(); len(squads.Squads) != 0 || squads.Teams[0].Faction != "United States Marine Corps" {
+		t.Errorf("got %+v, expected no squads on Fallujah", squads)
+	}
+	for _, player := range simulation.Players().ActivePlayers {
+		if player.SquadIndex != 0 || player.IsSquadLead || !strings.HasSuffix(player.Kit, "_Rifleman_01") {
+			t.Errorf("got %+v, expected the player to be reset", player)
+		}
+	}
+	if layer := simulation.NextLayer(); layer.Name != "Sumari_Seed_v1" {
+		t.Errorf("got next layer %s, expected the layer after Fallujah", layer.Name)
+	}
+
+	if response := simulation.Execute("AdminChangeLayer Unknown_v1"); response != "Could not find layer Unknown_v1" {
+		t.Errorf("got %q, expected an unknown layer", response)
+	}
+}
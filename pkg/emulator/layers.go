@@ -0,0 +1,79 @@
+package emulator
+
+import (
+	"strings"
+)
+
+// Faction plays on a team of a layer.
+type Faction struct {
+	// Id is the abbreviation used in `ShowCurrentMap` and as kit prefix, e.g. `USA`.
+	Id string
+
+	// Name is used in squad creation messages and the `ListSquads` team headers, e.g.
+	// `United States Army`.
+	Name string
+}
+
+// Layer is a playable layer.
+type Layer struct {
+	// Level is the map, e.g. `Narva`.
+	Level string
+
+	// Name is the layer name, e.g. `Narva_AAS_v1`.
+	Name string
+
+	// Factions contains the faction of team 1 and team 2.
+	Factions [2]Faction
+}
+
+var (
+	factionUSA  = Faction{Id: "USA", Name: "United States Army"}
+	factionUSMC = Faction{Id: "USMC", Name: "United States Marine Corps"}
+	factionBAF  = Faction{Id: "BAF", Name: "British Armed Forces"}
+	factionCAF  = Faction{Id: "CAF", Name: "Canadian Armed Forces"}
+	factionRGF  = Faction{Id: "RGF", Name: "Russian Ground Forces"}
+	factionMEA  = Faction{Id: "MEA", Name: "Middle Eastern Alliance"}
+	factionINS  = Faction{Id: "INS", Name: "Insurgent Forces"}
+)
+
+// DefaultLayers is used when no layers are configured.
+var DefaultLayers = []Layer{
+	{Level: "Narva", Name: "Narva_AAS_v1", Factions: [2]Faction{factionUSA, factionRGF}},
+	{Level: "Gorodok", Name: "Gorodok_RAAS_v1", Factions: [2]Faction{factionCAF, factionRGF}},
+	{Level: "Yehorivka", Name: "Yehorivka_AAS_v2", Factions: [2]Faction{factionBAF, factionMEA}},
+	{Level: "Fallujah", Name: "Fallujah_Invasion_v1", Factions: [2]Faction{factionUSMC, factionINS}},
+	{Level: "Sumari Bala", Name: "Sumari_Seed_v1", Factions: [2]Faction{factionBAF, factionINS}},
+}
+
+// kitRoles are combined with the faction to kits, e.g. `USA_Medic_01`.
+var kitRoles = []string{
+	"Rifleman",
+	"Medic",
+	"AutomaticRifleman",
+	"Grenadier",
+	"LAT",
+	"HAT",
+	"Engineer",
+	"Marksman",
+	"Crewman",
+	"Pilot",
+}
+
+const (
+	defaultKitRole = "Rifleman"
+	leaderKitRole  = "SL"
+)
+
+func kit(faction Faction, role string) string {
+	return faction.Id + "_" + role + "_01"
+}
+
+// findLayer returns the layer with the name, compared case-insensitively.
+func findLayer(layers []Layer, name string) (Layer, bool) {
+	for _, layer := range layers {
+		if strings.EqualFold(layer.Name, name) {
+			return layer, true
+		}
+	}
+	return Layer{}, false
+}
@@ -0,0 +1,292 @@
+package emulator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"squad-rcon-go/pkg/squadrcon"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownAction = errors.New("unknown scenario action")
+)
+
+// ScenarioAction is performed by a step of a scenario.
+type ScenarioAction string
+
+const (
+	// ActionJoin connects Player with SteamId to Team, or the smaller team if Team is 0.
+	ActionJoin ScenarioAction = "join"
+
+	// ActionLeave disconnects Player.
+	ActionLeave ScenarioAction = "leave"
+
+	// ActionCreateSquad creates a squad named Squad, led by Player.
+	ActionCreateSquad ScenarioAction = "createSquad"
+
+	// ActionJoinSquad moves Player into the squad with SquadId.
+	ActionJoinSquad ScenarioAction = "joinSquad"
+
+	// ActionLeaveSquad removes Player from their squad.
+	ActionLeaveSquad ScenarioAction = "leaveSquad"
+
+	// ActionLockSquad locks the squad with SquadId on Team, or unlocks it if Locked is false.
+	ActionLockSquad ScenarioAction = "lockSquad"
+
+	// ActionKit switches the kit of Player to Kit.
+	ActionKit ScenarioAction = "kit"
+
+	// ActionChangeTeam moves Player to the other team.
+	ActionChangeTeam ScenarioAction = "changeTeam"
+
+	// ActionChat sends Message as Player on Channel, ChatAll if empty.
+	ActionChat ScenarioAction = "chat"
+
+	// ActionChangeLayer starts a match on Layer.
+	ActionChangeLayer ScenarioAction = "changeLayer"
+
+	// ActionSetNextLayer sets the next layer to Layer.
+	ActionSetNextLayer ScenarioAction = "setNextLayer"
+
+	// ActionEndMatch starts a match on the next layer.
+	ActionEndMatch ScenarioAction = "endMatch"
+
+	// ActionMessage pushes Message to RCON clients as is, e.g. for messages the emulator does not
+	// simulate.
+	ActionMessage ScenarioAction = "message"
+)
+
+// Duration is a time.Duration written as string in scenario files, e.g. `1m30s`.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// Scenario scripts the events on the emulated server, e.g.
+//
+//	{
+//	  "Layer": "Narva_AAS_v1",
+//	  "Steps": [
+//	    {"Action": "join", "Player": "Jon", "SteamId": "76561197999957991", "Team": 1},
+//	    {"Wait": "2s", "Action": "createSquad", "Player": "Jon", "Squad": "ALPHA"},
+//	    {"Wait": "1s", "Action": "chat", "Player": "Jon", "Message": "need logi"}
+//	  ]
+//	}
+type Scenario struct {
+	// Layers replaces the layers that can be played. Optional.
+	Layers []Layer
+
+	// Layer is played when the scenario starts. Optional.
+	Layer string
+
+	// Loop restarts the steps after the last one. Players and squads are not reset.
+	Loop bool
+
+	Steps []ScenarioStep
+}
+
+// ScenarioStep performs an action. Only the fields used by the action are required, see the
+// ScenarioAction constants.
+type ScenarioStep struct {
+	// Wait is the time to wait after the previous step.
+	Wait Duration
+
+	Action ScenarioAction
+
+	// Player selects a player by Steam ID, match ID or name, as squadrcon.ResolvePlayer does. When
+	// joining, it is the name of the player.
+	Player string
+
+	SteamId squadrcon.SteamID64
+
+	Team int
+
+	Squad string
+
+	SquadId int
+
+	Locked bool
+
+	Kit string
+
+	Channel squadrcon.ChatChannel
+
+	Message string
+
+	Layer string
+}
+
+// ReadScenario reads a scenario from a JSON file.
+func ReadScenario(path string) (Scenario, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	defer file.Close()
+
+	return ParseScenario(file)
+}
+
+// ParseScenario parses a JSON scenario and rejects unknown actions.
+func ParseScenario(reader io.Reader) (Scenario, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+
+	var scenario Scenario
+	if err := decoder.Decode(&scenario); err != nil {
+		return Scenario{}, err
+	}
+
+	var errs []error
+	for i, step := range scenario.Steps {
+		if !step.Action.isKnown() {
+			errs = append(errs, fmt.Errorf("step %d: %w: %s", i, ErrUnknownAction, step.Action))
+		}
+	}
+
+	return scenario, errors.Join(errs...)
+}
+
+func (a ScenarioAction) isKnown() bool {
+	switch a {
+	case ActionJoin, ActionLeave, ActionCreateSquad, ActionJoinSquad, ActionLeaveSquad, ActionLockSquad,
+		ActionKit, ActionChangeTeam, ActionChat, ActionChangeLayer, ActionSetNextLayer, ActionEndMatch,
+		ActionMessage:
+		return true
+	default:
+		return false
+	}
+}
+
+type ScenarioPlayerSettings struct {
+	// OnError is called when a step fails. The scenario continues with the next step. Optional.
+	OnError func(err error)
+
+	// OnDone is called after the last step, unless the scenario loops or is closed. Optional.
+	OnDone func()
+}
+
+// ScenarioPlayer performs the steps of a scenario on a Simulation.
+type ScenarioPlayer struct {
+	simulation *Simulation
+	scenario   Scenario
+	settings   ScenarioPlayerSettings
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewScenarioPlayer starts playing the scenario. Call Close to stop.
+func NewScenarioPlayer(simulation *Simulation, scenario Scenario, settings ScenarioPlayerSettings) *ScenarioPlayer {
+	p := &ScenarioPlayer{
+		simulation: simulation,
+		scenario:   scenario,
+		settings:   settings,
+		done:       make(chan struct{}),
+	}
+
+	go p.run()
+
+	return p
+}
+
+// Close stops playing the scenario.
+func (p *ScenarioPlayer) Close() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+	})
+}
+
+func (p *ScenarioPlayer) run() {
+	if p.scenario.Layer != "" {
+		p.reportError(p.simulation.ChangeLayer(p.scenario.Layer))
+	}
+
+	for {
+		for i, step := range p.scenario.Steps {
+			select {
+			case <-p.done:
+				return
+			case <-time.After(time.Duration(step.Wait)):
+			}
+
+			if err := p.perform(step); err != nil {
+				p.reportError(fmt.Errorf("step %d (%s): %w", i, step.Action, err))
+			}
+		}
+
+		// Prevents spinning on looping scenarios without steps or waits.
+		if !p.scenario.Loop || len(p.scenario.Steps) == 0 {
+			break
+		}
+	}
+
+	if p.settings.OnDone != nil {
+		p.settings.OnDone()
+	}
+}
+
+func (p *ScenarioPlayer) perform(step ScenarioStep) error {
+	simulation := p.simulation
+
+	switch step.Action {
+	case ActionJoin:
+		_, err := simulation.Join(step.Player, step.SteamId, step.Team)
+		return err
+	case ActionLeave:
+		return simulation.Leave(step.Player)
+	case ActionCreateSquad:
+		_, err := simulation.CreateSquad(step.Player, step.Squad)
+		return err
+	case ActionJoinSquad:
+		return simulation.JoinSquad(step.Player, step.SquadId)
+	case ActionLeaveSquad:
+		return simulation.LeaveSquad(step.Player)
+	case ActionLockSquad:
+		return simulation.LockSquad(step.Team, step.SquadId, step.Locked)
+	case ActionKit:
+		return simulation.SetKit(step.Player, step.Kit)
+	case ActionChangeTeam:
+		_, err := simulation.ChangeTeam(step.Player)
+		return err
+	case ActionChat:
+		channel := step.Channel
+		if channel == "" {
+			channel = squadrcon.ChatAll
+		}
+		return simulation.Chat(step.Player, channel, step.Message)
+	case ActionChangeLayer:
+		return simulation.ChangeLayer(step.Layer)
+	case ActionSetNextLayer:
+		return simulation.SetNextLayer(step.Layer)
+	case ActionEndMatch:
+		simulation.EndMatch()
+		return nil
+	case ActionMessage:
+		simulation.push(step.Message)
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownAction, step.Action)
+	}
+}
+
+func (p *ScenarioPlayer) reportError(err error) {
+	if err != nil && p.settings.OnError != nil {
+		p.settings.OnError(err)
+	}
+}
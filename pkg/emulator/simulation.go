@@ -0,0 +1,559 @@
+// Package emulator simulates a Squad server for integration tests of RCON tools. The simulation
+// answers commands in the formats of the real server, see data/, and is driven by Autoplay,
+// which simulates players, and ScenarioPlayer, which replays a scripted scenario.
+package emulator
+
+import (
+	"errors"
+	"fmt"
+	"squad-rcon-go/pkg/squadrcon"
+	"sync"
+	"time"
+)
+
+var (
+	ErrServerFull       = errors.New("server is full")
+	ErrPlayerBanned     = errors.New("player is banned")
+	ErrAlreadyConnected = errors.New("player is already connected")
+	ErrSquadNotFound    = errors.New("squad does not exist")
+	ErrSquadLocked      = errors.New("squad is locked")
+	ErrLayerNotFound    = errors.New("layer does not exist")
+	ErrInvalidTeam      = errors.New("team must be 1 or 2")
+)
+
+const (
+	defaultServerName = "Squad RCON Emulator"
+	defaultMaxPlayers = 100
+
+	// Squad lists at most this many recently disconnected players.
+	maxDisconnectedPlayers = 15
+)
+
+type Settings struct {
+	// Layers contains the layers that can be played. Defaults to DefaultLayers. The first layer
+	// is played first.
+	Layers []Layer
+
+	// ServerName is reported by ShowServerInfo. Defaults to `Squad RCON Emulator`.
+	ServerName string
+
+	// MaxPlayers is the number of players that can join. Defaults to 100.
+	MaxPlayers int
+
+	// OnMessage is called for messages the server pushes to RCON clients, e.g. chat messages. It
+	// must not block. Usually rcon.Server.Broadcast. Optional.
+	OnMessage func(message string)
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Simulation contains the state of the emulated server. It is safe for concurrent use.
+type Simulation struct {
+	settings Settings
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	layer      Layer
+	nextLayer  Layer
+	matchStart time.Time
+
+	players      []squadrcon.ActivePlayer
+	disconnected []squadrcon.DisconnectedPlayer
+	squads       []squadrcon.Squad
+	bans         map[squadrcon.SteamID64]string
+
+	nextMatchId int
+}
+
+func NewSimulation(settings Settings) *Simulation {
+	if len(settings.Layers) == 0 {
+		settings.Layers = DefaultLayers
+	}
+
+	if settings.ServerName == "" {
+		settings.ServerName = defaultServerName
+	}
+
+	if settings.MaxPlayers <= 0 {
+		settings.MaxPlayers = defaultMaxPlayers
+	}
+
+	if settings.Now == nil {
+		settings.Now = time.Now
+	}
+
+	s := &Simulation{
+		settings:   settings,
+		layer:      settings.Layers[0],
+		matchStart: settings.Now(),
+		bans:       make(map[squadrcon.SteamID64]string),
+	}
+	s.nextLayer = s.layerAfter(s.layer)
+
+	return s
+}
+
+// Layers returns the layers that can be played.
+func (s *Simulation) Layers() []Layer {
+	return s.settings.Layers
+}
+
+// Layer returns the current layer.
+func (s *Simulation) Layer() Layer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.layer
+}
+
+// NextLayer returns the layer played after the current match.
+func (s *Simulation) NextLayer() Layer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.nextLayer
+}
+
+// Players returns the connected and recently disconnected players.
+func (s *Simulation) Players() squadrcon.PlayerList {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return squadrcon.PlayerList{
+		ActivePlayers:       append([]squadrcon.ActivePlayer(nil), s.players...),
+		DisconnectedPlayers: append([]squadrcon.DisconnectedPlayer(nil), s.disconnected...),
+	}
+}
+
+// Squads returns the teams and their squads.
+func (s *Simulation) Squads() squadrcon.SquadList {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return squadrcon.SquadList{
+		Teams:  s.teams(),
+		Squads: append([]squadrcon.Squad(nil), s.squads...),
+	}
+}
+
+// Join connects a player. If team is 0, the player joins the team with fewer players.
+func (s *Simulation) Join(name string, steamId squadrcon.SteamID64, team int) (squadrcon.ActivePlayer, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if team < 0 || team > 2 {
+		return squadrcon.ActivePlayer{}, fmt.Errorf("%w: %d", ErrInvalidTeam, team)
+	}
+
+	if len(s.players) >= s.settings.MaxPlayers {
+		return squadrcon.ActivePlayer{}, ErrServerFull
+	}
+
+	if _, banned := s.bans[steamId]; banned {
+		return squadrcon.ActivePlayer{}, fmt.Errorf("%w: %s", ErrPlayerBanned, steamId)
+	}
+
+	for _, player := range s.players {
+		if player.SteamId == steamId {
+			return squadrcon.ActivePlayer{}, fmt.Errorf("%w: %s", ErrAlreadyConnected, steamId)
+		}
+	}
+
+	if team == 0 {
+		team = 1
+		if s.teamSize(2) < s.teamSize(1) {
+			team = 2
+		}
+	}
+
+	player := squadrcon.ActivePlayer{
+		MatchId:   s.nextMatchId,
+		SteamId:   steamId,
+		Name:      name,
+		TeamIndex: team,
+		Kit:       kit(s.layer.Factions[team-1], defaultKitRole),
+	}
+	s.nextMatchId++
+	s.players = append(s.players, player)
+
+	// Players that reconnect are no longer listed as disconnected.
+	for i, disconnected := range s.disconnected {
+		if disconnected.SteamId == steamId {
+			s.disconnected = append(s.disconnected[:i], s.disconnected[i+1:]...)
+			break
+		}
+	}
+
+	return player, nil
+}
+
+// Leave disconnects the player.
+func (s *Simulation) Leave(selector string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	player, err := s.resolve(selector)
+	if err != nil {
+		return err
+	}
+
+	s.disconnect(player)
+	return nil
+}
+
+// CreateSquad creates a squad led by the player, who leaves their current squad.
+func (s *Simulation) CreateSquad(selector string, name string) (squadrcon.Squad, error) {
+	s.lock.Lock()
+
+	player, err := s.resolve(selector)
+	if err != nil {
+		s.lock.Unlock()
+		return squadrcon.Squad{}, err
+	}
+
+	s.leaveSquad(player)
+
+	squad := squadrcon.Squad{
+		Id:             s.freeSquadId(player.TeamIndex),
+		TeamIndex:      player.TeamIndex,
+		Name:           name,
+		Size:           1,
+		CreatorName:    player.Name,
+		CreatorSteamId: player.SteamId,
+	}
+	s.squads = append(s.squads, squad)
+
+	s.updatePlayer(player.MatchId, func(player *squadrcon.ActivePlayer) {
+		player.SquadIndex = squad.Id
+		player.IsSquadLead = true
+		player.Kit = kit(s.layer.Factions[player.TeamIndex-1], leaderKitRole)
+	})
+
+	message := fmt.Sprintf(
+		"%s (Steam ID: %s) has created Squad %d (Squad Name: %s) on %s",
+		player.Name,
+		player.SteamId,
+		squad.Id,
+		squad.Name,
+		s.layer.Factions[player.TeamIndex-1].Name,
+	)
+	s.lock.Unlock()
+
+	s.push(message)
+	return squad, nil
+}
+
+// JoinSquad moves the player into a squad of their team.
+func (s *Simulation) JoinSquad(selector string, squadId int) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	player, err := s.resolve(selector)
+	if err != nil {
+		return err
+	}
+
+	index := s.squadIndex(player.TeamIndex, squadId)
+	if index < 0 {
+		return fmt.Errorf("%w: team %d, squad %d", ErrSquadNotFound, player.TeamIndex, squadId)
+	}
+	if player.SquadIndex == squadId {
+		return nil
+	}
+	if s.squads[index].Locked {
+		return fmt.Errorf("%w: team %d, squad %d", ErrSquadLocked, player.TeamIndex, squadId)
+	}
+
+	s.leaveSquad(player)
+
+	// Leaving can remove a squad, which moves the squads after it.
+	index = s.squadIndex(player.TeamIndex, squadId)
+	s.squads[index].Size++
+	s.updatePlayer(player.MatchId, func(player *squadrcon.ActivePlayer) {
+		player.SquadIndex = squadId
+	})
+	return nil
+}
+
+// LeaveSquad removes the player from their squad.
+func (s *Simulation) LeaveSquad(selector string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	player, err := s.resolve(selector)
+	if err != nil {
+		return err
+	}
+
+	s.leaveSquad(player)
+	return nil
+}
+
+// LockSquad locks or unlocks a squad.
+func (s *Simulation) LockSquad(team int, squadId int, locked bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	index := s.squadIndex(team, squadId)
+	if index < 0 {
+		return fmt.Errorf("%w: team %d, squad %d", ErrSquadNotFound, team, squadId)
+	}
+
+	s.squads[index].Locked = locked
+	return nil
+}
+
+// SetKit changes the kit of the player, e.g. to `USA_Medic_01`.
+func (s *Simulation) SetKit(selector string, kit string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	player, err := s.resolve(selector)
+	if err != nil {
+		return err
+	}
+
+	s.updatePlayer(player.MatchId, func(player *squadrcon.ActivePlayer) {
+		player.Kit = kit
+	})
+	return nil
+}
+
+// ChangeTeam moves the player to the other team.
+func (s *Simulation) ChangeTeam(selector string) (squadrcon.ActivePlayer, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	player, err := s.resolve(selector)
+	if err != nil {
+		return squadrcon.ActivePlayer{}, err
+	}
+
+	return s.changeTeam(player), nil
+}
+
+// Chat sends a chat message of the player, e.g. on squadrcon.ChatAll.
+func (s *Simulation) Chat(selector string, channel squadrcon.ChatChannel, message string) error {
+	s.lock.Lock()
+	player, err := s.resolve(selector)
+	s.lock.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	s.push(fmt.Sprintf("[%s] [SteamID:%s] %s : %s", channel, player.SteamId, player.Name, message))
+	return nil
+}
+
+// ChangeLayer starts a new match on the layer. All squads are disbanded.
+func (s *Simulation) ChangeLayer(name string) error {
+	layer, found := findLayer(s.settings.Layers, name)
+	if !found {
+		return fmt.Errorf("%w: %s", ErrLayerNotFound, name)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.startMatch(layer)
+	return nil
+}
+
+// SetNextLayer sets the layer played after the current match.
+func (s *Simulation) SetNextLayer(name string) error {
+	layer, found := findLayer(s.settings.Layers, name)
+	if !found {
+		return fmt.Errorf("%w: %s", ErrLayerNotFound, name)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.nextLayer = layer
+	return nil
+}
+
+// EndMatch starts a new match on the next layer.
+func (s *Simulation) EndMatch() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.startMatch(s.nextLayer)
+}
+
+// RestartMatch starts a new match on the current layer.
+func (s *Simulation) RestartMatch() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.restartMatch()
+}
+
+// restartMatch starts a new match on the current layer, keeping the next layer.
+func (s *Simulation) restartMatch() {
+	next := s.nextLayer
+	s.startMatch(s.layer)
+	s.nextLayer = next
+}
+
+// startMatch disbands all squads and equips players with the default kit of their new faction.
+func (s *Simulation) startMatch(layer Layer) {
+	s.layer = layer
+	s.nextLayer = s.layerAfter(layer)
+	s.matchStart = s.settings.Now()
+	s.squads = nil
+
+	for i := range s.players {
+		player := &s.players[i]
+		player.SquadIndex = 0
+		player.IsSquadLead = false
+		player.Kit = kit(layer.Factions[player.TeamIndex-1], defaultKitRole)
+	}
+}
+
+// changeTeam moves the player to the other team and returns the updated player.
+func (s *Simulation) changeTeam(player squadrcon.ActivePlayer) squadrcon.ActivePlayer {
+	s.leaveSquad(player)
+
+	player.TeamIndex = 3 - player.TeamIndex
+	player.SquadIndex = 0
+	player.IsSquadLead = false
+	player.Kit = kit(s.layer.Factions[player.TeamIndex-1], defaultKitRole)
+	s.updatePlayer(player.MatchId, func(existing *squadrcon.ActivePlayer) {
+		*existing = player
+	})
+	return player
+}
+
+// ban disconnects the player and prevents them from joining again.
+func (s *Simulation) ban(player squadrcon.ActivePlayer, interval string) {
+	s.bans[player.SteamId] = interval
+	s.disconnect(player)
+}
+
+// disconnect removes the player, who is listed as recently disconnected afterwards.
+func (s *Simulation) disconnect(player squadrcon.ActivePlayer) {
+	s.leaveSquad(player)
+
+	for i := range s.players {
+		if s.players[i].MatchId == player.MatchId {
+			s.players = append(s.players[:i], s.players[i+1:]...)
+			break
+		}
+	}
+
+	s.disconnected = append(s.disconnected, squadrcon.DisconnectedPlayer{
+		MatchId:        player.MatchId,
+		SteamId:        player.SteamId,
+		Name:           player.Name,
+		DisconnectTime: s.settings.Now(),
+	})
+	if len(s.disconnected) > maxDisconnectedPlayers {
+		s.disconnected = s.disconnected[len(s.disconnected)-maxDisconnectedPlayers:]
+	}
+}
+
+// leaveSquad removes the player from their squad. Leadership passes to the member who joined
+// first, empty squads are disbanded.
+func (s *Simulation) leaveSquad(player squadrcon.ActivePlayer) {
+	if player.SquadIndex == 0 {
+		return
+	}
+
+	s.updatePlayer(player.MatchId, func(player *squadrcon.ActivePlayer) {
+		// The squad leader kit is only available to squad leaders.
+		if player.IsSquadLead {
+			player.Kit = kit(s.layer.Factions[player.TeamIndex-1], defaultKitRole)
+		}
+		player.SquadIndex = 0
+		player.IsSquadLead = false
+	})
+
+	index := s.squadIndex(player.TeamIndex, player.SquadIndex)
+	if index < 0 {
+		return
+	}
+
+	s.squads[index].Size--
+	if s.squads[index].Size <= 0 {
+		s.squads = append(s.squads[:index], s.squads[index+1:]...)
+		return
+	}
+
+	if player.IsSquadLead {
+		for i := range s.players {
+			member := &s.players[i]
+			if member.TeamIndex == player.TeamIndex && member.SquadIndex == player.SquadIndex {
+				member.IsSquadLead = true
+				break
+			}
+		}
+	}
+}
+
+func (s *Simulation) resolve(selector string) (squadrcon.ActivePlayer, error) {
+	return squadrcon.ResolvePlayer(selector, squadrcon.PlayerList{ActivePlayers: s.players})
+}
+
+func (s *Simulation) updatePlayer(matchId int, update func(player *squadrcon.ActivePlayer)) {
+	for i := range s.players {
+		if s.players[i].MatchId == matchId {
+			update(&s.players[i])
+			return
+		}
+	}
+}
+
+func (s *Simulation) squadIndex(team int, squadId int) int {
+	for i, squad := range s.squads {
+		if squad.TeamIndex == team && squad.Id == squadId {
+			return i
+		}
+	}
+	return -1
+}
+
+// freeSquadId returns the lowest squad ID that is not in use in the team, as Squad does.
+func (s *Simulation) freeSquadId(team int) int {
+	for id := 1; ; id++ {
+		if s.squadIndex(team, id) < 0 {
+			return id
+		}
+	}
+}
+
+func (s *Simulation) teamSize(team int) int {
+	size := 0
+	for _, player := range s.players {
+		if player.TeamIndex == team {
+			size++
+		}
+	}
+	return size
+}
+
+func (s *Simulation) teams() []squadrcon.Team {
+	return []squadrcon.Team{
+		{Index: 1, Faction: s.layer.Factions[0].Name},
+		{Index: 2, Faction: s.layer.Factions[1].Name},
+	}
+}
+
+// layerAfter returns the layer following the layer in the rotation.
+func (s *Simulation) layerAfter(layer Layer) Layer {
+	for i, candidate := range s.settings.Layers {
+		if candidate.Name == layer.Name {
+			return s.settings.Layers[(i+1)%len(s.settings.Layers)]
+		}
+	}
+	return s.settings.Layers[0]
+}
+
+func (s *Simulation) push(message string) {
+	if s.settings.OnMessage != nil {
+		s.settings.OnMessage(message)
+	}
+}
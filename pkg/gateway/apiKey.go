@@ -0,0 +1,118 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Scope permits the use of a group of endpoints.
+type Scope string
+
+const (
+	// ScopeRead permits reading players, squads, layers and server information.
+	ScopeRead Scope = "read"
+
+	ScopeBroadcast Scope = "broadcast"
+	ScopeWarn      Scope = "warn"
+	ScopeKick      Scope = "kick"
+	ScopeBan       Scope = "ban"
+
+	// ScopeExecute permits running arbitrary RCON commands.
+	ScopeExecute Scope = "execute"
+
+	// ScopeEvents permits receiving live events.
+	ScopeEvents Scope = "events"
+
+	// ScopeAll permits everything.
+	ScopeAll Scope = "*"
+)
+
+// KnownScopes contains all scopes, except for ScopeAll.
+var KnownScopes = []Scope{
+	ScopeRead,
+	ScopeBroadcast,
+	ScopeWarn,
+	ScopeKick,
+	ScopeBan,
+	ScopeExecute,
+	ScopeEvents,
+}
+
+// APIKey authenticates requests. Keys are passed as `Authorization: Bearer <key>` or
+// `X-API-Key: <key>` header.
+type APIKey struct {
+	// Name identifies the key in the audit log, e.g. the name of the application using it.
+	Name string
+
+	Key string
+
+	Scopes []Scope
+}
+
+// HasScope returns whether the key permits the scope.
+func (k APIKey) HasScope(scope Scope) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope || granted == ScopeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// keyring finds API keys without leaking the keys through response timing.
+type keyring struct {
+	keys    []APIKey
+	digests [][sha256.Size]byte
+}
+
+func newKeyring(keys []APIKey) keyring {
+	ring := keyring{}
+	for _, key := range keys {
+		// Empty keys would match requests without key.
+		if key.Key == "" {
+			continue
+		}
+		ring.keys = append(ring.keys, key)
+		ring.digests = append(ring.digests, sha256.Sum256([]byte(key.Key)))
+	}
+	return ring
+}
+
+// find returns the key matching the key passed with the request, optionally accepting the `key`
+// query parameter. All keys are compared, so the time taken does not depend on which key matches.
+func (r keyring) find(request *http.Request, allowQuery bool) (APIKey, bool) {
+	passed := requestKey(request)
+	if passed == "" && allowQuery {
+		passed = request.URL.Query().Get("key")
+	}
+	if passed == "" {
+		return APIKey{}, false
+	}
+
+	digest := sha256.Sum256([]byte(passed))
+	match := -1
+	for i := range r.digests {
+		if subtle.ConstantTimeCompare(digest[:], r.digests[i][:]) == 1 {
+			match = i
+		}
+	}
+
+	if match < 0 {
+		return APIKey{}, false
+	}
+	return r.keys[match], true
+}
+
+func requestKey(request *http.Request) string {
+	if key := request.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	scheme, key, found := strings.Cut(request.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(key)
+}
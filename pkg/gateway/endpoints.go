@@ -0,0 +1,318 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+)
+
+// MapResponse contains the current and next layer.
+type MapResponse struct {
+	Current squadrcon.LayerInfo
+	Next    squadrcon.LayerInfo
+}
+
+type BroadcastRequest struct {
+	Message string
+
+	// Priority is one of `low`, `normal` and `urgent`. Defaults to `normal`. Only used if the
+	// gateway queues broadcasts.
+	Priority string
+}
+
+type BroadcastResponse struct {
+	// Queued is false if the message was dropped as a duplicate of a recently queued message.
+	Queued bool
+}
+
+// WarnRequest, KickRequest and BanRequest identify the player using a selector as accepted by
+// squadrcon.ResolvePlayer, e.g. a Steam ID, EOS ID, match ID or name.
+type WarnRequest struct {
+	Player  string
+	Message string
+}
+
+type KickRequest struct {
+	Player string
+	Reason string
+}
+
+type BanRequest struct {
+	Player string
+
+	// Interval is the length of the ban, e.g. `1d` or `0` for a permanent ban.
+	Interval string
+
+	Reason string
+}
+
+// PlayerActionResponse contains the player a warn, kick or ban was issued to.
+type PlayerActionResponse struct {
+	Player squadrcon.ActivePlayer
+}
+
+type ExecuteRequest struct {
+	Command string
+}
+
+type ExecuteResponse struct {
+	Response string
+}
+
+var broadcastPriorities = map[string]squadrcon.BroadcastPriority{
+	"":       squadrcon.BroadcastPriorityNormal,
+	"low":    squadrcon.BroadcastPriorityLow,
+	"normal": squadrcon.BroadcastPriorityNormal,
+	"urgent": squadrcon.BroadcastPriorityUrgent,
+}
+
+func (h *Handler) defaultRoutes() []route {
+	return []route{
+		{
+			method:   http.MethodGet,
+			path:     "/openapi.json",
+			summary:  "OpenAPI description of this API",
+			response: map[string]any{},
+			handle: func(h *Handler, _ *http.Request, _ *AuditEntry) (any, error) {
+				return h.OpenAPI(), nil
+			},
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/players",
+			summary:  "List active and recently disconnected players",
+			scope:    ScopeRead,
+			response: squadrcon.PlayerList{},
+			handle: func(h *Handler, _ *http.Request, _ *AuditEntry) (any, error) {
+				return squadrcon.ListPlayers(h.rcon)
+			},
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/squads",
+			summary:  "List teams and squads",
+			scope:    ScopeRead,
+			response: squadrcon.SquadList{},
+			handle: func(h *Handler, _ *http.Request, _ *AuditEntry) (any, error) {
+				return squadrcon.ListSquads(h.rcon)
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/snapshot",
+			summary:     "List teams with their squads and players",
+			description: "Combines the player and squad lists, see squadrcon.Snapshot.",
+			scope:       ScopeRead,
+			response:    squadrcon.Snapshot{},
+			handle: func(h *Handler, _ *http.Request, _ *AuditEntry) (any, error) {
+				return squadrcon.TakeSnapshot(h.rcon)
+			},
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/map",
+			summary:  "Show the current and next layer",
+			scope:    ScopeRead,
+			response: MapResponse{},
+			handle:   handleMap,
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/layers",
+			summary:  "List the layers available on the server",
+			scope:    ScopeRead,
+			response: []string{},
+			handle: func(h *Handler, _ *http.Request, _ *AuditEntry) (any, error) {
+				return squadrcon.ListLayers(h.rcon)
+			},
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/server-info",
+			summary:  "Show server information",
+			scope:    ScopeRead,
+			response: squadrcon.ServerInfo{},
+			handle: func(h *Handler, _ *http.Request, _ *AuditEntry) (any, error) {
+				return squadrcon.ShowServerInfo(h.rcon)
+			},
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/broadcast",
+			summary:  "Show a message to all players",
+			scope:    ScopeBroadcast,
+			request:  BroadcastRequest{},
+			response: BroadcastResponse{},
+			handle:   handleBroadcast,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/warn",
+			summary:  "Show a message to a player",
+			scope:    ScopeWarn,
+			request:  WarnRequest{},
+			response: PlayerActionResponse{},
+			handle:   handleWarn,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/kick",
+			summary:  "Kick a player",
+			scope:    ScopeKick,
+			request:  KickRequest{},
+			response: PlayerActionResponse{},
+			handle:   handleKick,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/ban",
+			summary:  "Ban a player",
+			scope:    ScopeBan,
+			request:  BanRequest{},
+			response: PlayerActionResponse{},
+			handle:   handleBan,
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/execute",
+			summary:     "Run an RCON command",
+			description: "Returns the raw response of the server.",
+			scope:       ScopeExecute,
+			request:     ExecuteRequest{},
+			response:    ExecuteResponse{},
+			handle:      handleExecute,
+		},
+	}
+}
+
+func handleMap(h *Handler, _ *http.Request, _ *AuditEntry) (any, error) {
+	current, err := squadrcon.ShowCurrentMap(h.rcon)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := squadrcon.ShowNextMap(h.rcon)
+	if err != nil {
+		return nil, err
+	}
+
+	return MapResponse{Current: current, Next: next}, nil
+}
+
+func handleBroadcast(h *Handler, request *http.Request, entry *AuditEntry) (any, error) {
+	var body BroadcastRequest
+	if err := decodeRequest(request, &body); err != nil {
+		return nil, err
+	}
+	entry.Request = body
+
+	if strings.TrimSpace(body.Message) == "" {
+		return nil, fmt.Errorf("%w: Message is required", ErrBadRequest)
+	}
+
+	priority, valid := broadcastPriorities[strings.ToLower(body.Priority)]
+	if !valid {
+		return nil, fmt.Errorf("%w: Priority must be low, normal or urgent", ErrBadRequest)
+	}
+
+	if queue, ok := h.rcon.(broadcaster); ok {
+		return BroadcastResponse{Queued: queue.Broadcast(body.Message, priority)}, nil
+	}
+
+	if err := squadrcon.AdminBroadcast(h.rcon, body.Message); err != nil {
+		return nil, err
+	}
+	return BroadcastResponse{Queued: true}, nil
+}
+
+func handleWarn(h *Handler, request *http.Request, entry *AuditEntry) (any, error) {
+	var body WarnRequest
+	if err := decodeRequest(request, &body); err != nil {
+		return nil, err
+	}
+	entry.Request = body
+
+	if strings.TrimSpace(body.Message) == "" {
+		return nil, fmt.Errorf("%w: Message is required", ErrBadRequest)
+	}
+
+	return h.actOnPlayer(body.Player, entry, func(id string) error {
+		return squadrcon.AdminWarn(h.rcon, id, body.Message)
+	})
+}
+
+func handleKick(h *Handler, request *http.Request, entry *AuditEntry) (any, error) {
+	var body KickRequest
+	if err := decodeRequest(request, &body); err != nil {
+		return nil, err
+	}
+	entry.Request = body
+
+	return h.actOnPlayer(body.Player, entry, func(id string) error {
+		return squadrcon.AdminKick(h.rcon, id, body.Reason)
+	})
+}
+
+func handleBan(h *Handler, request *http.Request, entry *AuditEntry) (any, error) {
+	var body BanRequest
+	if err := decodeRequest(request, &body); err != nil {
+		return nil, err
+	}
+	entry.Request = body
+
+	if body.Interval == "" || strings.ContainsAny(body.Interval, " \t\r\n") {
+		return nil, fmt.Errorf("%w: Interval must be a single word such as 1d, or 0 for a permanent ban", ErrBadRequest)
+	}
+
+	return h.actOnPlayer(body.Player, entry, func(id string) error {
+		return squadrcon.AdminBan(h.rcon, id, body.Interval, body.Reason)
+	})
+}
+
+func handleExecute(h *Handler, request *http.Request, entry *AuditEntry) (any, error) {
+	var body ExecuteRequest
+	if err := decodeRequest(request, &body); err != nil {
+		return nil, err
+	}
+	entry.Request = body
+
+	if strings.TrimSpace(body.Command) == "" {
+		return nil, fmt.Errorf("%w: Command is required", ErrBadRequest)
+	}
+
+	response, err := h.rcon.Execute(body.Command)
+	if err != nil {
+		return nil, err
+	}
+	return ExecuteResponse{Response: response}, nil
+}
+
+// actOnPlayer resolves the selector among the active players and calls action with the ID of the
+// player, the Steam ID if known and the EOS ID otherwise.
+func (h *Handler) actOnPlayer(selector string, entry *AuditEntry, action func(id string) error) (any, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, fmt.Errorf("%w: Player is required", ErrBadRequest)
+	}
+
+	players, err := squadrcon.ListPlayers(h.rcon)
+	if err != nil {
+		return nil, err
+	}
+
+	player, err := squadrcon.ResolvePlayer(selector, players)
+	if err != nil {
+		return nil, err
+	}
+	entry.Player = &player
+
+	id := player.SteamId.String()
+	if id == "" {
+		id = player.EosId.String()
+	}
+
+	if err := action(id); err != nil {
+		return nil, err
+	}
+	return PlayerActionResponse{Player: player}, nil
+}
@@ -0,0 +1,219 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"squad-rcon-go/pkg/squadrcon"
+	"testing"
+	"time"
+)
+
+// decodeJSON decodes JSON into generic values, so that bodies can be compared regardless of field
+// order and formatting.
+func decodeJSON(t *testing.T, data []byte) any {
+	t.Helper()
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	return value
+}
+
+// encodeJSON returns value as it is expected to be served.
+func encodeJSON(t *testing.T, value any) any {
+	t.Helper()
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("could not encode %+v: %v", value, err)
+	}
+	return decodeJSON(t, data)
+}
+
+func TestEndpoints(t *testing.T) {
+	players, err := squadrcon.ParsePlayersList(testPlayers)
+	if err != nil {
+		t.Fatalf("could not parse players: %v", err)
+	}
+	squads, err := squadrcon.ParseSquadList(testSquads)
+	if err != nil {
+		t.Fatalf("could not parse squads: %v", err)
+	}
+	current, _ := squadrcon.ParseCurrentMap(newFakeRcon().responses["ShowCurrentMap"])
+	next, _ := squadrcon.ParseNextMap(newFakeRcon().responses["ShowNextMap"])
+	serverInfo, err := squadrcon.ParseServerInfo(testServerInfo)
+	if err != nil {
+		t.Fatalf("could not parse server info: %v", err)
+	}
+
+	jon := players.ActivePlayers[0]
+	consolePlayer := players.ActivePlayers[1]
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		expected any
+		commands []string
+	}{
+		{
+			name:     "players",
+			method:   http.MethodGet,
+			path:     "/players",
+			status:   http.StatusOK,
+			expected: players,
+			commands: []string{"ListPlayers"},
+		},
+		{
+			name:     "squads",
+			method:   http.MethodGet,
+			path:     "/squads",
+			status:   http.StatusOK,
+			expected: squads,
+			commands: []string{"ListSquads"},
+		},
+		{
+			name:     "map",
+			method:   http.MethodGet,
+			path:     "/map",
+			status:   http.StatusOK,
+			expected: MapResponse{Current: current, Next: next},
+			commands: []string{"ShowCurrentMap", "ShowNextMap"},
+		},
+		{
+			name:     "layers",
+			method:   http.MethodGet,
+			path:     "/layers/",
+			status:   http.StatusOK,
+			expected: []string{"Narva_AAS_v1", "Yehorivka_RAAS_v2"},
+			commands: []string{"ListLayers"},
+		},
+		{
+			name:     "server info",
+			method:   http.MethodGet,
+			path:     "/server-info",
+			status:   http.StatusOK,
+			expected: serverInfo,
+			commands: []string{"ShowServerInfo"},
+		},
+		{
+			name:     "broadcast",
+			method:   http.MethodPost,
+			path:     "/broadcast",
+			body:     `{"Message":"Seeding rules apply","Priority":"urgent"}`,
+			status:   http.StatusOK,
+			expected: BroadcastResponse{Queued: true},
+			commands: []string{"AdminBroadcast Seeding rules apply"},
+		},
+		{
+			name:     "broadcast with invalid priority",
+			method:   http.MethodPost,
+			path:     "/broadcast",
+			body:     `{"Message":"Seeding rules apply","Priority":"high"}`,
+			status:   http.StatusBadRequest,
+			expected: ErrorResponse{Error: "bad request: Priority must be low, normal or urgent"},
+		},
+		{
+			name:     "warn by name",
+			method:   http.MethodPost,
+			path:     "/warn",
+			body:     `{"Player":"jon","Message":"Stop"}`,
+			status:   http.StatusOK,
+			expected: PlayerActionResponse{Player: jon},
+			commands: []string{"ListPlayers", "AdminWarn 76561197999957991 Stop"},
+		},
+		{
+			name:     "kick player without Steam ID",
+			method:   http.MethodPost,
+			path:     "/kick",
+			body:     `{"Player":"0002b2c3d4e5f60718293a4b5c6d7e8f","Reason":"AFK"}`,
+			status:   http.StatusOK,
+			expected: PlayerActionResponse{Player: consolePlayer},
+			commands: []string{"ListPlayers", "AdminKick 0002b2c3d4e5f60718293a4b5c6d7e8f AFK"},
+		},
+		{
+			name:     "ban",
+			method:   http.MethodPost,
+			path:     "/ban",
+			body:     `{"Player":"76561197999957991","Interval":"1d","Reason":"Team killing"}`,
+			status:   http.StatusOK,
+			expected: PlayerActionResponse{Player: jon},
+			commands: []string{"ListPlayers", "AdminBan 76561197999957991 1d Team killing"},
+		},
+		{
+			name:     "kick unknown player",
+			method:   http.MethodPost,
+			path:     "/kick",
+			body:     `{"Player":"creaman"}`,
+			status:   http.StatusNotFound,
+			commands: []string{"ListPlayers"},
+		},
+		{
+			name:   "unknown field",
+			method: http.MethodPost,
+			path:   "/warn",
+			body:   `{"Player":"Jon","Text":"Stop"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:     "execute",
+			method:   http.MethodPost,
+			path:     "/execute",
+			body:     `{"Command":"ShowCurrentMap"}`,
+			status:   http.StatusOK,
+			expected: ExecuteResponse{Response: "Current level is Narva, layer is Narva_AAS_v1, factions USA RGF"},
+			commands: []string{"ShowCurrentMap"},
+		},
+	}
+
+	rcon := newFakeRcon()
+	handler := NewHandler(rcon, HandlerSettings{Keys: testKeys})
+
+	for _, test := range tests {
+		response := serve(handler, test.method, test.path, test.body, bearer("admin-key"))
+		if response.Code != test.status {
+			t.Errorf("%s: got status %d, expected %d: %s", test.name, response.Code, test.status, response.Body)
+		}
+		if contentType := response.Header().Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("%s: got content type %s", test.name, contentType)
+		}
+
+		if test.expected != nil {
+			if body, expected := decodeJSON(t, response.Body.Bytes()), encodeJSON(t, test.expected); !reflect.DeepEqual(body, expected) {
+				t.Errorf("%s: got %v, expected %v", test.name, body, expected)
+			}
+		}
+
+		if commands := rcon.Commands(); !reflect.DeepEqual(commands, test.commands) {
+			t.Errorf("%s: got commands %q, expected %q", test.name, commands, test.commands)
+		}
+	}
+}
+
+func TestSnapshotEndpoint(t *testing.T) {
+	handler := NewHandler(newFakeRcon(), HandlerSettings{Keys: testKeys})
+
+	response := serve(handler, http.MethodGet, "/snapshot", "", bearer("read-key"))
+	if response.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", response.Code, response.Body)
+	}
+
+	var snapshot squadrcon.Snapshot
+	if err := json.Unmarshal(response.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if time.Since(snapshot.Time) > time.Minute || len(snapshot.Teams) != 2 {
+		t.Fatalf("got %+v, expected a current snapshot of two teams", snapshot)
+	}
+	if squads := snapshot.Teams[0].Squads; len(squads) != 1 || squads[0].Leader == nil || squads[0].Leader.Name != "✯RAIDR✯Jon" {
+		t.Errorf("got %+v, expected squad 1 led by Jon", squads)
+	}
+	if unassigned := snapshot.Teams[1].Unassigned; len(unassigned) != 1 || unassigned[0].Name != "console player" {
+		t.Errorf("got %+v, expected the console player to be unassigned", unassigned)
+	}
+}
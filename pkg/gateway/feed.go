@@ -0,0 +1,327 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"squad-rcon-go/pkg/squadrcon"
+	"sync"
+	"time"
+)
+
+// Kinds of feed messages.
+const (
+	FeedKindEvent     = "event"
+	FeedKindHeartbeat = "heartbeat"
+	FeedKindGap       = "gap"
+)
+
+// FeedEvent is an event of the event bus as sent to feed clients.
+type FeedEvent struct {
+	// Kind is always `event`.
+	Kind string
+
+	// Sequence increases by one for every event published on the bus. Clients pass the sequence
+	// of the last event they received to resume after reconnecting.
+	Sequence uint64
+
+	ServerId string
+	Time     time.Time
+	Topic    squadrcon.Topic
+
+	// Type contains the Go type name of the payload, e.g. `ChatMessage` or `PlayerJoinedEvent`.
+	Type string
+
+	Payload any
+}
+
+// FeedHeartbeat is sent periodically when there are no events, so clients can detect stale
+// connections.
+type FeedHeartbeat struct {
+	// Kind is always `heartbeat`.
+	Kind string
+
+	Time time.Time
+
+	// Sequence contains the sequence of the last event published on the bus.
+	Sequence uint64
+}
+
+// FeedGap is sent before replayed events when events after the requested sequence are no longer
+// buffered, e.g. because the client was disconnected for too long or the gateway restarted.
+// Clients should reload their state, e.g. using GET /snapshot.
+type FeedGap struct {
+	// Kind is always `gap`.
+	Kind string
+
+	// After contains the sequence requested by the client.
+	After uint64
+
+	// Next contains the sequence of the first event that is sent, or of the next event published
+	// if no events are replayed.
+	Next uint64
+}
+
+const (
+	defaultFeedReplaySize       = 1000
+	defaultFeedHeartbeat        = 15 * time.Second
+	defaultFeedClientBufferSize = 256
+	feedSubscriptionBufferSize  = 1024
+)
+
+type FeedSettings struct {
+	// ReplaySize is the amount of recent events kept for clients resuming after a reconnect.
+	// Defaults to 1000.
+	ReplaySize int
+
+	// Heartbeat is the interval between heartbeats. Defaults to 15 seconds.
+	Heartbeat time.Duration
+
+	// ClientBufferSize is the amount of events that can be pending for a client. Clients that
+	// fall further behind are disconnected and can resume. Defaults to 256.
+	ClientBufferSize int
+
+	// OnError is called when an event cannot be encoded. Optional.
+	OnError func(err error)
+}
+
+// Feed buffers the events of an event bus and distributes them to the clients of the `/events`
+// and `/ws` endpoints.
+type Feed struct {
+	settings     FeedSettings
+	subscription *squadrcon.Subscription[squadrcon.Event]
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// Recent events, oldest first.
+	replay []feedItem
+
+	// The sequence of the last received event.
+	last uint64
+
+	clients map[*feedClient]struct{}
+
+	closed bool
+}
+
+// feedItem is an event together with its encoding, so events are only encoded once.
+type feedItem struct {
+	sequence uint64
+	topic    squadrcon.Topic
+	data     []byte
+}
+
+type feedClient struct {
+	// Accepted topics, nil if all topics are accepted.
+	topics map[squadrcon.Topic]struct{}
+
+	items chan feedItem
+
+	// Closed when the client is disconnected by the feed, because it is too slow or the feed
+	// is closed.
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func (c *feedClient) close() {
+	c.doneOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *feedClient) accepts(topic squadrcon.Topic) bool {
+	if c.topics == nil {
+		return true
+	}
+	_, accepted := c.topics[topic]
+	return accepted
+}
+
+// feedStart is what a client receives when subscribing.
+type feedStart struct {
+	client *feedClient
+
+	// Nil if no events were missed.
+	gap *FeedGap
+
+	replay []feedItem
+}
+
+// NewFeed creates a Feed receiving all events of the bus. Close stops receiving.
+func NewFeed(bus *squadrcon.EventBus, settings FeedSettings) *Feed {
+	if settings.ReplaySize <= 0 {
+		settings.ReplaySize = defaultFeedReplaySize
+	}
+	if settings.Heartbeat <= 0 {
+		settings.Heartbeat = defaultFeedHeartbeat
+	}
+	if settings.ClientBufferSize <= 0 {
+		settings.ClientBufferSize = defaultFeedClientBufferSize
+	}
+
+	f := &Feed{
+		settings: settings,
+		subscription: bus.Subscribe(squadrcon.SubscribeOptions{
+			BufferSize: feedSubscriptionBufferSize,
+		}),
+		clients: make(map[*feedClient]struct{}),
+	}
+
+	go f.run()
+
+	return f
+}
+
+// Close disconnects all clients and stops receiving events.
+func (f *Feed) Close() {
+	f.subscription.Unsubscribe()
+}
+
+func (f *Feed) run() {
+	for event := range f.subscription.Events() {
+		f.add(event)
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.closed = true
+	for client := range f.clients {
+		client.close()
+	}
+	f.clients = nil
+}
+
+func (f *Feed) add(event squadrcon.Event) {
+	data, err := json.Marshal(newFeedEvent(event))
+	if err != nil {
+		if f.settings.OnError != nil {
+			f.settings.OnError(fmt.Errorf("failed to encode event %d on topic %s: %w", event.Sequence, event.Topic, err))
+		}
+		return
+	}
+
+	item := feedItem{
+		sequence: event.Sequence,
+		topic:    event.Topic,
+		data:     data,
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.last = event.Sequence
+	f.replay = append(f.replay, item)
+	if len(f.replay) > f.settings.ReplaySize {
+		f.replay = f.replay[len(f.replay)-f.settings.ReplaySize:]
+	}
+
+	for client := range f.clients {
+		if !client.accepts(item.topic) {
+			continue
+		}
+
+		select {
+		case client.items <- item:
+		default:
+			// Disconnecting is preferred over silently dropping events, the client can resume
+			// from the last event it received.
+			client.close()
+			delete(f.clients, client)
+		}
+	}
+}
+
+// subscribe adds a client receiving the topics, all topics if empty. If resume is true, the
+// buffered events after the sequence are replayed.
+func (f *Feed) subscribe(topics []squadrcon.Topic, resume bool, after uint64) (feedStart, error) {
+	client := &feedClient{
+		items: make(chan feedItem, f.settings.ClientBufferSize),
+		done:  make(chan struct{}),
+	}
+	if len(topics) > 0 {
+		client.topics = make(map[squadrcon.Topic]struct{}, len(topics))
+		for _, topic := range topics {
+			client.topics[topic] = struct{}{}
+		}
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.closed {
+		return feedStart{}, ErrFeedClosed
+	}
+
+	start := feedStart{client: client}
+	if resume {
+		start.gap, start.replay = f.replayAfter(client, after)
+	}
+
+	f.clients[client] = struct{}{}
+	return start, nil
+}
+
+// replayAfter returns the buffered events after the sequence accepted by the client. Must be
+// called while locked.
+func (f *Feed) replayAfter(client *feedClient, after uint64) (*FeedGap, []feedItem) {
+	next := f.last + 1
+	if len(f.replay) > 0 {
+		next = f.replay[0].sequence
+	}
+
+	// A sequence ahead of the bus was issued before the gateway restarted, all buffered events
+	// are new to the client.
+	restarted := after > f.last
+
+	var gap *FeedGap
+	if restarted || after+1 < next {
+		gap = &FeedGap{Kind: FeedKindGap, After: after, Next: next}
+	}
+
+	var replay []feedItem
+	for _, item := range f.replay {
+		if (restarted || item.sequence > after) && client.accepts(item.topic) {
+			replay = append(replay, item)
+		}
+	}
+
+	return gap, replay
+}
+
+func (f *Feed) unsubscribe(client *feedClient) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.clients, client)
+	client.close()
+}
+
+func (f *Feed) heartbeat() FeedHeartbeat {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return FeedHeartbeat{Kind: FeedKindHeartbeat, Time: time.Now(), Sequence: f.last}
+}
+
+func newFeedEvent(event squadrcon.Event) FeedEvent {
+	var typeName string
+	if event.Payload != nil {
+		payloadType := reflect.TypeOf(event.Payload)
+		for payloadType.Kind() == reflect.Pointer {
+			payloadType = payloadType.Elem()
+		}
+		typeName = payloadType.Name()
+	}
+
+	return FeedEvent{
+		Kind:     FeedKindEvent,
+		Sequence: event.Sequence,
+		ServerId: event.ServerId,
+		Time:     event.Time,
+		Topic:    event.Topic,
+		Type:     typeName,
+		Payload:  event.Payload,
+	}
+}
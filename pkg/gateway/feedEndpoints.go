@@ -0,0 +1,262 @@
+package gateway
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"squad-rcon-go/pkg/squadrcon"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// feedWriteTimeout is the time a client has to accept a message before it is disconnected.
+const feedWriteTimeout = 10 * time.Second
+
+const feedDescription = "Query parameters: `topics`, a comma separated list of topics to receive, " +
+	"all topics if omitted; `after`, the sequence of the last event received, to replay the " +
+	"buffered events after it; `key`, the API key, for clients that cannot set headers. "
+
+func (h *Handler) feedRoutes() []route {
+	return []route{
+		{
+			method:  http.MethodGet,
+			path:    "/events",
+			summary: "Live events as Server-Sent Events",
+			description: feedDescription + "Each event is sent with its sequence as ID and its topic as " +
+				"event name, so EventSource resumes automatically using Last-Event-ID. Heartbeats " +
+				"and gaps are sent as `heartbeat` and `gap` events.",
+			scope:       ScopeEvents,
+			queryKey:    true,
+			response:    FeedEvent{},
+			contentType: "text/event-stream",
+			stream:      streamSSE,
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/ws",
+			summary: "Live events over WebSocket",
+			description: feedDescription + "Each text message contains a FeedEvent, FeedHeartbeat or " +
+				"FeedGap, distinguished by Kind.",
+			scope:    ScopeEvents,
+			queryKey: true,
+			response: FeedEvent{},
+			status:   http.StatusSwitchingProtocols,
+			stream:   streamWebSocket,
+		},
+	}
+}
+
+// subscribeFeed subscribes to the feed using the topics and resume position of the request.
+func (h *Handler) subscribeFeed(request *http.Request) (feedStart, error) {
+	query := request.URL.Query()
+
+	var topics []squadrcon.Topic
+	for _, topic := range strings.Split(query.Get("topics"), ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, squadrcon.Topic(topic))
+		}
+	}
+
+	// EventSource sends the ID of the last event it received when reconnecting.
+	position := request.Header.Get("Last-Event-ID")
+	if position == "" {
+		position = query.Get("after")
+	}
+
+	var after uint64
+	resume := position != ""
+	if resume {
+		var err error
+		after, err = strconv.ParseUint(position, 10, 64)
+		if err != nil {
+			return feedStart{}, fmt.Errorf("%w: invalid sequence %q", ErrBadRequest, position)
+		}
+	}
+
+	return h.settings.Feed.subscribe(topics, resume, after)
+}
+
+func streamSSE(h *Handler, writer http.ResponseWriter, request *http.Request) error {
+	controller := http.NewResponseController(writer)
+
+	start, err := h.subscribeFeed(request)
+	if err != nil {
+		return err
+	}
+	defer h.settings.Feed.unsubscribe(start.client)
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-store")
+	// Prevents reverse proxies such as nginx from buffering the stream.
+	writer.Header().Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+
+	send := func(event string, id string, data []byte) error {
+		_ = controller.SetWriteDeadline(time.Now().Add(feedWriteTimeout))
+
+		var builder strings.Builder
+		if id != "" {
+			builder.WriteString("id: " + id + "\n")
+		}
+		builder.WriteString("event: " + event + "\n")
+		builder.WriteString("data: ")
+		builder.Write(data)
+		builder.WriteString("\n\n")
+
+		if _, err := io.WriteString(writer, builder.String()); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+
+	stream := feedStream{
+		sendItem: func(item feedItem) error {
+			return send(string(item.topic), strconv.FormatUint(item.sequence, 10), item.data)
+		},
+		sendMessage: func(kind string, message any) error {
+			data, err := json.Marshal(message)
+			if err != nil {
+				return err
+			}
+			return send(kind, "", data)
+		},
+	}
+
+	// Write errors mean the client is gone, the response has been written either way.
+	_ = stream.run(h.settings.Feed, request, start)
+	return nil
+}
+
+func streamWebSocket(h *Handler, writer http.ResponseWriter, request *http.Request) error {
+	start, err := h.subscribeFeed(request)
+	if err != nil {
+		return err
+	}
+	defer h.settings.Feed.unsubscribe(start.client)
+
+	conn, buffer, err := upgradeWebSocket(writer, request)
+	if err != nil {
+		if errors.Is(err, errNotWebSocket) {
+			return &statusError{status: http.StatusBadRequest, err: err}
+		}
+		return err
+	}
+	defer conn.Close()
+
+	closed := make(chan struct{})
+	pings := make(chan []byte, 1)
+	go func() {
+		defer close(closed)
+		readWebSocketControlFrames(buffer.Reader, pings)
+	}()
+
+	// Only this goroutine writes, the reading goroutine passes pings on.
+	send := func(opcode byte, payload []byte) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(feedWriteTimeout))
+		return writeWebSocketFrame(buffer.Writer, opcode, payload)
+	}
+
+	stream := feedStream{
+		sendItem: func(item feedItem) error {
+			return send(opText, item.data)
+		},
+		sendMessage: func(_ string, message any) error {
+			data, err := json.Marshal(message)
+			if err != nil {
+				return err
+			}
+			return send(opText, data)
+		},
+		closed: closed,
+		pings:  pings,
+		sendPong: func(payload []byte) error {
+			return send(opPong, payload)
+		},
+	}
+
+	if err := stream.run(h.settings.Feed, request, start); err == nil {
+		// 1001, going away.
+		_ = send(opClose, []byte{0x03, 0xE9})
+	}
+	return nil
+}
+
+// readWebSocketControlFrames reads client frames until the connection is closed. Pings are passed
+// on to be answered, data frames are ignored.
+func readWebSocketControlFrames(reader *bufio.Reader, pings chan<- []byte) {
+	for {
+		opcode, payload, err := readWebSocketFrame(reader)
+		if err != nil {
+			return
+		}
+
+		switch opcode {
+		case opClose:
+			return
+		case opPing:
+			select {
+			case pings <- payload:
+			default:
+			}
+		}
+	}
+}
+
+// feedStream writes the feed to a client.
+type feedStream struct {
+	sendItem    func(item feedItem) error
+	sendMessage func(kind string, message any) error
+
+	// closed is closed when the client closes the connection. Optional.
+	closed <-chan struct{}
+
+	// pings receives the payloads of pings, which are answered using sendPong. Optional.
+	pings    <-chan []byte
+	sendPong func(payload []byte) error
+}
+
+// run sends the gap and replayed events, then live events and heartbeats until the request is
+// cancelled, the client closes the connection or the client is disconnected by the feed. Returns
+// the error of a failed write.
+func (s feedStream) run(feed *Feed, request *http.Request, start feedStart) error {
+	if start.gap != nil {
+		if err := s.sendMessage(FeedKindGap, *start.gap); err != nil {
+			return err
+		}
+	}
+	for _, item := range start.replay {
+		if err := s.sendItem(item); err != nil {
+			return err
+		}
+	}
+
+	heartbeat := time.NewTicker(feed.settings.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			return nil
+		case <-s.closed:
+			return nil
+		case <-start.client.done:
+			return nil
+		case payload := <-s.pings:
+			if err := s.sendPong(payload); err != nil {
+				return err
+			}
+		case item := <-start.client.items:
+			if err := s.sendItem(item); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if err := s.sendMessage(FeedKindHeartbeat, feed.heartbeat()); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"squad-rcon-go/pkg/squadrcon"
+	"testing"
+	"time"
+)
+
+// waitForFeed waits until the feed received the event with the sequence.
+func waitForFeed(t *testing.T, feed *Feed, sequence uint64) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		feed.lock.Lock()
+		last := feed.last
+		feed.lock.Unlock()
+
+		if last >= sequence {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for event %d, last is %d", sequence, last)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func sequences(items []feedItem) []uint64 {
+	var sequences []uint64
+	for _, item := range items {
+		sequences = append(sequences, item.sequence)
+	}
+	return sequences
+}
+
+func TestFeedResume(t *testing.T) {
+	bus := squadrcon.NewEventBus(squadrcon.EventBusSettings{})
+	feed := NewFeed(bus, FeedSettings{ReplaySize: 3})
+	defer feed.Close()
+
+	bus.Publish(squadrcon.TopicChat, squadrcon.ChatMessage{Message: "1"})
+	bus.Publish(squadrcon.TopicRoster, squadrcon.PlayerJoinedEvent{})
+	bus.Publish(squadrcon.TopicChat, squadrcon.ChatMessage{Message: "3"})
+	bus.Publish(squadrcon.TopicRoster, squadrcon.PlayerLeftEvent{})
+	bus.Publish(squadrcon.TopicChat, squadrcon.ChatMessage{Message: "5"})
+	waitForFeed(t, feed, 5)
+
+	// Events 1 and 2 have been evicted from the replay buffer of 3 events.
+	tests := []struct {
+		name   string
+		topics []squadrcon.Topic
+		resume bool
+		after  uint64
+		gap    *FeedGap
+		replay []uint64
+	}{
+		{name: "not resuming"},
+		{name: "up to date", resume: true, after: 5},
+		{name: "after buffered event", resume: true, after: 3, replay: []uint64{4, 5}},
+		{name: "after last evicted event", resume: true, after: 2, replay: []uint64{3, 4, 5}},
+		{
+			name:   "after evicted events",
+			resume: true,
+			after:  1,
+			gap:    &FeedGap{Kind: FeedKindGap, After: 1, Next: 3},
+			replay: []uint64{3, 4, 5},
+		},
+		{
+			name:   "after restart",
+			resume: true,
+			after:  42,
+			gap:    &FeedGap{Kind: FeedKindGap, After: 42, Next: 3},
+			replay: []uint64{3, 4, 5},
+		},
+		{
+			name:   "topics",
+			topics: []squadrcon.Topic{squadrcon.TopicChat},
+			resume: true,
+			after:  3,
+			replay: []uint64{5},
+		},
+	}
+
+	for _, test := range tests {
+		start, err := feed.subscribe(test.topics, test.resume, test.after)
+		if err != nil {
+			t.Fatalf("%s: could not subscribe: %v", test.name, err)
+		}
+		feed.unsubscribe(start.client)
+
+		if !reflect.DeepEqual(start.gap, test.gap) {
+			t.Errorf("%s: got gap %+v, expected %+v", test.name, start.gap, test.gap)
+		}
+		if replay := sequences(start.replay); !reflect.DeepEqual(replay, test.replay) {
+			t.Errorf("%s: got replay %v, expected %v", test.name, replay, test.replay)
+		}
+	}
+
+	var event FeedEvent
+	start, _ := feed.subscribe(nil, true, 4)
+	if err := json.Unmarshal(start.replay[0].data, &event); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if event.Kind != FeedKindEvent || event.Sequence != 5 || event.Topic != squadrcon.TopicChat || event.Type != "ChatMessage" {
+		t.Errorf("got %+v, expected chat message 5", event)
+	}
+}
+
+func TestFeedClients(t *testing.T) {
+	bus := squadrcon.NewEventBus(squadrcon.EventBusSettings{})
+	feed := NewFeed(bus, FeedSettings{ClientBufferSize: 1})
+
+	chat, _ := feed.subscribe([]squadrcon.Topic{squadrcon.TopicChat}, false, 0)
+	slow, _ := feed.subscribe(nil, false, 0)
+
+	bus.Publish(squadrcon.TopicChat, squadrcon.ChatMessage{})
+	bus.Publish(squadrcon.TopicRoster, squadrcon.PlayerJoinedEvent{})
+	waitForFeed(t, feed, 2)
+
+	if item := <-chat.client.items; item.sequence != 1 {
+		t.Errorf("got event %d, expected the chat message", item.sequence)
+	}
+
+	// The slow client did not receive its first event before the second was published.
+	select {
+	case <-slow.client.done:
+	default:
+		t.Error("expected a client falling behind to be disconnected")
+	}
+
+	feed.Close()
+	select {
+	case <-chat.client.done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Close to disconnect the clients")
+	}
+
+	if _, err := feed.subscribe(nil, false, 0); !errors.Is(err, ErrFeedClosed) {
+		t.Errorf("got %v, expected %v after Close", err, ErrFeedClosed)
+	}
+}
@@ -0,0 +1,282 @@
+// Package gateway exposes the typed Squad RCON client over HTTP with JSON bodies, authenticated
+// using API keys with scopes.
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"squad-rcon-go/pkg/rcon"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBadRequest = errors.New("bad request")
+	ErrFeedClosed = errors.New("event feed is closed")
+)
+
+const maxRequestBodySize = 64 * 1024
+
+// AuditEntry describes a handled request.
+type AuditEntry struct {
+	Time       time.Time
+	RemoteAddr string
+	Method     string
+	Path       string
+
+	// KeyName contains the name of the API key used. Empty if the request was not authenticated.
+	KeyName string
+
+	// Request contains the decoded request body, if any.
+	Request any
+
+	// Player contains the player the request acted on, if any.
+	Player *squadrcon.ActivePlayer
+
+	Status   int
+	Duration time.Duration
+
+	// Error contains the error returned to the client, if any.
+	Error string
+}
+
+type HandlerSettings struct {
+	Keys []APIKey
+
+	// Feed serves the live event endpoints `/events` and `/ws`. The endpoints are not available
+	// without feed. Optional.
+	Feed *Feed
+
+	// OnAudit is called after every request, including rejected ones. Requests of the live event
+	// endpoints are reported when the client disconnects. Optional.
+	OnAudit func(entry AuditEntry)
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Handler is an http.Handler serving the gateway API. The OpenAPI description is served on
+// `GET /openapi.json` without authentication.
+type Handler struct {
+	rcon     rcon.Rcon
+	settings HandlerSettings
+	keys     keyring
+	routes   []route
+}
+
+// route describes an endpoint. Routes are used both to serve requests and to generate the OpenAPI
+// description.
+type route struct {
+	method      string
+	path        string
+	summary     string
+	description string
+
+	// The scope required, empty for endpoints that do not require authentication.
+	scope Scope
+
+	// True if the API key can be passed as `key` query parameter, for browser APIs that cannot
+	// set headers.
+	queryKey bool
+
+	// Zero values of the request and response bodies, used for the OpenAPI description. Nil
+	// if there is no request body.
+	request  any
+	response any
+
+	// Status returned on success. Defaults to 200.
+	status int
+
+	// Content type of the response. Defaults to application/json.
+	contentType string
+
+	// handle returns the response body.
+	handle func(h *Handler, request *http.Request, entry *AuditEntry) (any, error)
+
+	// stream serves streaming endpoints instead of handle. It writes the response itself, the
+	// returned error is reported as JSON and must therefore only be returned before writing.
+	stream func(h *Handler, writer http.ResponseWriter, request *http.Request) error
+}
+
+// statusError is an error that is reported with a specific status code.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// ErrorResponse is the body of failed requests.
+type ErrorResponse struct {
+	Error string
+}
+
+// broadcaster is implemented by connections with a broadcast queue, such as
+// *squadrcon.SquadRcon.
+type broadcaster interface {
+	Broadcast(message string, priority squadrcon.BroadcastPriority) bool
+}
+
+// NewHandler creates a Handler executing commands on the connection. If the connection has a
+// broadcast queue, broadcasts are queued instead of shown immediately.
+func NewHandler(rcon rcon.Rcon, settings HandlerSettings) *Handler {
+	if settings.Now == nil {
+		settings.Now = time.Now
+	}
+
+	h := &Handler{
+		rcon:     rcon,
+		settings: settings,
+		keys:     newKeyring(settings.Keys),
+	}
+	h.routes = h.defaultRoutes()
+	if settings.Feed != nil {
+		h.routes = append(h.routes, h.feedRoutes()...)
+	}
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	entry := AuditEntry{
+		Time:       h.settings.Now(),
+		RemoteAddr: request.RemoteAddr,
+		Method:     request.Method,
+		Path:       request.URL.Path,
+	}
+
+	status, body := h.serve(writer, request, &entry)
+
+	entry.Status = status
+	entry.Duration = h.settings.Now().Sub(entry.Time)
+	if response, ok := body.(ErrorResponse); ok {
+		entry.Error = response.Error
+	}
+
+	// Streamed responses have been written already.
+	if body != nil {
+		writeJSON(writer, status, body)
+	}
+
+	if h.settings.OnAudit != nil {
+		h.settings.OnAudit(entry)
+	}
+}
+
+// serve authenticates the request and runs its route. Returns the status and the response body,
+// which is nil if the response has been streamed.
+func (h *Handler) serve(writer http.ResponseWriter, request *http.Request, entry *AuditEntry) (int, any) {
+	header := writer.Header()
+
+	path := strings.TrimSuffix(request.URL.Path, "/")
+	if path == "" {
+		path = "/"
+	}
+
+	var allowed []string
+	var matched *route
+	for i := range h.routes {
+		if h.routes[i].path != path {
+			continue
+		}
+		allowed = append(allowed, h.routes[i].method)
+		if h.routes[i].method == request.Method {
+			matched = &h.routes[i]
+		}
+	}
+
+	if len(allowed) == 0 {
+		return http.StatusNotFound, ErrorResponse{Error: "not found"}
+	}
+	if matched == nil {
+		header.Set("Allow", strings.Join(allowed, ", "))
+		return http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"}
+	}
+
+	if matched.scope != "" {
+		key, found := h.keys.find(request, matched.queryKey)
+		if !found {
+			header.Set("WWW-Authenticate", "Bearer")
+			return http.StatusUnauthorized, ErrorResponse{Error: "missing or invalid API key"}
+		}
+
+		entry.KeyName = key.Name
+		if !key.HasScope(matched.scope) {
+			return http.StatusForbidden, ErrorResponse{Error: fmt.Sprintf("API key lacks scope %q", matched.scope)}
+		}
+	}
+
+	status := http.StatusOK
+	if matched.status != 0 {
+		status = matched.status
+	}
+
+	if matched.stream != nil {
+		if err := matched.stream(h, writer, request); err != nil {
+			return statusForError(err), ErrorResponse{Error: err.Error()}
+		}
+		return status, nil
+	}
+
+	body, err := matched.handle(h, request, entry)
+	if err != nil {
+		return statusForError(err), ErrorResponse{Error: err.Error()}
+	}
+	return status, body
+}
+
+// statusForError maps errors to status codes. Errors of the server connection are reported as bad
+// gateway.
+func statusForError(err error) int {
+	var statusErr *statusError
+	var ambiguous *squadrcon.AmbiguousPlayerError
+
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.status
+	case errors.Is(err, ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, squadrcon.ErrPlayerNotFound):
+		return http.StatusNotFound
+	case errors.As(err, &ambiguous):
+		return http.StatusConflict
+	case errors.Is(err, squadrcon.ErrUnsupportedCommand):
+		return http.StatusNotImplemented
+	case errors.Is(err, ErrFeedClosed):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// decodeRequest decodes the JSON body of the request into value. Unknown fields are rejected so
+// that typos do not go unnoticed.
+func decodeRequest(request *http.Request, value any) error {
+	decoder := json.NewDecoder(io.LimitReader(request.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(value); err != nil {
+		return fmt.Errorf("%w: invalid JSON body: %v", ErrBadRequest, err)
+	}
+	return nil
+}
+
+func writeJSON(writer http.ResponseWriter, status int, body any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.WriteHeader(status)
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(body)
+}
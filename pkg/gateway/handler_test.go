@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testPlayers = "----- Active Players -----\n" +
+		"ID: 0 | Online IDs: EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561197999957991 | Name: ✯RAIDR✯Jon | Team ID: 1 | Squad ID: 1 | Is Leader: True | Role: USA_SL_01\n" +
+		"ID: 1 | Online IDs: EOS: 0002b2c3d4e5f60718293a4b5c6d7e8f | Name: console player | Team ID: 2 | Squad ID: N/A | Is Leader: False | Role: RGF_Rifleman_01\n" +
+		"----- Recently Disconnected Players [Max of 15] -----\n"
+	testSquads = "----- Active Squads -----\n" +
+		"Team ID: 1 (United States Army)\n" +
+		"ID: 1 | Name: INF | Size: 1 | Locked: False | Creator Name: ✯RAIDR✯Jon | Creator Online IDs: EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561197999957991\n" +
+		"Team ID: 2 (Russian Ground Forces)\n"
+	testServerInfo = `{"ServerName_s":"Squad RCON Emulator","GameVersion_s":"v7.0.0.123","MaxPlayers":100,"PlayerCount_I":2,"PublicQueue_I":0,"ReservedQueue_I":0,"MapName_s":"Narva_AAS_v1","NextLayer_s":"Yehorivka_RAAS_v2","TeamOne_s":"USA","TeamTwo_s":"RGF","PLAYTIME_I":754}`
+)
+
+// fakeRcon answers commands with canned responses and records the executed commands. Commands
+// without response are answered with an empty response.
+type fakeRcon struct {
+	responses map[string]string
+
+	lock     sync.Mutex
+	commands []string
+}
+
+func newFakeRcon() *fakeRcon {
+	return &fakeRcon{responses: map[string]string{
+		"ListPlayers":    testPlayers,
+		"ListSquads":     testSquads,
+		"ShowCurrentMap": "Current level is Narva, layer is Narva_AAS_v1, factions USA RGF",
+		"ShowNextMap":    "Next level is Yehorivka, layer is Yehorivka_RAAS_v2, factions USA RGF",
+		"ListLayers":     "List of available layers :\nNarva_AAS_v1\nYehorivka_RAAS_v2\n",
+		"ShowServerInfo": testServerInfo,
+	}}
+}
+
+func (r *fakeRcon) Execute(command string) (string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.commands = append(r.commands, command)
+	return r.responses[command], nil
+}
+
+func (r *fakeRcon) Close() error {
+	return nil
+}
+
+// Commands returns the executed commands and forgets them.
+func (r *fakeRcon) Commands() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	commands := r.commands
+	r.commands = nil
+	return commands
+}
+
+var testKeys = []APIKey{
+	{Name: "admin", Key: "admin-key", Scopes: []Scope{ScopeAll}},
+	{Name: "reader", Key: "read-key", Scopes: []Scope{ScopeRead}},
+	{Name: "empty", Key: "", Scopes: []Scope{ScopeAll}},
+}
+
+// serve performs a request against the handler and returns the recorded response.
+func serve(handler http.Handler, method string, path string, body string, header http.Header) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func bearer(key string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + key}}
+}
+
+func TestHandlerAuthentication(t *testing.T) {
+	rcon := newFakeRcon()
+	var audit []AuditEntry
+	handler := NewHandler(rcon, HandlerSettings{
+		Keys:    testKeys,
+		OnAudit: func(entry AuditEntry) { audit = append(audit, entry) },
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		header  http.Header
+		status  int
+		keyName string
+	}{
+		{"missing key", http.MethodGet, "/players", "", nil, http.StatusUnauthorized, ""},
+		{"wrong key", http.MethodGet, "/players", "", bearer("wrong-key"), http.StatusUnauthorized, ""},
+		{"empty key", http.MethodGet, "/players", "", http.Header{"Authorization": {"Bearer "}}, http.StatusUnauthorized, ""},
+		{"other scheme", http.MethodGet, "/players", "", http.Header{"Authorization": {"Basic read-key"}}, http.StatusUnauthorized, ""},
+		{"query key", http.MethodGet, "/players?key=read-key", "", nil, http.StatusUnauthorized, ""},
+		{"bearer key", http.MethodGet, "/players", "", bearer("read-key"), http.StatusOK, "reader"},
+		{"header key", http.MethodGet, "/players", "", http.Header{"X-Api-Key": {"read-key"}}, http.StatusOK, "reader"},
+		{"missing scope", http.MethodPost, "/kick", `{"Player":"Jon"}`, bearer("read-key"), http.StatusForbidden, "reader"},
+		{"missing execute scope", http.MethodPost, "/execute", `{"Command":"AdminEndMatch"}`, bearer("read-key"), http.StatusForbidden, "reader"},
+		{"all scopes", http.MethodPost, "/execute", `{"Command":"ListPlayers"}`, bearer("admin-key"), http.StatusOK, "admin"},
+		{"without authentication", http.MethodGet, "/openapi.json", "", nil, http.StatusOK, ""},
+		{"unknown path", http.MethodGet, "/unknown", "", bearer("admin-key"), http.StatusNotFound, ""},
+		{"wrong method", http.MethodPost, "/players", "", bearer("admin-key"), http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, test := range tests {
+		audit = nil
+		rcon.Commands()
+
+		response := serve(handler, test.method, test.path, test.body, test.header)
+		if response.Code != test.status {
+			t.Errorf("%s: got status %d, expected %d: %s", test.name, response.Code, test.status, response.Body)
+			continue
+		}
+
+		if test.status == http.StatusUnauthorized && response.Header().Get("WWW-Authenticate") != "Bearer" {
+			t.Errorf("%s: expected a WWW-Authenticate header", test.name)
+		}
+
+		if test.status >= 400 {
+			var body ErrorResponse
+			if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil || body.Error == "" {
+				t.Errorf("%s: got %s, expected an error response", test.name, response.Body)
+			}
+			if commands := rcon.Commands(); len(commands) != 0 {
+				t.Errorf("%s: got commands %q, expected none for a rejected request", test.name, commands)
+			}
+		}
+
+		if len(audit) != 1 || audit[0].Status != test.status || audit[0].KeyName != test.keyName {
+			t.Errorf("%s: got audit %+v, expected status %d of key %q", test.name, audit, test.status, test.keyName)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package gateway
+
+import (
+	"encoding"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const openAPIVersion = "3.0.3"
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+)
+
+// OpenAPI returns the OpenAPI 3 description of the API. Schemas are derived from the Go types of
+// the request and response bodies, which are encoded using their field names.
+func (h *Handler) OpenAPI() map[string]any {
+	schemas := schemaBuilder{schemas: make(map[string]any)}
+	errorSchema := schemas.schema(reflect.TypeOf(ErrorResponse{}))
+
+	paths := make(map[string]any)
+	for _, route := range h.routes {
+		operation := map[string]any{
+			"summary":     route.summary,
+			"operationId": operationId(route),
+		}
+
+		description := route.description
+		if route.scope != "" {
+			description = strings.TrimSpace(description + " Requires the `" + string(route.scope) + "` scope.")
+			operation["security"] = []any{
+				map[string]any{"bearer": []any{}},
+				map[string]any{"apiKey": []any{}},
+			}
+		} else {
+			operation["security"] = []any{}
+		}
+		if description != "" {
+			operation["description"] = description
+		}
+
+		if route.request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(schemas.schema(reflect.TypeOf(route.request))),
+			}
+		}
+
+		status := route.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]any{"description": http.StatusText(status)}
+		switch {
+		case route.stream != nil:
+			// Streams consist of events, heartbeats and gaps.
+			schema := map[string]any{"oneOf": []any{
+				schemas.schema(reflect.TypeOf(FeedEvent{})),
+				schemas.schema(reflect.TypeOf(FeedHeartbeat{})),
+				schemas.schema(reflect.TypeOf(FeedGap{})),
+			}}
+			if route.contentType != "" {
+				success["content"] = map[string]any{route.contentType: map[string]any{"schema": schema}}
+			}
+		default:
+			success["content"] = jsonContent(schemas.schema(reflect.TypeOf(route.response)))
+		}
+		responses := map[string]any{strconv.Itoa(status): success}
+		for _, errorStatus := range errorStatuses(route) {
+			responses[strconv.Itoa(errorStatus)] = map[string]any{
+				"description": http.StatusText(errorStatus),
+				"content":     jsonContent(errorSchema),
+			}
+		}
+		operation["responses"] = responses
+
+		item, exists := paths[route.path].(map[string]any)
+		if !exists {
+			item = make(map[string]any)
+			paths[route.path] = item
+		}
+		item[strings.ToLower(route.method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":   "Squad RCON gateway",
+			"version": "1",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.schemas,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+				"apiKey": map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+	}
+}
+
+// operationId returns e.g. `getPlayers` for `GET /players`.
+func operationId(route route) string {
+	var builder strings.Builder
+	builder.WriteString(strings.ToLower(route.method))
+	for _, word := range strings.FieldsFunc(route.path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.'
+	}) {
+		builder.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return builder.String()
+}
+
+// errorStatuses returns the error statuses a route can respond with.
+func errorStatuses(route route) []int {
+	if route.scope == "" {
+		return nil
+	}
+
+	statuses := []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusBadGateway}
+	if route.request != nil {
+		statuses = append(statuses, http.StatusBadRequest)
+	}
+	if _, actsOnPlayer := route.response.(PlayerActionResponse); actsOnPlayer {
+		statuses = append(statuses, http.StatusNotFound, http.StatusConflict)
+	}
+	sort.Ints(statuses)
+	return statuses
+}
+
+func jsonContent(schema any) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{"schema": schema},
+	}
+}
+
+// schemaBuilder derives JSON schemas from Go types. Named structs are added to the components and
+// referenced.
+type schemaBuilder struct {
+	schemas map[string]any
+}
+
+func (b schemaBuilder) schema(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == durationType:
+		return map[string]any{"type": "integer", "format": "int64", "description": "Duration in nanoseconds"}
+	case t.Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := b.schema(t.Elem())
+		schema["nullable"] = true
+		return schema
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+
+		name := t.Name()
+		if _, exists := b.schemas[name]; !exists {
+			// Added before the properties are built, so recursive types terminate.
+			b.schemas[name] = nil
+			b.schemas[name] = b.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		// Interfaces, e.g. map[string]any values, can hold anything.
+		return map[string]any{}
+	}
+}
+
+func (b schemaBuilder) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	b.addProperties(t, properties)
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// addProperties adds the exported fields of the struct. Fields of embedded structs are added as if
+// they were fields of the struct, as encoding/json does.
+func (b schemaBuilder) addProperties(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			b.addProperties(field.Type, properties)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		properties[field.Name] = b.schema(field.Type)
+	}
+}
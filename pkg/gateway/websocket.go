@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// The minimal subset of RFC 6455 needed to push messages to browsers: the handshake, unfragmented
+// server frames and reading client frames to answer pings and closes.
+
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Client frames are only read to handle control frames, larger frames are rejected.
+const maxWebSocketFrameSize = 64 * 1024
+
+var (
+	errNotWebSocket        = errors.New("not a WebSocket handshake")
+	errWebSocketFrameLarge = errors.New("WebSocket frame too large")
+	errWebSocketUnmasked   = errors.New("WebSocket client frame is not masked")
+)
+
+// upgradeWebSocket completes the opening handshake and takes over the connection. Returns an
+// error wrapping errNotWebSocket, without writing a response, for requests that are not a valid
+// handshake.
+func upgradeWebSocket(writer http.ResponseWriter, request *http.Request) (net.Conn, *bufio.ReadWriter, error) {
+	switch {
+	case !headerContainsToken(request.Header, "Connection", "upgrade"):
+		return nil, nil, fmt.Errorf("%w: missing Connection: Upgrade", errNotWebSocket)
+	case !headerContainsToken(request.Header, "Upgrade", "websocket"):
+		return nil, nil, fmt.Errorf("%w: missing Upgrade: websocket", errNotWebSocket)
+	case request.Header.Get("Sec-WebSocket-Version") != "13":
+		return nil, nil, fmt.Errorf("%w: unsupported version, expected 13", errNotWebSocket)
+	}
+
+	key := request.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, nil, fmt.Errorf("%w: invalid Sec-WebSocket-Key", errNotWebSocket)
+	}
+
+	hijacker, ok := writer.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("connection does not support WebSocket")
+	}
+
+	conn, buffer, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	digest := sha1.Sum([]byte(key + webSocketGUID))
+	_, err = buffer.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(digest[:]) + "\r\n\r\n")
+	if err == nil {
+		err = buffer.Flush()
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+
+	return conn, buffer, nil
+}
+
+func headerContainsToken(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeWebSocketFrame writes an unfragmented, unmasked frame as sent by servers.
+func writeWebSocketFrame(writer *bufio.Writer, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+
+	switch length := len(payload); {
+	case length < 126:
+		header = append(header, byte(length))
+	case length <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	if _, err := writer.Write(header); err != nil {
+		return err
+	}
+	if _, err := writer.Write(payload); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// readWebSocketFrame reads a client frame and returns its opcode and unmasked payload.
+func readWebSocketFrame(reader *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	opcode := header[0] & 0x0F
+	if header[1]&0x80 == 0 {
+		return 0, nil, errWebSocketUnmasked
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(reader, extended[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(reader, extended[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if length > maxWebSocketFrameSize {
+		return 0, nil, errWebSocketFrameLarge
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(reader, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return opcode, payload, nil
+}
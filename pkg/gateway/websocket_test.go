@@ -0,0 +1,229 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clientFrame returns a masked frame as sent by clients, using the extended length of the size.
+func clientFrame(opcode byte, payload []byte, extendedLength int) []byte {
+	frame := []byte{0x80 | opcode}
+
+	switch extendedLength {
+	case 0:
+		frame = append(frame, 0x80|byte(len(payload)))
+	case 2:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+
+	mask := []byte{0x12, 0x34, 0x56, 0x78}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+// readServerFrame reads an unmasked frame as sent by servers.
+func readServerFrame(reader *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+	if header[1]&0x80 != 0 {
+		return 0, nil, errors.New("server frame is masked")
+	}
+
+	length := uint64(header[1])
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(reader, extended[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(reader, extended[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	payload := make([]byte, length)
+	_, err := io.ReadFull(reader, payload)
+	return header[0] & 0x0F, payload, err
+}
+
+func TestWriteWebSocketFrame(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		header []byte
+	}{
+		{"empty", 0, []byte{0x81, 0}},
+		{"short", 125, []byte{0x81, 125}},
+		{"16 bit length", 126, []byte{0x81, 126, 0x00, 126}},
+		{"largest 16 bit length", 0xFFFF, []byte{0x81, 126, 0xFF, 0xFF}},
+		{"64 bit length", 0x10000, []byte{0x81, 127, 0, 0, 0, 0, 0, 0x01, 0x00, 0x00}},
+	}
+
+	for _, test := range tests {
+		payload := bytes.Repeat([]byte{'x'}, test.length)
+
+		var buffer bytes.Buffer
+		if err := writeWebSocketFrame(bufio.NewWriter(&buffer), opText, payload); err != nil {
+			t.Fatalf("%s: could not write: %v", test.name, err)
+		}
+
+		frame := buffer.Bytes()
+		if !bytes.Equal(frame[:len(test.header)], test.header) || !bytes.Equal(frame[len(test.header):], payload) {
+			t.Errorf("%s: got header % x, expected % x", test.name, frame[:len(test.header)], test.header)
+		}
+
+		opcode, read, err := readServerFrame(bufio.NewReader(&buffer))
+		if err != nil || opcode != opText || !bytes.Equal(read, payload) {
+			t.Errorf("%s: could not read frame back: %v", test.name, err)
+		}
+	}
+}
+
+func TestReadWebSocketFrame(t *testing.T) {
+	tests := []struct {
+		name   string
+		frame  []byte
+		opcode byte
+		length int
+		err    error
+	}{
+		{"ping", clientFrame(opPing, []byte("ping"), 0), opPing, 4, nil},
+		{"16 bit length", clientFrame(opText, bytes.Repeat([]byte{'x'}, 300), 2), opText, 300, nil},
+		{"64 bit length", clientFrame(opBinary, bytes.Repeat([]byte{'x'}, maxWebSocketFrameSize), 8), opBinary, maxWebSocketFrameSize, nil},
+		{"64 bit length of short frame", clientFrame(opClose, []byte{0x03, 0xE8}, 8), opClose, 2, nil},
+		{"too large", clientFrame(opBinary, bytes.Repeat([]byte{'x'}, maxWebSocketFrameSize+1), 8), 0, 0, errWebSocketFrameLarge},
+		{"unmasked", []byte{0x89, 0x00}, 0, 0, errWebSocketUnmasked},
+		{"truncated", clientFrame(opText, []byte("hello"), 0)[:8], 0, 0, io.ErrUnexpectedEOF},
+	}
+
+	for _, test := range tests {
+		opcode, payload, err := readWebSocketFrame(bufio.NewReader(bytes.NewReader(test.frame)))
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v, expected %v", test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if opcode != test.opcode || len(payload) != test.length {
+			t.Errorf("%s: got opcode %d with %d bytes, expected opcode %d with %d bytes", test.name, opcode, len(payload), test.opcode, test.length)
+		}
+		if test.name == "ping" && string(payload) != "ping" {
+			t.Errorf("%s: got %q, expected the unmasked payload", test.name, payload)
+		}
+	}
+}
+
+func TestWebSocketEndpoint(t *testing.T) {
+	bus := squadrcon.NewEventBus(squadrcon.EventBusSettings{})
+	feed := NewFeed(bus, FeedSettings{ReplaySize: 2})
+	defer feed.Close()
+
+	for i := 0; i < 4; i++ {
+		bus.Publish(squadrcon.TopicChat, squadrcon.ChatMessage{})
+	}
+	waitForFeed(t, feed, 4)
+
+	server := httptest.NewServer(NewHandler(newFakeRcon(), HandlerSettings{
+		Keys: []APIKey{{Name: "events", Key: "events-key", Scopes: []Scope{ScopeEvents}}},
+		Feed: feed,
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// Event 2 has been evicted, events 3 and 4 are replayed after the gap.
+	_, err = io.WriteString(conn, "GET /ws?after=1&key=events-key HTTP/1.1\r\n"+
+		"Host: gateway\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	if err != nil {
+		t.Fatalf("could not send handshake: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("could not read handshake response: %v", err)
+	}
+	// The accept value of the example handshake of RFC 6455.
+	if response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("got %s with headers %v, expected a completed handshake", response.Status, response.Header)
+	}
+
+	var gap FeedGap
+	if _, payload, err := readServerFrame(reader); err != nil || json.Unmarshal(payload, &gap) != nil {
+		t.Fatalf("could not read gap: %v", err)
+	}
+	if gap != (FeedGap{Kind: FeedKindGap, After: 1, Next: 3}) {
+		t.Errorf("got %+v, expected a gap after 1", gap)
+	}
+
+	for _, expected := range []uint64{3, 4} {
+		var event FeedEvent
+		if _, payload, err := readServerFrame(reader); err != nil || json.Unmarshal(payload, &event) != nil {
+			t.Fatalf("could not read event: %v", err)
+		}
+		if event.Kind != FeedKindEvent || event.Sequence != expected {
+			t.Errorf("got %+v, expected event %d", event, expected)
+		}
+	}
+
+	if _, err := conn.Write(clientFrame(opPing, []byte("are you there"), 0)); err != nil {
+		t.Fatalf("could not send ping: %v", err)
+	}
+	if opcode, payload, err := readServerFrame(reader); err != nil || opcode != opPong || string(payload) != "are you there" {
+		t.Errorf("got opcode %d with %q, %v, expected a pong", opcode, payload, err)
+	}
+
+	if _, err := conn.Write(clientFrame(opClose, nil, 0)); err != nil {
+		t.Fatalf("could not send close: %v", err)
+	}
+	if opcode, _, err := readServerFrame(reader); err != nil || opcode != opClose {
+		t.Errorf("got opcode %d, %v, expected a close frame", opcode, err)
+	}
+}
+
+func TestWebSocketEndpointRejectsPlainRequests(t *testing.T) {
+	bus := squadrcon.NewEventBus(squadrcon.EventBusSettings{})
+	feed := NewFeed(bus, FeedSettings{})
+	defer feed.Close()
+
+	handler := NewHandler(newFakeRcon(), HandlerSettings{Keys: testKeys, Feed: feed})
+	response := serve(handler, http.MethodGet, "/ws", "", bearer("admin-key"))
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "not a WebSocket handshake") {
+		t.Errorf("got %d %s, expected a bad request", response.Code, response.Body)
+	}
+}
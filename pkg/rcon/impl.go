@@ -4,14 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Packet type
 const (
 	serverDataResponseValue = 0
+	serverDataChatValue     = 1
 	serverDataExecCommand   = 2
 	serverDataAuthResponse  = 2
 	serverDataAuth          = 3
@@ -25,6 +28,7 @@ var (
 	ErrCommandEmpty      = errors.New("command is empty")
 	ErrIncorrectPassword = errors.New("RCON password is incorrect")
 	ErrNotAuthenticated  = errors.New("not authenticated")
+	ErrConnectionClosed  = errors.New("connection is closed")
 )
 
 type rconResponse struct {
@@ -63,6 +67,31 @@ type rconImpl struct {
 	// The next packet ID to use
 	execIdCounter int
 
+	// Called for messages pushed by the server. Optional.
+	onServerMessage func(message string)
+
+	// Called when the connection is lost. Optional.
+	onDisconnect func(err error)
+
+	// Receives debug output. Optional.
+	logger *log.Logger
+
+	// Receives every packet sent and received. Optional.
+	recorder *Recorder
+
+	// Called for every packet sent and received. Optional.
+	onPacketSent     func(id int32, packetType int32, body string)
+	onPacketReceived func(id int32, packetType int32, body string)
+
+	// executeCommand wrapped by the middleware.
+	execute ExecuteFunc
+
+	// Closed when packets are no longer read, after which no responses will arrive.
+	done chan struct{}
+
+	// True once Close is called.
+	closed atomic.Bool
+
 	// Lock needed before accessing execIdCounter.
 	idCounterLock sync.Mutex
 
@@ -75,6 +104,7 @@ type rconImpl struct {
 
 // Close closes the connection.
 func (r *rconImpl) Close() error {
+	r.closed.Store(true)
 	if err := r.conn.Close(); err != nil {
 		return err
 	}
@@ -83,32 +113,59 @@ func (r *rconImpl) Close() error {
 }
 
 func (r *rconImpl) Execute(command string) (string, error) {
+	return r.execute(command)
+}
+
+// executeCommand sends the command and waits for the response.
+func (r *rconImpl) executeCommand(command string) (string, error) {
 	if command == "" {
 		return "", ErrCommandEmpty
 	}
 
+	select {
+	case <-r.done:
+		return "", ErrConnectionClosed
+	default:
+	}
+
 	packetId := r.getNextId()
-	fmt.Printf("Executing command %s, ID: %d\n", command, packetId)
+	r.logf("Executing command %s, ID: %d", command, packetId)
+
+	// Registered before writing, the response may arrive before the writes return.
+	response := r.addCallback(packetId)
 
 	if err := r.write(serverDataExecCommand, packetId, command); err != nil {
+		r.removeCallback(packetId)
 		return "", err
 	}
 
 	// Send a short command with packetId + 1 after each command. When we receive the response to
 	// this command, we know that all the responses of the previous command have arrived.
 	if err := r.write(serverDataExecCommand, packetId+1, r.confirmationCommand); err != nil {
+		r.removeCallback(packetId)
 		return "", err
 	}
 
-	return <-r.addCallback(packetId), nil
+	select {
+	case body := <-response:
+		return body, nil
+	case <-r.done:
+		r.removeCallback(packetId)
+		return "", ErrConnectionClosed
+	}
 }
 
 func (r *rconImpl) start() {
 	go func() {
+		defer close(r.done)
+
 		for {
 			if err := r.handleIncomingPacket(); err != nil {
-				// todo, set up reconnect on error
-				fmt.Printf("Encountered an error processing packets. Stopping. %v\n", err)
+				r.logf("Encountered an error processing packets. Stopping. %v", err)
+
+				if !r.closed.Load() && r.onDisconnect != nil {
+					r.onDisconnect(err)
+				}
 				return
 			}
 		}
@@ -125,6 +182,9 @@ func (r *rconImpl) authenticate(password string) error {
 
 	emptyPacket := packet{}
 	_, err := emptyPacket.ReadFrom(r.conn)
+	if err == nil {
+		r.observe(DirectionReceived, &emptyPacket)
+	}
 
 	// Squad's RCON implementation closes the connection on failed authentication
 	switch {
@@ -136,7 +196,7 @@ func (r *rconImpl) authenticate(password string) error {
 		// Can happen when connection is closed by server due to inactivity
 		return err
 	case err != nil:
-		fmt.Println("Error reading from connection:", err)
+		r.logf("Error reading from connection: %v", err)
 		return err
 	}
 
@@ -146,6 +206,7 @@ func (r *rconImpl) authenticate(password string) error {
 	if err != nil {
 		return err
 	}
+	r.observe(DirectionReceived, &authResultPacket)
 
 	if authResultPacket.Id != packetId {
 		return fmt.Errorf("unexpected ID in auth response. Got %d, expected %d", authResultPacket.Id, packetId)
@@ -156,7 +217,8 @@ func (r *rconImpl) authenticate(password string) error {
 }
 
 func (r *rconImpl) addCallback(id int32) chan string {
-	channel := make(chan string)
+	// Buffered so that the packet reader does not block when Execute has given up waiting.
+	channel := make(chan string, 1)
 	r.callbackLock.Lock()
 	r.callbacks[id] = &callback{
 		Channel: channel,
@@ -173,7 +235,7 @@ func (r *rconImpl) handleIncomingPacket() error {
 		return ErrNotAuthenticated
 	}
 
-	fmt.Printf("Trying to read packet\n")
+	r.logf("Trying to read packet")
 	packet := packet{}
 	_, err := packet.ReadFrom(r.conn)
 
@@ -187,14 +249,24 @@ func (r *rconImpl) handleIncomingPacket() error {
 		return err
 	}
 
-	fmt.Printf(
-		"Packet received; Id: %d, Type: %d, Body size: %d, Body: %s\n",
+	r.observe(DirectionReceived, &packet)
+
+	r.logf(
+		"Packet received; Id: %d, Type: %d, Body size: %d, Body: %s",
 		packet.Id,
 		packet.Type,
 		packet.GetBodySize(),
 		packet.GetBody(),
 	)
 
+	// Messages pushed by the server, such as chat, are not part of a response.
+	if packet.Type == serverDataChatValue {
+		if r.onServerMessage != nil {
+			r.onServerMessage(packet.GetBody())
+		}
+		return nil
+	}
+
 	// Completion packets, identified by odd IDs, signal completeness for responses with
 	// ID - 1. E.g.:
 	// When receiving a packet with an even ID, we append the data to the callbacks[ID].Data.
@@ -209,7 +281,7 @@ func (r *rconImpl) handleIncomingPacket() error {
 	r.callbackLock.Lock()
 	callback, exists := r.callbacks[callbackId]
 	if !exists {
-		fmt.Printf("Callback for ID %d not registered\n", packet.Id)
+		r.logf("Callback for ID %d not registered", packet.Id)
 		r.callbackLock.Unlock()
 		return nil
 	}
@@ -217,7 +289,7 @@ func (r *rconImpl) handleIncomingPacket() error {
 	if isCompletionPacket {
 		callback.Channel <- string(callback.Data)
 		close(callback.Channel)
-		delete(r.callbacks, packet.Id)
+		delete(r.callbacks, callbackId)
 	} else {
 		callback.Data = append(callback.Data, packet.Body...)
 	}
@@ -226,6 +298,18 @@ func (r *rconImpl) handleIncomingPacket() error {
 	return nil
 }
 
+func (r *rconImpl) removeCallback(id int32) {
+	r.callbackLock.Lock()
+	delete(r.callbacks, id)
+	r.callbackLock.Unlock()
+}
+
+func (r *rconImpl) logf(format string, args ...any) {
+	if r.logger != nil {
+		r.logger.Printf(format, args...)
+	}
+}
+
 func (r *rconImpl) write(packetType int32, packetId int32, command string) error {
 	if r.writeTimeout != 0 {
 		if err := r.conn.SetWriteDeadline(time.Now().Add(r.writeTimeout)); err != nil {
@@ -234,11 +318,35 @@ func (r *rconImpl) write(packetType int32, packetId int32, command string) error
 	}
 
 	packet := newPacket(packetType, packetId, command)
+
+	// Observed before writing, so the response cannot be observed before the packet.
+	r.observe(DirectionSent, packet)
+
 	_, err := packet.WriteTo(r.conn)
 
 	return err
 }
 
+// observe passes the packet to the recorder and the packet hooks.
+func (r *rconImpl) observe(direction Direction, packet *packet) {
+	if r.recorder != nil {
+		if err := r.recorder.recordPacket(direction, packet); err != nil {
+			r.logf("Failed to record packet: %v", err)
+		}
+	}
+
+	switch {
+	case direction == DirectionSent && r.onPacketSent != nil:
+		body := packet.GetBody()
+		if packet.Type == serverDataAuth {
+			body = ""
+		}
+		r.onPacketSent(packet.Id, packet.Type, body)
+	case direction == DirectionReceived && r.onPacketReceived != nil:
+		r.onPacketReceived(packet.Id, packet.Type, packet.GetBody())
+	}
+}
+
 // getNextId returns the next packet ID.
 // Will always return even numbers.
 // The returned number will be between startId and startId + wrapIdsAfter.
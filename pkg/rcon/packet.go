@@ -67,14 +67,11 @@ func (packet *packet) ReadFrom(r io.Reader) (int64, error) {
 		}
 	}
 
-	fmt.Printf("Packet size %v\n", packet.Size)
-
 	if packet.Size > maxPacketSize {
-		panic(fmt.Errorf("packet size too large, %d", packet.Size))
-		//return reader.TotalBytesRead, &packetParseError{
-		//	Err:         fmt.Errorf("packet size too large, %d", packet.Size),
-		//	PacketBytes: reader.Bytes,
-		//}
+		return reader.TotalBytesRead, &packetParseError{
+			Err:         fmt.Errorf("packet size too large, %d", packet.Size),
+			PacketBytes: reader.Bytes,
+		}
 	}
 
 	if err := binary.Read(reader, binary.LittleEndian, &packet.Id); err != nil {
@@ -144,8 +141,6 @@ func (packet *packet) ReadFrom(r io.Reader) (int64, error) {
 		}
 	}
 
-	fmt.Printf("Packet read: %v\n", reader.Bytes)
-
 	return reader.TotalBytesRead, nil
 }
 
@@ -0,0 +1,300 @@
+package rcon
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrProxyClosed = errors.New("proxy is closed")
+
+	errProxyNotConnected = errors.New("proxy is not connected to the server")
+)
+
+const (
+	defaultProxyReconnectInterval = 5 * time.Second
+
+	// The confirmation command used by the squadrcon package, as the proxy is meant for Squad.
+	defaultProxyConfirmationCommand = "ShowCurrentMap"
+)
+
+// ProxyClient is a tool allowed to connect to the proxy, e.g. SquadJS.
+type ProxyClient struct {
+	// Name identifies the client in errors.
+	Name string
+
+	// Password the client authenticates with. Passwords must be unique, the password identifies
+	// the client.
+	Password string
+
+	// AllowedCommands contains the commands the client may execute, compared case-insensitively
+	// to the first word of the command. All commands are allowed if empty.
+	AllowedCommands []string
+}
+
+// allows returns whether the client may execute the command.
+func (c ProxyClient) allows(command string) bool {
+	if len(c.AllowedCommands) == 0 {
+		return true
+	}
+
+	name, _, _ := strings.Cut(strings.TrimSpace(command), " ")
+	for _, allowed := range c.AllowedCommands {
+		if strings.EqualFold(allowed, name) {
+			return true
+		}
+	}
+	return false
+}
+
+type ProxySettings struct {
+	// Upstream configures the connection to the server. Its OnServerMessage and OnDisconnect are
+	// called in addition to the handling of the proxy. Its ConfirmationCommand defaults to
+	// `ShowCurrentMap`.
+	Upstream Settings
+
+	Clients []ProxyClient
+
+	// ReconnectInterval is the time between attempts to reconnect to the server after the
+	// connection is lost. Defaults to 5 seconds.
+	ReconnectInterval time.Duration
+
+	// WriteTimeout is the time to wait for writing to a client before disconnecting it. Defaults
+	// to 5 seconds.
+	WriteTimeout time.Duration
+
+	// OnError is called when reconnecting fails or a client connection fails. Optional.
+	OnError func(err error)
+}
+
+// Proxy shares a single connection to the server between multiple RCON clients. Commands of the
+// clients are executed on the shared connection, which assigns its own packet IDs, and the
+// responses are returned using the packet IDs chosen by the clients. Messages pushed by the server,
+// such as chat, are sent to all clients.
+//
+// While the connection to the server is lost, clients are disconnected and new clients are
+// rejected until the proxy has reconnected.
+type Proxy struct {
+	address  string
+	password string
+	settings ProxySettings
+
+	// Serves the clients.
+	server *Server
+
+	// SHA-256 digests of the client passwords, compared in constant time.
+	passwordDigests [][sha256.Size]byte
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// Nil while disconnected.
+	upstream Rcon
+
+	// Closed when the proxy is closed.
+	done chan struct{}
+
+	closed bool
+}
+
+// proxyHandler handles the clients of the proxy, keeping the ServerHandler methods out of the API
+// of Proxy.
+type proxyHandler struct {
+	proxy *Proxy
+}
+
+// NewProxy connects to the server. Clients are accepted once Serve is called.
+func NewProxy(address string, password string, settings ProxySettings) (*Proxy, error) {
+	if settings.ReconnectInterval <= 0 {
+		settings.ReconnectInterval = defaultProxyReconnectInterval
+	}
+	if settings.Upstream.ConfirmationCommand == "" {
+		settings.Upstream.ConfirmationCommand = defaultProxyConfirmationCommand
+	}
+
+	p := &Proxy{
+		address:  address,
+		password: password,
+		settings: settings,
+		done:     make(chan struct{}),
+	}
+	for _, client := range settings.Clients {
+		p.passwordDigests = append(p.passwordDigests, sha256.Sum256([]byte(client.Password)))
+	}
+	p.server = NewServer(proxyHandler{proxy: p}, ServerSettings{
+		WriteTimeout: settings.WriteTimeout,
+		OnError:      settings.OnError,
+	})
+
+	upstream, err := p.connect()
+	if err != nil {
+		return nil, err
+	}
+	p.upstream = upstream
+
+	return p, nil
+}
+
+// ListenAndServe listens on the TCP address and serves clients until the proxy is closed.
+func (p *Proxy) ListenAndServe(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return p.Serve(listener)
+}
+
+// Serve accepts clients on the listener until the proxy is closed, in which case ErrProxyClosed is
+// returned. The listener is closed when Serve returns.
+func (p *Proxy) Serve(listener net.Listener) error {
+	err := p.server.Serve(listener)
+	if errors.Is(err, ErrServerClosed) {
+		return ErrProxyClosed
+	}
+	return err
+}
+
+// Close stops serving, disconnects all clients and closes the connection to the server.
+func (p *Proxy) Close() error {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+
+	upstream := p.upstream
+	p.upstream = nil
+	p.lock.Unlock()
+
+	_ = p.server.Close()
+
+	if upstream == nil {
+		return nil
+	}
+	return upstream.Close()
+}
+
+func (p *Proxy) connect() (Rcon, error) {
+	settings := p.settings.Upstream
+	settings.OnServerMessage = func(message string) {
+		p.server.Broadcast(message)
+		if p.settings.Upstream.OnServerMessage != nil {
+			p.settings.Upstream.OnServerMessage(message)
+		}
+	}
+	settings.OnDisconnect = func(err error) {
+		p.upstreamLost()
+		if p.settings.Upstream.OnDisconnect != nil {
+			p.settings.Upstream.OnDisconnect(err)
+		}
+	}
+
+	return Connect(p.address, p.password, settings)
+}
+
+// upstreamLost disconnects all clients and reconnects in the background.
+func (p *Proxy) upstreamLost() {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return
+	}
+	p.upstream = nil
+	p.lock.Unlock()
+
+	for _, session := range p.server.Sessions() {
+		_ = session.Close()
+	}
+
+	go p.reconnect()
+}
+
+func (p *Proxy) reconnect() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-time.After(p.settings.ReconnectInterval):
+		}
+
+		upstream, err := p.connect()
+		if err != nil {
+			p.reportError(fmt.Errorf("failed to reconnect to %s: %w", p.address, err))
+			continue
+		}
+
+		p.lock.Lock()
+		if p.closed {
+			p.lock.Unlock()
+			_ = upstream.Close()
+			return
+		}
+		p.upstream = upstream
+		p.lock.Unlock()
+		return
+	}
+}
+
+func (p *Proxy) getUpstream() Rcon {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.upstream
+}
+
+func (p *Proxy) reportError(err error) {
+	if p.settings.OnError != nil {
+		p.settings.OnError(err)
+	}
+}
+
+// Authenticate finds the client with the password. Clients are rejected like with an incorrect
+// password while the proxy is not connected to the server, so that they retry later.
+func (h proxyHandler) Authenticate(session *ServerSession, password string) error {
+	if h.proxy.getUpstream() == nil {
+		return errProxyNotConnected
+	}
+
+	digest := sha256.Sum256([]byte(password))
+	match := -1
+	for i := range h.proxy.passwordDigests {
+		if subtle.ConstantTimeCompare(digest[:], h.proxy.passwordDigests[i][:]) == 1 {
+			match = i
+		}
+	}
+
+	if match < 0 {
+		return ErrIncorrectPassword
+	}
+	session.Value = h.proxy.settings.Clients[match]
+	return nil
+}
+
+// Execute executes the command on the shared connection, unless the client is not allowed to.
+func (h proxyHandler) Execute(session *ServerSession, command string) (string, error) {
+	client := session.Value.(ProxyClient)
+	if !client.allows(command) {
+		name, _, _ := strings.Cut(strings.TrimSpace(command), " ")
+		return fmt.Sprintf("Command %s is not allowed for this client", name), nil
+	}
+
+	upstream := h.proxy.getUpstream()
+	if upstream == nil {
+		return "", errProxyNotConnected
+	}
+
+	response, err := upstream.Execute(command)
+	if err != nil {
+		return "", fmt.Errorf("client %s: %w", client.Name, err)
+	}
+	return response, nil
+}
@@ -0,0 +1,175 @@
+package rcon
+
+import (
+	"net"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingHandler echoes commands like echoHandler and records them.
+type recordingHandler struct {
+	lock     sync.Mutex
+	commands []string
+}
+
+func (h *recordingHandler) Authenticate(session *ServerSession, password string) error {
+	if password != "upstream" {
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
+func (h *recordingHandler) Execute(session *ServerSession, command string) (string, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.commands = append(h.commands, command)
+	return "executed " + command, nil
+}
+
+func (h *recordingHandler) Commands() []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return append([]string(nil), h.commands...)
+}
+
+// listen serves on a random local port and returns its address.
+func listen(t *testing.T, serve func(listener net.Listener) error) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	go func() {
+		_ = serve(listener)
+	}()
+
+	return listener.Addr().String()
+}
+
+// dialProxy connects and authenticates a client using the auth packet ID 1.
+func dialProxy(t *testing.T, address string, password string) net.Conn {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if bodies := exchange(t, conn, newPacket(serverDataAuth, 1, password), 2); !reflect.DeepEqual(bodies, []string{"", ""}) {
+		t.Fatalf("got %q, expected an auth response", bodies)
+	}
+	return conn
+}
+
+// readPacket reads the next packet sent to the client.
+func readPacket(t *testing.T, conn net.Conn) packet {
+	t.Helper()
+
+	received := packet{}
+	if _, err := received.ReadFrom(conn); err != nil {
+		t.Fatalf("could not read packet: %v", err)
+	}
+	return received
+}
+
+func TestProxy(t *testing.T) {
+	handler := &recordingHandler{}
+	upstream := NewServer(handler, ServerSettings{ConfirmationCommands: []string{"ShowCurrentMap"}})
+	defer upstream.Close()
+	upstreamAddress := listen(t, upstream.Serve)
+
+	proxy, err := NewProxy(upstreamAddress, "upstream", ProxySettings{
+		Clients: []ProxyClient{
+			{Name: "squadjs", Password: "first"},
+			{Name: "bot", Password: "second", AllowedCommands: []string{"ListPlayers", "AdminWarn"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not connect the proxy: %v", err)
+	}
+	defer proxy.Close()
+	proxyAddress := listen(t, proxy.Serve)
+
+	first := dialProxy(t, proxyAddress, "first")
+	defer first.Close()
+	second := dialProxy(t, proxyAddress, "second")
+	defer second.Close()
+
+	// Both clients use the same packet ID, each must receive its own response.
+	if _, err := newPacket(serverDataExecCommand, 10, "ListSquads").WriteTo(first); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+	if _, err := newPacket(serverDataExecCommand, 10, "ListPlayers").WriteTo(second); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+
+	if response := readPacket(t, first); response.Id != 10 || response.GetBody() != "executed ListSquads" {
+		t.Errorf("first client got %d %q, expected the response to ListSquads", response.Id, response.GetBody())
+	}
+	if response := readPacket(t, second); response.Id != 10 || response.GetBody() != "executed ListPlayers" {
+		t.Errorf("second client got %d %q, expected the response to ListPlayers", response.Id, response.GetBody())
+	}
+
+	// Commands outside AllowedCommands are answered by the proxy without reaching the server.
+	bodies := exchange(t, second, newPacket(serverDataExecCommand, 11, "AdminKick 76561197999957991"), 1)
+	if !reflect.DeepEqual(bodies, []string{"Command AdminKick is not allowed for this client"}) {
+		t.Errorf("got %q, expected the command to be rejected", bodies)
+	}
+	if bodies := exchange(t, second, newPacket(serverDataExecCommand, 12, "adminwarn Jon hello"), 1); !reflect.DeepEqual(bodies, []string{"executed adminwarn Jon hello"}) {
+		t.Errorf("got %q, expected allowed commands to be compared case-insensitively", bodies)
+	}
+
+	// The commands of both clients may reach the server in any order.
+	expected := []string{"ListPlayers", "ListSquads", "adminwarn Jon hello"}
+	commands := handler.Commands()
+	sort.Strings(commands)
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("server got %q, expected %q", commands, expected)
+	}
+
+	// Messages pushed by the server are sent to all clients as chat packets.
+	upstream.Broadcast("[ChatAll] [Online IDs:EOS: 0002a10386f3487ba4b12b5e5f6a6a1a steam: 76561197999957991] Jon : hello")
+	for _, conn := range []net.Conn{first, second} {
+		message := readPacket(t, conn)
+		if message.Type != serverDataChatValue || message.GetBody() != "[ChatAll] [Online IDs:EOS: 0002a10386f3487ba4b12b5e5f6a6a1a steam: 76561197999957991] Jon : hello" {
+			t.Errorf("got packet of type %d %q, expected the chat message", message.Type, message.GetBody())
+		}
+	}
+}
+
+func TestProxyRejectsUnknownPasswords(t *testing.T) {
+	upstream := NewServer(&recordingHandler{}, ServerSettings{ConfirmationCommands: []string{"ShowCurrentMap"}})
+	defer upstream.Close()
+	upstreamAddress := listen(t, upstream.Serve)
+
+	proxy, err := NewProxy(upstreamAddress, "upstream", ProxySettings{
+		Clients: []ProxyClient{{Name: "squadjs", Password: "first"}},
+	})
+	if err != nil {
+		t.Fatalf("could not connect the proxy: %v", err)
+	}
+	defer proxy.Close()
+	proxyAddress := listen(t, proxy.Serve)
+
+	conn, err := net.Dial("tcp", proxyAddress)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := newPacket(serverDataAuth, 1, "upstream").WriteTo(conn); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+	// Clients with unknown passwords are disconnected, even with the password of the server.
+	if _, err := (&packet{}).ReadFrom(conn); err == nil {
+		t.Error("expected the connection to be closed")
+	}
+}
@@ -2,6 +2,7 @@ package rcon
 
 import (
 	"fmt"
+	"log"
 	"net"
 	"time"
 )
@@ -11,6 +12,22 @@ type Rcon interface {
 	Execute(command string) (string, error)
 }
 
+// Packet types, as passed to Settings.OnPacketSent and Settings.OnPacketReceived.
+const (
+	PacketTypeResponseValue int32 = serverDataResponseValue
+	PacketTypeChatValue     int32 = serverDataChatValue
+	PacketTypeExecCommand   int32 = serverDataExecCommand
+	PacketTypeAuthResponse  int32 = serverDataAuthResponse
+	PacketTypeAuth          int32 = serverDataAuth
+)
+
+// ExecuteFunc executes a command, see Rcon.Execute.
+type ExecuteFunc func(command string) (string, error)
+
+// Middleware wraps Execute, e.g. to trace, audit or rewrite commands, or to reject commands
+// without sending them by returning an error instead of calling next.
+type Middleware func(next ExecuteFunc) ExecuteFunc
+
 type Settings struct {
 	// The RCON command that is sent after every Execute.
 	// Used to detect whether all responses to the execute command are received.
@@ -19,23 +36,73 @@ type Settings struct {
 
 	DialTimeout time.Duration
 
+	// Logger receives debug output about the packets sent and received. Optional.
+	Logger *log.Logger
+
+	// Middleware wraps Execute. The first middleware is the outermost, it is called first and
+	// returns last. Optional.
+	Middleware []Middleware
+
+	// OnDisconnect is called when the connection is lost, but not when it is closed using Close.
+	// Pending and later calls to Execute fail with ErrConnectionClosed. Optional.
+	OnDisconnect func(err error)
+
+	// OnPacketReceived is called for every packet received, including the packets of responses
+	// and pushed messages. It is called from the goroutine that reads packets, which means that it
+	// must not block and must not call Execute. Optional.
+	OnPacketReceived func(id int32, packetType int32, body string)
+
+	// OnPacketSent is called for every packet before it is sent, including confirmation
+	// commands. The body of auth packets, the password, is passed as empty string. It must not
+	// block. Optional.
+	OnPacketSent func(id int32, packetType int32, body string)
+
+	// OnServerMessage is called for every message pushed by the server that is not a response to
+	// a command, e.g. chat messages. It is called from the goroutine that reads packets, which
+	// means that it must not block and must not call Execute.
+	OnServerMessage func(message string)
+
 	// PacketIdStart contains the first packet ID that will be used. Change it when multiple rcon
 	// connections are used. E.g. SquadJS uses ID 1 and 2, so these IDs shouldn't be used to prevent
 	// conflicts.
 	PacketIdStart int32
 
+	// Recorder receives every packet sent and received, e.g. to replay the traffic later using
+	// NewReplayConn. Optional.
+	Recorder *Recorder
+
 	WriteTimeout time.Duration
 }
 
 // Connect connects to the RCON server and authenticates.
 func Connect(address string, password string, settings Settings) (Rcon, error) {
+	conn, err := net.DialTimeout("tcp", address, settings.DialTimeout)
+	if err != nil {
+		// Failed to open TCP connection to the server.
+		return nil, fmt.Errorf("failed to connect to rcon server on %s: %w", address, err)
+	}
+
+	return ConnectConn(conn, password, settings)
+}
+
+// ConnectConn authenticates on an established connection, e.g. a connection returned by
+// NewReplayConn. The connection is closed when authentication fails.
+func ConnectConn(conn net.Conn, password string, settings Settings) (Rcon, error) {
 	client := &rconImpl{
 		confirmationCommand: settings.ConfirmationCommand,
 		dialTimeout:         5 * time.Second,
 		writeTimeout:        5 * time.Second,
 		callbacks:           make(map[int32]*callback),
 		execIdCounter:       10000,
+		onServerMessage:     settings.OnServerMessage,
+		onDisconnect:        settings.OnDisconnect,
+		logger:              settings.Logger,
+		recorder:            settings.Recorder,
+		onPacketSent:        settings.OnPacketSent,
+		onPacketReceived:    settings.OnPacketReceived,
+		done:                make(chan struct{}),
 		startId:             10000,
+		conn:                conn,
 	}
 
 	if settings.DialTimeout > 0 {
@@ -46,12 +113,10 @@ func Connect(address string, password string, settings Settings) (Rcon, error) {
 		client.writeTimeout = settings.WriteTimeout
 	}
 
-	conn, err := net.DialTimeout("tcp", address, settings.DialTimeout)
-	if err != nil {
-		// Failed to open TCP connection to the server.
-		return nil, fmt.Errorf("failed to connect to rcon server on %s: %w", address, err)
+	client.execute = client.executeCommand
+	for i := len(settings.Middleware) - 1; i >= 0; i-- {
+		client.execute = settings.Middleware[i](client.execute)
 	}
-	client.conn = conn
 
 	if err := client.authenticate(password); err != nil {
 		if err2 := client.Close(); err2 != nil {
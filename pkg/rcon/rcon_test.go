@@ -0,0 +1,172 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// packetLog records the packets passed to OnPacketSent and OnPacketReceived.
+type packetLog struct {
+	lock    sync.Mutex
+	packets []string
+}
+
+func (l *packetLog) hook(direction string) func(id int32, packetType int32, body string) {
+	return func(id int32, packetType int32, body string) {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		l.packets = append(l.packets, fmt.Sprintf("%s %d %d %q", direction, id, packetType, body))
+	}
+}
+
+// take returns and clears the recorded packets.
+func (l *packetLog) take() []string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	packets := l.packets
+	l.packets = nil
+	return packets
+}
+
+// connectTest connects to a server using handler over a pipe.
+func connectTest(t *testing.T, handler ServerHandler, settings Settings) Rcon {
+	t.Helper()
+
+	server := NewServer(handler, ServerSettings{ConfirmationCommands: []string{"ShowCurrentMap"}})
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+
+	client, conn := net.Pipe()
+	go server.ServeConn(conn)
+
+	settings.ConfirmationCommand = "ShowCurrentMap"
+	rc, err := ConnectConn(client, "upstream", settings)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = rc.Close()
+	})
+
+	return rc
+}
+
+func TestPacketHooks(t *testing.T) {
+	observed := &packetLog{}
+	rc := connectTest(t, &recordingHandler{}, Settings{
+		OnPacketSent:     observed.hook("sent"),
+		OnPacketReceived: observed.hook("received"),
+	})
+
+	// The password is not passed to the hook.
+	expected := []string{
+		`sent 10000 3 ""`,
+		`received 10000 0 ""`,
+		`received 10000 2 ""`,
+	}
+	if packets := observed.take(); !reflect.DeepEqual(packets, expected) {
+		t.Errorf("got %q, expected the auth packets %q", packets, expected)
+	}
+
+	if response, err := rc.Execute("ListSquads"); err != nil || response != "executed ListSquads" {
+		t.Fatalf("got %q, %v, expected the response", response, err)
+	}
+
+	// The response is observed before Execute returns, including the confirmation.
+	expected = []string{
+		`sent 10002 2 "ListSquads"`,
+		`sent 10003 2 "ShowCurrentMap"`,
+		`received 10002 0 "executed ListSquads"`,
+		`received 10003 0 ""`,
+	}
+	if packets := observed.take(); !reflect.DeepEqual(packets, expected) {
+		t.Errorf("got %q, expected the command packets %q", packets, expected)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var trace []string
+	record := func(entry string) {
+		trace = append(trace, entry)
+	}
+
+	// tracing records the command passed to it and the response returned to it.
+	tracing := func(name string) Middleware {
+		return func(next ExecuteFunc) ExecuteFunc {
+			return func(command string) (string, error) {
+				record(name + " " + command)
+				response, err := next(command)
+				record(name + " returned " + response)
+				return response, err
+			}
+		}
+	}
+
+	errNotAllowed := errors.New("command is not allowed")
+	allowlist := func(next ExecuteFunc) ExecuteFunc {
+		return func(command string) (string, error) {
+			if !strings.HasPrefix(command, "List") {
+				return "", errNotAllowed
+			}
+			return next(command)
+		}
+	}
+
+	aliases := func(next ExecuteFunc) ExecuteFunc {
+		return func(command string) (string, error) {
+			if command == "players" {
+				command = "ListPlayers"
+			}
+			response, err := next(command)
+			return strings.ToUpper(response), err
+		}
+	}
+
+	handler := &recordingHandler{}
+	observed := &packetLog{}
+	rc := connectTest(t, handler, Settings{
+		Middleware:   []Middleware{tracing("outer"), aliases, allowlist, tracing("inner")},
+		OnPacketSent: observed.hook("sent"),
+	})
+	observed.take()
+
+	// The first middleware is called first and returns last, rewrites apply to the later ones.
+	response, err := rc.Execute("players")
+	if err != nil || response != "EXECUTED LISTPLAYERS" {
+		t.Errorf("got %q, %v, expected the rewritten response", response, err)
+	}
+	expected := []string{
+		"outer players",
+		"inner ListPlayers",
+		"inner returned executed ListPlayers",
+		"outer returned EXECUTED LISTPLAYERS",
+	}
+	if !reflect.DeepEqual(trace, expected) {
+		t.Errorf("got %q, expected %q", trace, expected)
+	}
+
+	// Rejected commands do not reach the later middleware or the server.
+	trace = nil
+	if _, err := rc.Execute("AdminKick 76561197999957991"); !errors.Is(err, errNotAllowed) {
+		t.Errorf("got %v, expected %v", err, errNotAllowed)
+	}
+	expected = []string{"outer AdminKick 76561197999957991", "outer returned "}
+	if !reflect.DeepEqual(trace, expected) {
+		t.Errorf("got %q, expected %q", trace, expected)
+	}
+
+	if commands := handler.Commands(); !reflect.DeepEqual(commands, []string{"ListPlayers"}) {
+		t.Errorf("server got %q, expected only the rewritten command", commands)
+	}
+	if packets := observed.take(); len(packets) != 2 {
+		t.Errorf("got %q, expected only the packets of the allowed command", packets)
+	}
+}
@@ -0,0 +1,313 @@
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrReplayMismatch = errors.New("sent packet does not match the recording")
+)
+
+// Direction tells whether a recorded packet was sent or received by the client.
+type Direction string
+
+const (
+	DirectionSent     Direction = "sent"
+	DirectionReceived Direction = "received"
+)
+
+// RecordedPacket is a line of a recording.
+type RecordedPacket struct {
+	Time      time.Time
+	Direction Direction
+	Id        int32
+	Type      int32
+	Body      string
+}
+
+// Recorder writes the packets of a connection as JSON Lines, one RecordedPacket per line. The
+// body of auth packets, the password, is not recorded. It is safe for concurrent use.
+type Recorder struct {
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	writer  io.Writer
+	encoder *json.Encoder
+}
+
+// NewRecorder returns a recorder writing to writer.
+func NewRecorder(writer io.Writer) *Recorder {
+	return &Recorder{
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+// CreateRecording returns a recorder writing to the file, which is truncated if it exists.
+func CreateRecording(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRecorder(file), nil
+}
+
+// Record writes the packet.
+func (r *Recorder) Record(packet RecordedPacket) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.encoder.Encode(packet)
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if closer, ok := r.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (r *Recorder) recordPacket(direction Direction, packet *packet) error {
+	body := packet.GetBody()
+	if direction == DirectionSent && packet.Type == serverDataAuth {
+		body = ""
+	}
+
+	return r.Record(RecordedPacket{
+		Time:      time.Now(),
+		Direction: direction,
+		Id:        packet.Id,
+		Type:      packet.Type,
+		Body:      body,
+	})
+}
+
+// ReadRecording reads a recording written by Recorder.
+func ReadRecording(path string) ([]RecordedPacket, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseRecording(file)
+}
+
+// ParseRecording parses a recording written by Recorder. Empty lines are skipped.
+func ParseRecording(reader io.Reader) ([]RecordedPacket, error) {
+	var packets []RecordedPacket
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var packet RecordedPacket
+		if err := json.Unmarshal(scanner.Bytes(), &packet); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if packet.Direction != DirectionSent && packet.Direction != DirectionReceived {
+			return nil, fmt.Errorf("line %d: unknown direction %q", line, packet.Direction)
+		}
+
+		packets = append(packets, packet)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return packets, nil
+}
+
+// replayConn is a connection that plays back a recording, see NewReplayConn.
+type replayConn struct {
+	packets []RecordedPacket
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// Signalled when readable data is added or the connection is closed.
+	readable *sync.Cond
+
+	// Index of the next packet of the recording.
+	next int
+
+	// Received packets that were released, but not read yet.
+	readBuffer bytes.Buffer
+
+	// Written bytes that do not form a complete packet yet.
+	writeBuffer bytes.Buffer
+
+	// Maps the IDs of the sent packets of the recording to the IDs used by the client.
+	ids map[int32]int32
+
+	closed bool
+}
+
+// NewReplayConn returns a connection that plays back the recording to a client, e.g. using
+// ConnectConn. Received packets are released in order once the client has sent the packets
+// preceding them in the recording, so chat interleaved with responses is reproduced exactly,
+// regardless of timing.
+//
+// Sent packets must match the recording in type and body, otherwise the write fails with
+// ErrReplayMismatch. Their IDs may differ, the IDs of received packets are rewritten
+// accordingly. Reads fail with io.EOF once all received packets have been read.
+func NewReplayConn(packets []RecordedPacket) net.Conn {
+	c := &replayConn{
+		packets: packets,
+		ids:     make(map[int32]int32),
+	}
+	c.readable = sync.NewCond(&c.lock)
+	c.release()
+
+	return c
+}
+
+func (c *replayConn) Read(b []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for c.readBuffer.Len() == 0 {
+		switch {
+		case c.closed:
+			return 0, net.ErrClosed
+		case c.next >= len(c.packets):
+			return 0, io.EOF
+		}
+		c.readable.Wait()
+	}
+
+	return c.readBuffer.Read(b)
+}
+
+func (c *replayConn) Write(b []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+
+	c.writeBuffer.Write(b)
+	for {
+		// Packets are prefixed with their size, which does not include the size field itself.
+		if c.writeBuffer.Len() < 4 {
+			break
+		}
+		size := int(binary.LittleEndian.Uint32(c.writeBuffer.Bytes()))
+		if c.writeBuffer.Len() < 4+size {
+			break
+		}
+
+		sent := packet{}
+		if _, err := sent.ReadFrom(&c.writeBuffer); err != nil {
+			return 0, err
+		}
+		if err := c.send(&sent); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// send matches the packet against the next packet of the recording and releases the received
+// packets following it.
+func (c *replayConn) send(sent *packet) error {
+	if c.next >= len(c.packets) {
+		return fmt.Errorf("%w: recording ended, got type %d %q", ErrReplayMismatch, sent.Type, sent.GetBody())
+	}
+
+	recorded := c.packets[c.next]
+	bodyMatches := recorded.Body == sent.GetBody() || sent.Type == serverDataAuth
+	if recorded.Type != sent.Type || !bodyMatches {
+		return fmt.Errorf(
+			"%w: got type %d %q, expected type %d %q",
+			ErrReplayMismatch,
+			sent.Type,
+			sent.GetBody(),
+			recorded.Type,
+			recorded.Body,
+		)
+	}
+
+	c.ids[recorded.Id] = sent.Id
+	c.next++
+	c.release()
+	return nil
+}
+
+// release moves the received packets up to the next sent packet into the read buffer.
+func (c *replayConn) release() {
+	for c.next < len(c.packets) && c.packets[c.next].Direction == DirectionReceived {
+		recorded := c.packets[c.next]
+
+		id := recorded.Id
+		if mapped, exists := c.ids[id]; exists {
+			id = mapped
+		}
+
+		_, _ = newPacket(recorded.Type, id, recorded.Body).WriteTo(&c.readBuffer)
+		c.next++
+	}
+
+	c.readable.Broadcast()
+}
+
+func (c *replayConn) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed = true
+	c.readable.Broadcast()
+	return nil
+}
+
+func (c *replayConn) LocalAddr() net.Addr {
+	return replayAddr{}
+}
+
+func (c *replayConn) RemoteAddr() net.Addr {
+	return replayAddr{}
+}
+
+// Deadlines are not supported, reads and writes never wait for the network.
+func (c *replayConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *replayConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *replayConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+type replayAddr struct{}
+
+func (replayAddr) Network() string {
+	return "replay"
+}
+
+func (replayAddr) String() string {
+	return "replay"
+}
@@ -0,0 +1,402 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrServerClosed = errors.New("server is closed")
+)
+
+const (
+	defaultServerWriteTimeout = 5 * time.Second
+	defaultServerQueueSize    = 256
+
+	// The largest body that fits into a packet of maxPacketSize.
+	maxPacketBodySize = maxPacketSize - int(PacketHeaderSize) - int(PacketAmountOfNullTerminators)
+)
+
+// ServerHandler handles the connections of a Server.
+type ServerHandler interface {
+	// Authenticate is called with the password sent by a new connection. Returning an error
+	// rejects the connection, which is closed without response, as Squad does for incorrect
+	// passwords.
+	Authenticate(session *ServerSession, password string) error
+
+	// Execute returns the response to a command of an authenticated session. Commands of a session
+	// are executed one at a time. Returning an error closes the session.
+	Execute(session *ServerSession, command string) (string, error)
+}
+
+type ServerSettings struct {
+	// WriteTimeout is the time to wait for writing to a client before disconnecting it. Defaults
+	// to 5 seconds.
+	WriteTimeout time.Duration
+
+	// QueueSize is the number of packets queued per session. Pushed messages are dropped while the
+	// queue of a session is full. Defaults to 256.
+	QueueSize int
+
+	// ConfirmationCommands contains commands that are answered with an empty response without
+	// calling the handler, compared case-insensitively to the command. This avoids executing the
+	// confirmation command sent after every command, e.g. `ShowCurrentMap` by the squadrcon
+	// package, but also answers the command when it is executed for its response. Optional.
+	ConfirmationCommands []string
+
+	// OnError is called when a client fails to authenticate, sends an invalid packet or the
+	// handler fails to execute a command. Optional.
+	OnError func(err error)
+}
+
+// Server implements the server side of the RCON protocol as spoken by Squad, for building fake
+// servers, proxies and bridges. Responses that do not fit into a single packet are split into
+// multiple packets. Empty commands and ServerSettings.ConfirmationCommands are answered with an
+// empty response without calling the handler, other confirmation commands, such as the
+// `ShowCurrentMap` sent by the squadrcon package after every command, reach the handler like any
+// other command.
+type Server struct {
+	handler  ServerHandler
+	settings ServerSettings
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	listeners map[net.Listener]struct{}
+	sessions  map[*ServerSession]struct{}
+
+	// Closed when the server is closed.
+	done chan struct{}
+
+	closed bool
+}
+
+// ServerSession is an authenticated client connection.
+type ServerSession struct {
+	// Value holds data of the handler, e.g. the user the password belongs to. It may only be set
+	// in Authenticate.
+	Value any
+
+	conn net.Conn
+
+	// Packets waiting to be written.
+	outgoing chan *packet
+
+	// Closed when the session ends.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// RemoteAddr returns the address of the client.
+func (s *ServerSession) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+// Push sends a message that is not a response to a command, e.g. a chat message, to the client.
+// The message is dropped if the client falls behind. Returns false if the message was dropped.
+func (s *ServerSession) Push(message string) bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+	}
+
+	select {
+	case s.outgoing <- newPacket(serverDataChatValue, 0, message):
+		return true
+	default:
+		return false
+	}
+}
+
+// Done returns a channel that is closed when the session ends.
+func (s *ServerSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close disconnects the client.
+func (s *ServerSession) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+// send queues a packet, waiting while the queue is full. Returns false if the session ended.
+func (s *ServerSession) send(p *packet) bool {
+	select {
+	case s.outgoing <- p:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+func NewServer(handler ServerHandler, settings ServerSettings) *Server {
+	if settings.WriteTimeout <= 0 {
+		settings.WriteTimeout = defaultServerWriteTimeout
+	}
+
+	if settings.QueueSize <= 0 {
+		settings.QueueSize = defaultServerQueueSize
+	}
+
+	return &Server{
+		handler:   handler,
+		settings:  settings,
+		listeners: make(map[net.Listener]struct{}),
+		sessions:  make(map[*ServerSession]struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP address and serves clients until the server is closed.
+func (s *Server) ListenAndServe(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+// Serve accepts clients on the listener until the server is closed, in which case ErrServerClosed
+// is returned. The listener is closed when Serve returns.
+func (s *Server) Serve(listener net.Listener) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		_ = listener.Close()
+		return ErrServerClosed
+	}
+	s.listeners[listener] = struct{}{}
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.listeners, listener)
+		s.lock.Unlock()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+				return ErrServerClosed
+			default:
+			}
+
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn authenticates the client connected using conn and executes its commands until it
+// disconnects. The connection is closed when ServeConn returns.
+func (s *Server) ServeConn(conn net.Conn) {
+	session := &ServerSession{
+		conn:     conn,
+		outgoing: make(chan *packet, s.settings.QueueSize),
+		done:     make(chan struct{}),
+	}
+
+	authPacket, err := s.authenticate(session)
+	if err != nil {
+		_ = conn.Close()
+		s.reportError(fmt.Errorf("client %s failed to authenticate: %w", conn.RemoteAddr(), err))
+		return
+	}
+
+	// Squad responds to authentication with an empty response followed by the auth response.
+	// Queued before the session is registered, so that pushed messages follow them.
+	session.outgoing <- newPacket(serverDataResponseValue, authPacket.Id, "")
+	session.outgoing <- newPacket(serverDataAuthResponse, authPacket.Id, "")
+
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		_ = conn.Close()
+		return
+	}
+	s.sessions[session] = struct{}{}
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.sessions, session)
+		s.lock.Unlock()
+		_ = session.Close()
+	}()
+
+	go s.writePackets(session)
+
+	for {
+		request := packet{}
+		if _, err := request.ReadFrom(conn); err != nil {
+			select {
+			case <-session.done:
+			default:
+				// Clients closing the connection between packets is not an error.
+				if errors.Is(err, io.EOF) && request.Size == 0 {
+					return
+				}
+				s.reportError(fmt.Errorf("client %s disconnected: %w", conn.RemoteAddr(), err))
+			}
+			return
+		}
+
+		if !s.handlePacket(session, &request) {
+			return
+		}
+	}
+}
+
+// Broadcast pushes the message to all sessions. Clients that fall behind miss messages.
+func (s *Server) Broadcast(message string) {
+	for _, session := range s.Sessions() {
+		session.Push(message)
+	}
+}
+
+// Sessions returns the sessions that are currently connected.
+func (s *Server) Sessions() []*ServerSession {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sessions := make([]*ServerSession, 0, len(s.sessions))
+	for session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// Close stops serving and disconnects all clients.
+func (s *Server) Close() error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+
+	for listener := range s.listeners {
+		_ = listener.Close()
+	}
+	sessions := s.sessions
+	s.sessions = make(map[*ServerSession]struct{})
+	s.lock.Unlock()
+
+	for session := range sessions {
+		_ = session.Close()
+	}
+
+	return nil
+}
+
+// authenticate reads the auth packet and passes the password to the handler.
+func (s *Server) authenticate(session *ServerSession) (*packet, error) {
+	request := &packet{}
+	if _, err := request.ReadFrom(session.conn); err != nil {
+		return nil, err
+	}
+
+	if request.Type != serverDataAuth {
+		return nil, fmt.Errorf("%w: expected auth packet, got type %d", ErrNotAuthenticated, request.Type)
+	}
+
+	if err := s.handler.Authenticate(session, request.GetBody()); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// handlePacket responds to a packet of the client. Returns false if the session should end.
+func (s *Server) handlePacket(session *ServerSession, request *packet) bool {
+	command := request.GetBody()
+
+	switch {
+	case request.Type != serverDataExecCommand:
+		// Clients send empty response packets to detect the end of multi-packet responses. Squad
+		// mirrors them twice followed by a packet with body `00 01 00 00`, see Notes.md.
+		return session.send(newPacket(serverDataResponseValue, request.Id, "")) &&
+			session.send(newPacket(serverDataResponseValue, request.Id, "")) &&
+			session.send(newPacket(serverDataResponseValue, request.Id, "\x00\x01\x00\x00"))
+	case strings.TrimSpace(command) == "" || s.isConfirmationCommand(command):
+		return session.send(newPacket(serverDataResponseValue, request.Id, ""))
+	}
+
+	response, err := s.handler.Execute(session, command)
+	if err != nil {
+		s.reportError(fmt.Errorf("failed to execute %q for client %s: %w", command, session.RemoteAddr(), err))
+		return false
+	}
+
+	return s.respond(session, request.Id, response)
+}
+
+func (s *Server) isConfirmationCommand(command string) bool {
+	command = strings.TrimSpace(command)
+	for _, confirmationCommand := range s.settings.ConfirmationCommands {
+		if strings.EqualFold(confirmationCommand, command) {
+			return true
+		}
+	}
+	return false
+}
+
+// respond sends the response, split into multiple packets if it does not fit into one, as Squad
+// does for long responses.
+func (s *Server) respond(session *ServerSession, id int32, response string) bool {
+	for {
+		chunk := response
+		if len(chunk) > maxPacketBodySize {
+			chunk = chunk[:maxPacketBodySize]
+		}
+		response = response[len(chunk):]
+
+		if !session.send(newPacket(serverDataResponseValue, id, chunk)) {
+			return false
+		}
+		if response == "" {
+			return true
+		}
+	}
+}
+
+func (s *Server) writePackets(session *ServerSession) {
+	for {
+		select {
+		case <-session.done:
+			return
+		case outgoing := <-session.outgoing:
+			_ = session.conn.SetWriteDeadline(time.Now().Add(s.settings.WriteTimeout))
+
+			if _, err := outgoing.WriteTo(session.conn); err != nil {
+				_ = session.Close()
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) reportError(err error) {
+	if s.settings.OnError != nil {
+		s.settings.OnError(err)
+	}
+}
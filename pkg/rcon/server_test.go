@@ -0,0 +1,68 @@
+package rcon
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+type echoHandler struct{}
+
+func (echoHandler) Authenticate(session *ServerSession, password string) error {
+	return nil
+}
+
+func (echoHandler) Execute(session *ServerSession, command string) (string, error) {
+	return "executed " + command, nil
+}
+
+// exchange sends a packet to the server and returns the bodies of the next count packets.
+func exchange(t *testing.T, conn net.Conn, request *packet, count int) []string {
+	t.Helper()
+
+	if _, err := request.WriteTo(conn); err != nil {
+		t.Fatalf("could not write packet: %v", err)
+	}
+
+	var bodies []string
+	for i := 0; i < count; i++ {
+		response := packet{}
+		if _, err := response.ReadFrom(conn); err != nil {
+			t.Fatalf("could not read packet: %v", err)
+		}
+		if response.Id != request.Id {
+			t.Errorf("got response with ID %d, expected %d", response.Id, request.Id)
+		}
+		bodies = append(bodies, response.GetBody())
+	}
+	return bodies
+}
+
+func TestServerResponses(t *testing.T) {
+	server := NewServer(echoHandler{}, ServerSettings{
+		ConfirmationCommands: []string{"ShowCurrentMap"},
+	})
+	defer server.Close()
+
+	client, conn := net.Pipe()
+	defer client.Close()
+	go server.ServeConn(conn)
+
+	tests := []struct {
+		name     string
+		request  *packet
+		expected []string
+	}{
+		{"auth", newPacket(serverDataAuth, 2, "password"), []string{"", ""}},
+		{"command", newPacket(serverDataExecCommand, 4, "ListSquads"), []string{"executed ListSquads"}},
+		{"confirmation command", newPacket(serverDataExecCommand, 5, "showcurrentmap"), []string{""}},
+		{"empty command", newPacket(serverDataExecCommand, 7, " "), []string{""}},
+		{"empty response", newPacket(serverDataResponseValue, 9, ""), []string{"", "", "\x00\x01\x00\x00"}},
+	}
+
+	for _, test := range tests {
+		if bodies := exchange(t, client, test.request, len(test.expected)); !reflect.DeepEqual(bodies, test.expected) {
+			t.Errorf("%s: got %q, expected %q", test.name, bodies, test.expected)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package servercfg
+
+import (
+	"sort"
+)
+
+// Change is a difference of a single key between two configs.
+type Change struct {
+	Key string
+
+	// Old is empty if the key was added.
+	Old string
+
+	// New is empty if the key was removed.
+	New string
+
+	Added   bool
+	Removed bool
+}
+
+// DiffServerConfig returns the keys whose values differ, ordered by key. Formatting differences
+// such as quotes and comments are ignored.
+func DiffServerConfig(old *ServerConfig, new *ServerConfig) []Change {
+	oldValues := old.Values()
+	newValues := new.Values()
+
+	var changes []Change
+	for key, oldValue := range oldValues {
+		newValue, exists := newValues[key]
+		if !exists {
+			changes = append(changes, Change{Key: key, Old: oldValue, Removed: true})
+		} else if newValue != oldValue {
+			changes = append(changes, Change{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+
+	for key, newValue := range newValues {
+		if _, exists := oldValues[key]; !exists {
+			changes = append(changes, Change{Key: key, New: newValue, Added: true})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// RotationDiff is the difference between two rotations.
+type RotationDiff struct {
+	// Added contains the entries only in the new rotation, in rotation order.
+	Added []string
+
+	// Removed contains the entries only in the old rotation, in rotation order.
+	Removed []string
+
+	// Reordered is true if the entries in both rotations are in a different order.
+	Reordered bool
+}
+
+// IsEmpty returns whether both rotations contain the same entries in the same order.
+func (d RotationDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && !d.Reordered
+}
+
+func DiffRotation(old *Rotation, new *Rotation) RotationDiff {
+	oldEntries := old.Entries()
+	newEntries := new.Entries()
+
+	var diff RotationDiff
+	diff.Added = subtractEntries(newEntries, oldEntries)
+	diff.Removed = subtractEntries(oldEntries, newEntries)
+
+	// Compare the order of the entries present in both rotations.
+	oldCommon := subtractEntries(oldEntries, diff.Removed)
+	newCommon := subtractEntries(newEntries, diff.Added)
+	for i := range oldCommon {
+		if oldCommon[i] != newCommon[i] {
+			diff.Reordered = true
+			break
+		}
+	}
+
+	return diff
+}
+
+// subtractEntries returns the entries of a that are not in b. Repeated entries are counted, so an
+// entry occurring twice in a and once in b is returned once.
+func subtractEntries(a []string, b []string) []string {
+	counts := make(map[string]int)
+	for _, entry := range b {
+		counts[entry]++
+	}
+
+	var result []string
+	for _, entry := range a {
+		if counts[entry] > 0 {
+			counts[entry]--
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
@@ -0,0 +1,193 @@
+package servercfg
+
+import (
+	"fmt"
+	"reflect"
+	"squad-rcon-go/pkg/rcon"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Issue is a problem found by linting.
+type Issue struct {
+	Severity Severity
+
+	// Subject is the key, layer or tag the issue is about.
+	Subject string
+
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Subject, i.Message)
+}
+
+// HasErrors returns whether any issue is an error.
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+const maxPlayersLimit = 100
+
+// LintServerConfig checks a Server.cfg for unknown and duplicate keys, invalid values and
+// inconsistent settings.
+func LintServerConfig(config *ServerConfig) []Issue {
+	var issues []Issue
+
+	known := make(map[string]struct{})
+	settingsType := reflect.TypeOf(ServerSettings{})
+	for i := 0; i < settingsType.NumField(); i++ {
+		known[settingsType.Field(i).Name] = struct{}{}
+	}
+
+	seen := make(map[string]struct{})
+	for _, key := range config.Keys() {
+		if _, duplicate := seen[key]; duplicate {
+			issues = append(issues, Issue{SeverityWarning, key, "key is set multiple times, the last value is used"})
+		}
+		seen[key] = struct{}{}
+
+		if _, exists := known[key]; !exists {
+			issues = append(issues, Issue{SeverityWarning, key, "unknown key"})
+		}
+	}
+
+	settings, fieldErrors := config.decodeSettings()
+	for _, key := range sortedKeys(fieldErrors) {
+		issues = append(issues, Issue{SeverityError, key, fieldErrors[key].Error()})
+	}
+
+	values := config.Values()
+
+	if _, exists := values["ServerName"]; exists && strings.TrimSpace(settings.ServerName) == "" {
+		issues = append(issues, Issue{SeverityWarning, "ServerName", "server name is empty"})
+	}
+
+	if _, exists := values["MaxPlayers"]; exists && (settings.MaxPlayers < 1 || settings.MaxPlayers > maxPlayersLimit) {
+		issues = append(issues, Issue{SeverityError, "MaxPlayers", fmt.Sprintf("must be between 1 and %d", maxPlayersLimit)})
+	}
+
+	if settings.NumReservedSlots < 0 || (settings.MaxPlayers > 0 && settings.NumReservedSlots > settings.MaxPlayers) {
+		issues = append(issues, Issue{SeverityError, "NumReservedSlots", "must be between 0 and MaxPlayers"})
+	}
+
+	switch settings.MapRotationMode {
+	case "", RotationModeLayerList, RotationModeLevelList, RotationModeLayerListVote:
+	default:
+		issues = append(issues, Issue{SeverityError, "MapRotationMode", fmt.Sprintf(
+			"must be %s, %s or %s",
+			RotationModeLayerList,
+			RotationModeLevelList,
+			RotationModeLayerListVote,
+		)})
+	}
+
+	for _, key := range []string{"PublicQueueLimit", "RejoinSquadDelayAfterKick", "ServerMessageInterval", "AutoTKBanNumberTKs", "AutoTKBanTime"} {
+		if reflect.ValueOf(settings).FieldByName(key).Int() < 0 {
+			issues = append(issues, Issue{SeverityError, key, "must not be negative"})
+		}
+	}
+
+	return issues
+}
+
+// LintRotation checks a rotation for entries that do not exist and repeated entries. Existence is
+// not checked if available is nil.
+func LintRotation(rotation *Rotation, available []string) []Issue {
+	var issues []Issue
+
+	entries := rotation.Entries()
+	if len(entries) == 0 {
+		issues = append(issues, Issue{SeverityError, "rotation", "rotation is empty"})
+	}
+
+	var availableSet map[string]struct{}
+	var availableFold map[string]string
+	if available != nil {
+		availableSet = make(map[string]struct{}, len(available))
+		availableFold = make(map[string]string, len(available))
+		for _, name := range available {
+			availableSet[name] = struct{}{}
+			availableFold[strings.ToLower(name)] = name
+		}
+	}
+
+	for index, entry := range entries {
+		if index > 0 && entries[index-1] == entry {
+			issues = append(issues, Issue{SeverityWarning, entry, "is repeated consecutively"})
+		}
+
+		if availableSet == nil {
+			continue
+		}
+
+		if _, exists := availableSet[entry]; exists {
+			continue
+		}
+
+		message := "does not exist on the server"
+		if suggestion, exists := availableFold[strings.ToLower(entry)]; exists {
+			message += fmt.Sprintf(", did you mean %s?", suggestion)
+		}
+		issues = append(issues, Issue{SeverityError, entry, message})
+	}
+
+	return issues
+}
+
+// LintRotationOnServer checks a rotation against the layers or levels of a server, depending on
+// the map rotation mode.
+func LintRotationOnServer(rcon rcon.Rcon, rotation *Rotation, mode string) ([]Issue, error) {
+	var available []string
+	var err error
+
+	if mode == RotationModeLevelList {
+		available, err = squadrcon.ListLevels(rcon)
+	} else {
+		available, err = squadrcon.ListLayers(rcon)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return LintRotation(rotation, available), nil
+}
+
+// LintMOTD checks a MOTD for unbalanced rich text tags.
+func LintMOTD(motd MOTD) []Issue {
+	var issues []Issue
+
+	if strings.TrimSpace(motd.Text) == "" {
+		issues = append(issues, Issue{SeverityWarning, "MOTD", "message is empty"})
+	}
+
+	for _, tag := range motd.unbalancedTags() {
+		issues = append(issues, Issue{SeverityWarning, "<" + tag + ">", "tag is not balanced"})
+	}
+
+	return issues
+}
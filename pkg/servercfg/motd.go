@@ -0,0 +1,78 @@
+package servercfg
+
+import (
+	"os"
+	"regexp"
+	"squad-rcon-go/pkg/cfgfile"
+	"strings"
+)
+
+// MOTD is the content of MOTD.cfg, the message shown to players when joining. It may contain rich
+// text tags such as `<color=#ff0000>...</>` or `<b>...</b>`.
+type MOTD struct {
+	// Text uses LF line endings, regardless of the line ending of the file.
+	Text string
+
+	// Written after every line, see cfgfile.JoinLines.
+	lineEnding string
+}
+
+// ReadMOTD reads a MOTD.cfg file.
+func ReadMOTD(path string) (MOTD, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return MOTD{}, err
+	}
+
+	return ParseMOTD(string(content)), nil
+}
+
+// ParseMOTD normalises line endings and removes trailing whitespace. The line ending of the content
+// is used when writing.
+func ParseMOTD(content string) MOTD {
+	lineEnding := cfgfile.DetectLineEnding(content)
+	content = strings.ReplaceAll(content, cfgfile.CRLF, cfgfile.LF)
+	return MOTD{Text: strings.TrimRight(content, " \t\n"), lineEnding: lineEnding}
+}
+
+func (m MOTD) String() string {
+	return cfgfile.JoinLines(strings.Split(m.Text, cfgfile.LF), m.lineEnding)
+}
+
+// WriteFile writes the MOTD to a file, replacing its content.
+func (m MOTD) WriteFile(path string) error {
+	return os.WriteFile(path, []byte(m.String()), 0644)
+}
+
+var richTextTagRegex = regexp.MustCompile(`<(/?)([A-Za-z]*)(?:=[^>]*)?>`)
+
+const (
+	_ = iota
+	richTextTagClosing
+	richTextTagName
+)
+
+// unbalancedTags returns the names of tags that are opened but not closed, or closed but not
+// opened. The generic closing tag `</>` closes the last opened tag.
+func (m MOTD) unbalancedTags() []string {
+	var open []string
+	var unbalanced []string
+
+	for _, matches := range richTextTagRegex.FindAllStringSubmatch(m.Text, -1) {
+		name := strings.ToLower(matches[richTextTagName])
+
+		if matches[richTextTagClosing] == "" {
+			open = append(open, name)
+			continue
+		}
+
+		if len(open) > 0 && (name == "" || open[len(open)-1] == name) {
+			open = open[:len(open)-1]
+			continue
+		}
+
+		unbalanced = append(unbalanced, "/"+name)
+	}
+
+	return append(unbalanced, open...)
+}
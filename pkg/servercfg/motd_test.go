@@ -0,0 +1,60 @@
+package servercfg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMOTD(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		written string
+	}{
+		{"LF", "<b>Welcome</b>\nRules: discord.gg/example  \n\n", "<b>Welcome</b>\nRules: discord.gg/example\n"},
+		{"CRLF", "<b>Welcome</b>\r\nRules: discord.gg/example  \r\n\r\n", "<b>Welcome</b>\r\nRules: discord.gg/example\r\n"},
+		{"unterminated", "<b>Welcome</b>\r\nRules: discord.gg/example", "<b>Welcome</b>\r\nRules: discord.gg/example\r\n"},
+	}
+
+	for _, test := range tests {
+		motd := ParseMOTD(test.content)
+		if motd.Text != "<b>Welcome</b>\nRules: discord.gg/example" {
+			t.Errorf("%s: got %q", test.name, motd.Text)
+		}
+
+		// The MOTD is written with the line ending it was read with.
+		if written := motd.String(); written != test.written {
+			t.Errorf("%s: got %q, expected %q", test.name, written, test.written)
+		}
+	}
+
+	if written := (MOTD{Text: "Welcome\nRules"}).String(); written != "Welcome\nRules\n" {
+		t.Errorf("got %q, expected LF for new messages", written)
+	}
+}
+
+func TestLintMOTD(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []Issue
+	}{
+		{text: "<color=#ff0000>Welcome</> to <b>the server</b>"},
+		{text: "<color=#ff0000><b>Welcome</></>"},
+		{
+			text:     "<b>Welcome</i>",
+			expected: []Issue{{SeverityWarning, "</i>", "tag is not balanced"}, {SeverityWarning, "<b>", "tag is not balanced"}},
+		},
+		{
+			text:     " ",
+			expected: []Issue{{SeverityWarning, "MOTD", "message is empty"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if issues := LintMOTD(MOTD{Text: test.text}); !reflect.DeepEqual(issues, test.expected) {
+				t.Errorf("got %v, expected %v", issues, test.expected)
+			}
+		})
+	}
+}
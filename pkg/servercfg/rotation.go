@@ -0,0 +1,102 @@
+package servercfg
+
+import (
+	"io"
+	"os"
+	"squad-rcon-go/pkg/cfgfile"
+	"strings"
+	"sync"
+)
+
+// Rotation is the content of LayerRotation.cfg or MapRotation.cfg: one layer or level per line.
+// Comments and empty lines are preserved when writing. It is safe for concurrent use.
+type Rotation struct {
+	// Lock to be used before accessing lines.
+	lock sync.RWMutex
+
+	lines []string
+
+	// Written after every line, see cfgfile.JoinLines.
+	lineEnding string
+}
+
+// ReadRotation reads and parses a rotation file.
+func ReadRotation(path string) (*Rotation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseRotation(file)
+}
+
+// ParseRotation parses the content of a rotation file.
+func ParseRotation(reader io.Reader) (*Rotation, error) {
+	lines, lineEnding, err := cfgfile.ReadLines(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rotation{lines: lines, lineEnding: lineEnding}, nil
+}
+
+// NewRotation creates a rotation containing the entries.
+func NewRotation(entries []string) *Rotation {
+	return &Rotation{
+		lines: append([]string(nil), entries...),
+	}
+}
+
+// Entries returns the layers or levels in rotation order.
+func (r *Rotation) Entries() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var entries []string
+	for _, line := range r.lines {
+		if entry, ok := rotationEntry(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// SetEntries replaces the entries. Comments and empty lines before the first entry are kept, the
+// others are removed.
+func (r *Rotation) SetEntries(entries []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var lines []string
+	for _, line := range r.lines {
+		if _, ok := rotationEntry(line); ok {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	r.lines = append(lines, entries...)
+}
+
+func (r *Rotation) String() string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return cfgfile.JoinLines(r.lines, r.lineEnding)
+}
+
+// WriteFile writes the rotation to a file, replacing its content.
+func (r *Rotation) WriteFile(path string) error {
+	return os.WriteFile(path, []byte(r.String()), 0644)
+}
+
+// rotationEntry returns the layer or level of a line. Returns false for comments and empty lines.
+func rotationEntry(line string) (string, bool) {
+	if content, _, found := strings.Cut(line, "//"); found {
+		line = content
+	}
+
+	entry := strings.TrimSpace(line)
+	return entry, entry != ""
+}
@@ -0,0 +1,101 @@
+package servercfg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleRotation = `// Seeding
+Sumari_Seed_v1
+Narva_AAS_v1 // night layer
+
+Yehorivka_RAAS_v2
+`
+
+func TestParseRotation(t *testing.T) {
+	rotation, err := ParseRotation(strings.NewReader(sampleRotation))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"Sumari_Seed_v1", "Narva_AAS_v1", "Yehorivka_RAAS_v2"}
+	if entries := rotation.Entries(); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("got %v, expected %v", entries, expected)
+	}
+
+	rotation.SetEntries([]string{"Narva_AAS_v1"})
+	if formatted := rotation.String(); formatted != "// Seeding\nNarva_AAS_v1\n" {
+		t.Errorf("got %q", formatted)
+	}
+}
+
+func TestRotationRoundTrip(t *testing.T) {
+	for _, content := range []string{sampleRotation, strings.ReplaceAll(sampleRotation, "\n", "\r\n")} {
+		rotation, err := ParseRotation(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if rotation.String() != content {
+			t.Errorf("got %q, expected %q", rotation.String(), content)
+		}
+	}
+}
+
+func TestRotationMixedLineEndings(t *testing.T) {
+	// The first line was added by an editor using LF, the other lines use CRLF.
+	rotation, err := ParseRotation(strings.NewReader("// Seeding\nNarva_AAS_v1\r\nGorodok_RAAS_v1\r\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rotation.SetEntries([]string{"Narva_AAS_v1", "Yehorivka_RAAS_v2"})
+	if formatted := rotation.String(); formatted != "// Seeding\r\nNarva_AAS_v1\r\nYehorivka_RAAS_v2\r\n" {
+		t.Errorf("got %q, expected the line ending of the majority of the lines", formatted)
+	}
+}
+
+func TestDiffRotation(t *testing.T) {
+	old := NewRotation([]string{"A", "B", "C", "C"})
+
+	tests := []struct {
+		name     string
+		new      []string
+		expected RotationDiff
+	}{
+		{name: "same", new: []string{"A", "B", "C", "C"}},
+		{name: "reordered", new: []string{"C", "B", "A", "C"}, expected: RotationDiff{Reordered: true}},
+		{name: "added and removed", new: []string{"A", "C", "D"}, expected: RotationDiff{Added: []string{"D"}, Removed: []string{"B", "C"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff := DiffRotation(old, NewRotation(test.new))
+			if !reflect.DeepEqual(diff, test.expected) {
+				t.Errorf("got %+v, expected %+v", diff, test.expected)
+			}
+			if diff.IsEmpty() != reflect.DeepEqual(test.expected, RotationDiff{}) {
+				t.Errorf("IsEmpty: got %t", diff.IsEmpty())
+			}
+		})
+	}
+}
+
+func TestLintRotation(t *testing.T) {
+	rotation := NewRotation([]string{"Narva_AAS_v1", "Narva_AAS_v1", "narva_raas_v1", "Unknown"})
+	available := []string{"Narva_AAS_v1", "Narva_RAAS_v1"}
+
+	expected := []Issue{
+		{SeverityWarning, "Narva_AAS_v1", "is repeated consecutively"},
+		{SeverityError, "narva_raas_v1", "does not exist on the server, did you mean Narva_RAAS_v1?"},
+		{SeverityError, "Unknown", "does not exist on the server"},
+	}
+	if issues := LintRotation(rotation, available); !reflect.DeepEqual(issues, expected) {
+		t.Errorf("got %v, expected %v", issues, expected)
+	}
+
+	if issues := LintRotation(NewRotation(nil), nil); len(issues) != 1 || issues[0].Severity != SeverityError {
+		t.Errorf("got %v, expected an empty rotation error", issues)
+	}
+}
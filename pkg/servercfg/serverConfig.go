@@ -0,0 +1,326 @@
+package servercfg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"squad-rcon-go/pkg/cfgfile"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// ServerSettings contains the settings of Server.cfg. Each field is stored under the key with the
+// same name.
+type ServerSettings struct {
+	ServerName                    string
+	ShouldAdvertise               bool
+	IsLANMatch                    bool
+	MaxPlayers                    int
+	NumReservedSlots              int
+	PublicQueueLimit              int
+	MapRotationMode               string
+	RandomizeAtStart              bool
+	UseVoteFactions               bool
+	UseVoteLevel                  bool
+	UseVoteLayer                  bool
+	AllowTeamChanges              bool
+	PreventTeamChangeIfUnbalanced bool
+	NumPlayersDiffForTeamChanges  int
+	RejoinSquadDelayAfterKick     int
+	RecordDemos                   bool
+	AllowPublicClientsToRecord    bool
+	ServerMessageInterval         int
+	TKAutoKickEnabled             bool
+	AutoTKBanNumberTKs            int
+	AutoTKBanTime                 int
+	VehicleKitRequirementDisabled bool
+	AllowCommunityAdminAccess     bool
+	AllowDevProfiling             bool
+	AllowQA                       bool
+	VehicleClaimingDisabled       bool
+}
+
+// Map rotation modes accepted by MapRotationMode.
+const (
+	RotationModeLayerList     = "LayerList"
+	RotationModeLevelList     = "LevelList"
+	RotationModeLayerListVote = "LayerList_Vote"
+)
+
+// entry is a line of a key/value file. Lines without key, such as comments, only have raw set.
+type entry struct {
+	raw   string
+	key   string
+	value string
+
+	// The value at the time of parsing. The raw line is written as long as the value is unchanged.
+	original string
+}
+
+func (e *entry) String() string {
+	if e.key == "" || (e.raw != "" && e.value == e.original) {
+		return e.raw
+	}
+	return e.key + "=" + formatValue(e.value)
+}
+
+// ServerConfig is the content of a Server.cfg file. Comments, unknown keys and the order of lines
+// are preserved when writing. It is safe for concurrent use.
+type ServerConfig struct {
+	// Lock to be used before accessing entries.
+	lock sync.RWMutex
+
+	entries []*entry
+
+	// Written after every line, see cfgfile.JoinLines.
+	lineEnding string
+}
+
+// ReadServerConfig reads and parses a Server.cfg file.
+func ReadServerConfig(path string) (*ServerConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseServerConfig(file)
+}
+
+// ParseServerConfig parses the content of a Server.cfg file.
+func ParseServerConfig(reader io.Reader) (*ServerConfig, error) {
+	lines, lineEnding, err := cfgfile.ReadLines(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ServerConfig{lineEnding: lineEnding}
+
+	for _, line := range lines {
+		e := &entry{raw: line}
+
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "//") {
+			if key, value, found := strings.Cut(trimmed, "="); found {
+				e.key = strings.TrimSpace(key)
+				e.value = parseValue(value)
+				e.original = e.value
+			}
+		}
+
+		config.entries = append(config.entries, e)
+	}
+
+	return config, nil
+}
+
+// parseValue removes surrounding whitespace and quotes.
+func parseValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// formatValue quotes values containing spaces.
+func formatValue(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+func (c *ServerConfig) String() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	lines := make([]string, 0, len(c.entries))
+	for _, entry := range c.entries {
+		lines = append(lines, entry.String())
+	}
+	return cfgfile.JoinLines(lines, c.lineEnding)
+}
+
+// WriteFile writes the config to a file, replacing its content.
+func (c *ServerConfig) WriteFile(path string) error {
+	return os.WriteFile(path, []byte(c.String()), 0644)
+}
+
+// Get returns the value of the key. If the key occurs multiple times, the last value is returned,
+// as that is the value used by the server.
+func (c *ServerConfig) Get(key string) (string, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if entry := c.find(key); entry != nil {
+		return entry.value, true
+	}
+	return "", false
+}
+
+// Set changes the value of the key, or adds the key at the end of the config.
+func (c *ServerConfig) Set(key string, value string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if entry := c.find(key); entry != nil {
+		entry.value = value
+		return
+	}
+
+	c.entries = append(c.entries, &entry{key: key, value: value})
+}
+
+// Values returns all keys and their values. Duplicate keys have the last value.
+func (c *ServerConfig) Values() map[string]string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	values := make(map[string]string)
+	for _, entry := range c.entries {
+		if entry.key != "" {
+			values[entry.key] = entry.value
+		}
+	}
+	return values
+}
+
+// Keys returns the keys in the order they occur, including duplicates.
+func (c *ServerConfig) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var keys []string
+	for _, entry := range c.entries {
+		if entry.key != "" {
+			keys = append(keys, entry.key)
+		}
+	}
+	return keys
+}
+
+// Settings returns the typed settings. Missing keys are left at the zero value. Values that cannot
+// be converted are reported in the returned error, the other settings are returned regardless.
+func (c *ServerConfig) Settings() (ServerSettings, error) {
+	settings, fieldErrors := c.decodeSettings()
+
+	var errs []error
+	for _, key := range sortedKeys(fieldErrors) {
+		errs = append(errs, fmt.Errorf("%s: %w", key, fieldErrors[key]))
+	}
+
+	return settings, errors.Join(errs...)
+}
+
+// decodeSettings returns the typed settings and the conversion errors keyed by key.
+func (c *ServerConfig) decodeSettings() (ServerSettings, map[string]error) {
+	values := c.Values()
+
+	var settings ServerSettings
+	fieldErrors := make(map[string]error)
+
+	target := reflect.ValueOf(&settings).Elem()
+	for i := 0; i < target.NumField(); i++ {
+		name := target.Type().Field(i).Name
+		value, exists := values[name]
+		if !exists {
+			continue
+		}
+
+		if err := setField(target.Field(i), value); err != nil {
+			fieldErrors[name] = err
+		}
+	}
+
+	return settings, fieldErrors
+}
+
+// SetSettings writes the typed settings. Only keys whose value differs are changed, so the
+// formatting of other lines is preserved. Missing keys are only added for settings that are not
+// the zero value, so that the server defaults of the other settings remain in effect.
+func (c *ServerConfig) SetSettings(settings ServerSettings) {
+	values := c.Values()
+
+	source := reflect.ValueOf(settings)
+	for i := 0; i < source.NumField(); i++ {
+		name := source.Type().Field(i).Name
+		value := formatField(source.Field(i))
+
+		current, exists := values[name]
+		if exists && sameFieldValue(source.Field(i), current, value) {
+			continue
+		}
+		if !exists && source.Field(i).IsZero() {
+			continue
+		}
+
+		c.Set(name, value)
+	}
+}
+
+func (c *ServerConfig) find(key string) *entry {
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		if c.entries[i].key == key {
+			return c.entries[i]
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s is not a boolean", ErrInvalidValue, value)
+		}
+		field.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s is not a number", ErrInvalidValue, value)
+		}
+		field.SetInt(int64(parsed))
+	}
+	return nil
+}
+
+func formatField(field reflect.Value) string {
+	switch field.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool())
+	case reflect.Int:
+		return strconv.FormatInt(field.Int(), 10)
+	default:
+		return field.String()
+	}
+}
+
+// sameFieldValue compares values by their parsed value, so e.g. `True` is not replaced by `true`.
+func sameFieldValue(field reflect.Value, current string, value string) bool {
+	parsed := reflect.New(field.Type()).Elem()
+	if err := setField(parsed, current); err != nil {
+		return false
+	}
+	return formatField(parsed) == value
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,141 @@
+package servercfg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleServerConfig = `// Server name
+ServerName="[TWS] Squad | Seeding"
+ShouldAdvertise=true
+MaxPlayers=98
+NumReservedSlots=2
+// LayerList, LevelList or LayerList_Vote
+MapRotationMode=LayerList
+AllowTeamChanges=True
+TKAutoKickEnabled=false
+`
+
+func TestParseServerConfig(t *testing.T) {
+	config, err := ParseServerConfig(strings.NewReader(sampleServerConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	settings, err := config.Settings()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := ServerSettings{
+		ServerName:       "[TWS] Squad | Seeding",
+		ShouldAdvertise:  true,
+		MaxPlayers:       98,
+		NumReservedSlots: 2,
+		MapRotationMode:  RotationModeLayerList,
+		AllowTeamChanges: true,
+	}
+	if settings != expected {
+		t.Errorf("got %+v, expected %+v", settings, expected)
+	}
+
+	expectedKeys := []string{"ServerName", "ShouldAdvertise", "MaxPlayers", "NumReservedSlots", "MapRotationMode", "AllowTeamChanges", "TKAutoKickEnabled"}
+	if keys := config.Keys(); !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("got keys %v, expected %v", keys, expectedKeys)
+	}
+}
+
+func TestServerConfigInvalidValues(t *testing.T) {
+	config, err := ParseServerConfig(strings.NewReader("MaxPlayers=many\nRecordDemos=maybe\nServerName=Squad\nMaxPlayers=80\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The last value of duplicate keys is used.
+	settings, err := config.Settings()
+	if !errors.Is(err, ErrInvalidValue) || !strings.Contains(err.Error(), "RecordDemos") || strings.Contains(err.Error(), "MaxPlayers") {
+		t.Errorf("got %v, expected an invalid RecordDemos", err)
+	}
+	if settings.ServerName != "Squad" || settings.MaxPlayers != 80 {
+		t.Errorf("expected valid settings to be returned, got %+v", settings)
+	}
+}
+
+func TestServerConfigRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "LF", content: sampleServerConfig},
+		{name: "CRLF", content: strings.ReplaceAll(sampleServerConfig, "\n", "\r\n")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := ParseServerConfig(strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if config.String() != test.content {
+				t.Errorf("got %q, expected %q", config.String(), test.content)
+			}
+
+			lineEnding := "\n"
+			if strings.Contains(test.content, "\r\n") {
+				lineEnding = "\r\n"
+			}
+
+			settings, _ := config.Settings()
+			settings.MaxPlayers = 100
+			settings.RecordDemos = true
+			config.SetSettings(settings)
+
+			// Unchanged values keep their formatting, e.g. `True` is not replaced by `true`.
+			expected := strings.Replace(test.content, "MaxPlayers=98", "MaxPlayers=100", 1) + "RecordDemos=true" + lineEnding
+			if config.String() != expected {
+				t.Errorf("got %q, expected %q", config.String(), expected)
+			}
+		})
+	}
+}
+
+func TestDiffServerConfig(t *testing.T) {
+	old, _ := ParseServerConfig(strings.NewReader("ServerName=\"Squad\"\nMaxPlayers=98\nRecordDemos=true\n"))
+	new, _ := ParseServerConfig(strings.NewReader("// Renamed\nServerName=Squad\nMaxPlayers=100\nAllowQA=false\n"))
+
+	expected := []Change{
+		{Key: "AllowQA", New: "false", Added: true},
+		{Key: "MaxPlayers", Old: "98", New: "100"},
+		{Key: "RecordDemos", Old: "true", Removed: true},
+	}
+	if changes := DiffServerConfig(old, new); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("got %+v, expected %+v", changes, expected)
+	}
+}
+
+func TestLintServerConfig(t *testing.T) {
+	config, _ := ParseServerConfig(strings.NewReader("ServerName=Squad\nMaxPlayers=120\nNumReservedSlots=2\nMapRotationMode=Random\nMaxPlayers=101\nUnknownKey=1\nAutoTKBanTime=-1\n"))
+
+	expected := []Issue{
+		{SeverityWarning, "MaxPlayers", "key is set multiple times, the last value is used"},
+		{SeverityWarning, "UnknownKey", "unknown key"},
+		{SeverityError, "MaxPlayers", "must be between 1 and 100"},
+		{SeverityError, "MapRotationMode", "must be LayerList, LevelList or LayerList_Vote"},
+		{SeverityError, "AutoTKBanTime", "must not be negative"},
+	}
+	issues := LintServerConfig(config)
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("got %v, expected %v", issues, expected)
+	}
+	if !HasErrors(issues) {
+		t.Error("expected errors")
+	}
+
+	config, _ = ParseServerConfig(strings.NewReader(sampleServerConfig))
+	if issues := LintServerConfig(config); len(issues) != 0 {
+		t.Errorf("got %v, expected no issues", issues)
+	}
+}
@@ -0,0 +1,104 @@
+package squadlog
+
+import (
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+)
+
+// Correlate returns a copy of the event in which the Player field of each PlayerRef is set to the
+// matching player of the RCON roster. Players are matched by Steam ID, EOS ID, exact name and
+// finally by a case-insensitive name if that matches a single player. Events without player
+// references are returned unchanged.
+func Correlate(event Event, players []squadrcon.ActivePlayer) Event {
+	switch e := event.(type) {
+	case PlayerConnectedEvent:
+		correlateRef(&e.Player, players)
+		return e
+	case PlayerDisconnectedEvent:
+		correlateRef(&e.Player, players)
+		return e
+	case PlayerDamagedEvent:
+		correlateRef(&e.Victim, players)
+		correlateRef(&e.Attacker, players)
+		return e
+	case PlayerWoundedEvent:
+		correlateRef(&e.Victim, players)
+		correlateRef(&e.Attacker, players)
+		return e
+	case PlayerDiedEvent:
+		correlateRef(&e.Victim, players)
+		correlateRef(&e.Attacker, players)
+		return e
+	case PlayerRevivedEvent:
+		correlateRef(&e.Reviver, players)
+		correlateRef(&e.Victim, players)
+		return e
+	case DeployablePlacedEvent:
+		correlateRef(&e.Placer, players)
+		return e
+	case DeployableDamagedEvent:
+		correlateRef(&e.Attacker, players)
+		return e
+	case VehicleDamagedEvent:
+		correlateRef(&e.Attacker, players)
+		return e
+	default:
+		return event
+	}
+}
+
+func correlateRef(ref *PlayerRef, players []squadrcon.ActivePlayer) {
+	if player, found := findPlayer(*ref, players); found {
+		ref.Player = &player
+
+		// Complete the identity with the fields not present in the log line.
+		if ref.Name == "" {
+			ref.Name = player.Name
+		}
+		if ref.SteamId == 0 {
+			ref.SteamId = player.SteamId
+		}
+		if ref.EosId == "" {
+			ref.EosId = player.EosId
+		}
+	}
+}
+
+func findPlayer(ref PlayerRef, players []squadrcon.ActivePlayer) (squadrcon.ActivePlayer, bool) {
+	if ref.SteamId != 0 {
+		for _, player := range players {
+			if player.SteamId == ref.SteamId {
+				return player, true
+			}
+		}
+	}
+
+	if ref.EosId != "" {
+		for _, player := range players {
+			if player.EosId == ref.EosId {
+				return player, true
+			}
+		}
+	}
+
+	if ref.Name == "" {
+		return squadrcon.ActivePlayer{}, false
+	}
+
+	for _, player := range players {
+		if player.Name == ref.Name {
+			return player, true
+		}
+	}
+
+	var match squadrcon.ActivePlayer
+	matches := 0
+	for _, player := range players {
+		if strings.EqualFold(player.Name, ref.Name) {
+			match = player
+			matches++
+		}
+	}
+
+	return match, matches == 1
+}
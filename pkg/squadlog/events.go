@@ -0,0 +1,139 @@
+package squadlog
+
+import (
+	"squad-rcon-go/pkg/squadrcon"
+	"time"
+)
+
+// Event is implemented by all events parsed from the log.
+type Event interface {
+	// GetTime returns the time of the log line, in UTC.
+	GetTime() time.Time
+
+	// GetRaw returns the log line.
+	GetRaw() string
+}
+
+// LineHeader contains the fields common to all log lines. It is embedded in all events.
+type LineHeader struct {
+	Time time.Time
+
+	// ChainId is the frame counter logged by the server. Lines with the same ChainId were logged
+	// while handling the same frame, e.g. the damage and wound lines of a single hit.
+	ChainId int
+
+	Raw string
+}
+
+func (h LineHeader) GetTime() time.Time {
+	return h.Time
+}
+
+func (h LineHeader) GetRaw() string {
+	return h.Raw
+}
+
+// PlayerRef identifies a player as logged by the server. The identity fields that are available
+// depend on the log line. Player is filled in by correlation with the RCON roster.
+type PlayerRef struct {
+	Name    string
+	SteamId squadrcon.SteamID64
+	EosId   squadrcon.EOSID
+
+	// Controller is the name of the player's controller, e.g. `BP_PlayerController_C_2130438728`.
+	Controller string
+
+	// Player is the matching player of the RCON roster, nil if no player matches.
+	Player *squadrcon.ActivePlayer
+}
+
+type PlayerConnectedEvent struct {
+	LineHeader
+	Player PlayerRef
+	Ip     string
+}
+
+type PlayerDisconnectedEvent struct {
+	LineHeader
+	Player PlayerRef
+	Ip     string
+}
+
+// PlayerDamagedEvent is logged for every hit on a player.
+type PlayerDamagedEvent struct {
+	LineHeader
+	Victim   PlayerRef
+	Attacker PlayerRef
+	Damage   float64
+	Weapon   string
+}
+
+type PlayerWoundedEvent struct {
+	LineHeader
+	Victim   PlayerRef
+	Attacker PlayerRef
+	Damage   float64
+	Weapon   string
+}
+
+type PlayerDiedEvent struct {
+	LineHeader
+	Victim   PlayerRef
+	Attacker PlayerRef
+	Damage   float64
+	Weapon   string
+}
+
+type PlayerRevivedEvent struct {
+	LineHeader
+	Reviver PlayerRef
+	Victim  PlayerRef
+}
+
+// DeployablePlacedEvent is logged when a player places a deployable, such as a FOB radio or HAB.
+// The format of these lines has not been confirmed against a server log yet, so no default pattern
+// exists. Register one using DeployablePlacedPattern to receive them.
+type DeployablePlacedEvent struct {
+	LineHeader
+	Deployable string
+	Placer     PlayerRef
+}
+
+// DeployableDamagedEvent is logged when a deployable, such as a FOB radio or HAB, takes damage.
+type DeployableDamagedEvent struct {
+	LineHeader
+	Deployable      string
+	Damage          float64
+	Weapon          string
+	DamageType      string
+	Attacker        PlayerRef
+	HealthRemaining float64
+}
+
+// VehicleDamagedEvent is logged when a vehicle seat takes damage.
+type VehicleDamagedEvent struct {
+	LineHeader
+	Vehicle         string
+	Damage          float64
+	Causer          string
+	Attacker        PlayerRef
+	HealthRemaining float64
+}
+
+// NewGameEvent is logged when a layer is loaded.
+type NewGameEvent struct {
+	LineHeader
+	Level string
+
+	// Layer contains the layer's class name, e.g. `Narva_AAS_v1`.
+	Layer string
+}
+
+type RoundEndedEvent struct {
+	LineHeader
+	WinningTeamIndex int
+	WinningFaction   string
+	Tickets          int
+	Layer            string
+	Level            string
+}
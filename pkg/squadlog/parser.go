@@ -0,0 +1,532 @@
+package squadlog
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"squad-rcon-go/pkg/squadrcon"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLineHasNoHeader = errors.New("log line does not start with a time and chain ID")
+)
+
+// LinePattern recognises one log line format.
+type LinePattern struct {
+	// Regex is matched against the part of the line that follows the header, e.g.
+	// `LogSquad: ...` for `[2023.12.01-00.05.51:123][456]LogSquad: ...`.
+	Regex *regexp.Regexp
+
+	// Parse converts the submatches of Regex into an event. Returning an error causes the next
+	// pattern to be tried.
+	Parse func(header LineHeader, matches []string) (Event, error)
+}
+
+// Parser converts SquadGame.log lines into typed events using a table of patterns. The first
+// matching pattern determines the result.
+type Parser struct {
+	// Lock to be used before accessing patterns.
+	lock sync.RWMutex
+
+	patterns []LinePattern
+}
+
+// NewParser returns a parser that recognises the formats known to this package. Other formats can
+// be added using Register.
+func NewParser() *Parser {
+	return &Parser{
+		patterns: defaultLinePatterns(),
+	}
+}
+
+// Register adds a pattern. Registered patterns take precedence over the existing patterns so they
+// can be used to override the default patterns.
+func (p *Parser) Register(pattern LinePattern) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.patterns = append([]LinePattern{pattern}, p.patterns...)
+}
+
+// Parse returns the event of the line. Returns false for lines that do not match any pattern,
+// which applies to most of the log.
+func (p *Parser) Parse(line string) (Event, bool) {
+	header, body, err := ParseLineHeader(line)
+	if err != nil {
+		return nil, false
+	}
+
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	for _, pattern := range p.patterns {
+		matches := pattern.Regex.FindStringSubmatch(body)
+		if matches == nil {
+			continue
+		}
+
+		event, err := pattern.Parse(header, matches)
+		if err == nil {
+			return event, true
+		}
+	}
+
+	return nil, false
+}
+
+var lineHeaderRegex = regexp.MustCompile(`^\[(\d{4}\.\d{2}\.\d{2}-\d{2}\.\d{2}\.\d{2}:\d{3})\]\[\s*(\d+)\](.*)$`)
+
+const (
+	_ = iota
+	lineHeaderTime
+	lineHeaderChainId
+	lineHeaderBody
+)
+
+// lineTimeLayout is the layout of the log time after replacing the separator of the milliseconds,
+// which is `:` in the log, by `.` as required by time.Parse.
+const lineTimeLayout = "2006.01.02-15.04.05.000"
+
+// ParseLineHeader splits a log line into its header and the remainder of the line.
+func ParseLineHeader(line string) (LineHeader, string, error) {
+	matches := lineHeaderRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return LineHeader{}, "", ErrLineHasNoHeader
+	}
+
+	timeText := matches[lineHeaderTime]
+	lineTime, err := time.Parse(lineTimeLayout, timeText[:19]+"."+timeText[20:])
+	if err != nil {
+		return LineHeader{}, "", fmt.Errorf("could not parse log time \"%s\": %w", matches[lineHeaderTime], err)
+	}
+
+	chainId, err := strconv.Atoi(matches[lineHeaderChainId])
+	if err != nil {
+		return LineHeader{}, "", fmt.Errorf("could not parse chain ID \"%s\": %w", matches[lineHeaderChainId], err)
+	}
+
+	return LineHeader{
+		Time:    lineTime,
+		ChainId: chainId,
+		Raw:     line,
+	}, matches[lineHeaderBody], nil
+}
+
+// onlineIdsPattern matches `Online IDs: EOS: <id> steam: <id>`. Contains two groups: EOS ID and
+// Steam ID.
+const onlineIdsPattern = `Online IDs: EOS: ([0-9a-fA-F]{32})(?: steam: (\d+))?`
+
+var playerConnectedRegex = regexp.MustCompile(`^LogSquad: PostLogin: NewPlayer: [^ ]+ .+?PersistentLevel\.([^\s]+) \(IP: ([\d.]+) \| ` + onlineIdsPattern + `\)`)
+
+const (
+	_ = iota
+	playerConnectedController
+	playerConnectedIp
+	playerConnectedEosId
+	playerConnectedSteamId
+)
+
+var playerDisconnectedRegex = regexp.MustCompile(`^LogNet: UChannel::Close: Sending CloseBunch.* RemoteAddr: ([\d.]+):\d+,.* PC: ([^ ,]+),.* UniqueId: RedpointEOS:([0-9a-fA-F]{32})`)
+
+const (
+	_ = iota
+	playerDisconnectedIp
+	playerDisconnectedController
+	playerDisconnectedEosId
+)
+
+var playerDamagedRegex = regexp.MustCompile(`^LogSquad: Player:(.+) ActualDamage=([0-9.]+) from (.+) \(` + onlineIdsPattern + ` \| Player Controller ID: ([^ )]+)\)caused by ([A-Za-z_0-9-]+)_C`)
+
+const (
+	_ = iota
+	playerDamagedVictimName
+	playerDamagedDamage
+	playerDamagedAttackerName
+	playerDamagedAttackerEosId
+	playerDamagedAttackerSteamId
+	playerDamagedAttackerController
+	playerDamagedWeapon
+)
+
+// The server logs `Contoller`, the typo is intentional.
+var playerWoundedRegex = regexp.MustCompile(`^LogSquadTrace: \[DedicatedServer\](?:ASQSoldier::)?Wound\(\): Player:(.+) KillingDamage=-*([0-9.]+) from ([A-Za-z_0-9]+) \(` + onlineIdsPattern + ` \| Contoller ID: ([\w]+)\) caused by ([A-Za-z_0-9-]+)_C`)
+var playerDiedRegex = regexp.MustCompile(`^LogSquadTrace: \[DedicatedServer\](?:ASQSoldier::)?Die\(\): Player:(.+) KillingDamage=-*([0-9.]+) from ([A-Za-z_0-9]+) \(` + onlineIdsPattern + ` \| Contoller ID: ([\w]+)\) caused by ([A-Za-z_0-9-]+)_C`)
+
+const (
+	_ = iota
+	playerKilledVictimName
+	playerKilledDamage
+	playerKilledAttackerController
+	playerKilledAttackerEosId
+	playerKilledAttackerSteamId
+	playerKilledAttackerControllerId
+	playerKilledWeapon
+)
+
+var playerRevivedRegex = regexp.MustCompile(`^LogSquad: (.+) \(` + onlineIdsPattern + `\) has revived (.+) \(` + onlineIdsPattern + `\)\.`)
+
+const (
+	_ = iota
+	playerRevivedReviverName
+	playerRevivedReviverEosId
+	playerRevivedReviverSteamId
+	playerRevivedVictimName
+	playerRevivedVictimEosId
+	playerRevivedVictimSteamId
+)
+
+// Submatches of the regex passed to DeployablePlacedPattern.
+const (
+	_ = iota
+	deployablePlacedDeployable
+	deployablePlacedPlacerName
+	deployablePlacedPlacerEosId
+	deployablePlacedPlacerSteamId
+)
+
+var deployableDamagedRegex = regexp.MustCompile(`^LogSquadTrace: \[DedicatedServer\](?:ASQDeployable::)?TakeDamage\(\): ([A-Za-z0-9_]+)_C_\d+: ([0-9.]+) damage attempt by causer ([A-Za-z0-9_]+)_C_\d+ instigator (.+) with damage type ([A-Za-z0-9_]+)_C health remaining ([0-9.]+)`)
+
+const (
+	_ = iota
+	deployableDamagedDeployable
+	deployableDamagedDamage
+	deployableDamagedWeapon
+	deployableDamagedAttackerName
+	deployableDamagedDamageType
+	deployableDamagedHealthRemaining
+)
+
+var vehicleDamagedRegex = regexp.MustCompile(`^LogSquadTrace: \[DedicatedServer\](?:ASQVehicleSeat::)?TakeDamage\(\): \[(.+?)\] ([A-Za-z0-9_]+?)(?:_C_\d+)?: ([0-9.]+) damage taken by causer ([A-Za-z0-9_]+?)(?:_C_\d+)? instigator (.+?) health remaining ([0-9.]+)`)
+
+const (
+	_ = iota
+	vehicleDamagedAttackerName
+	vehicleDamagedVehicle
+	vehicleDamagedDamage
+	vehicleDamagedCauser
+	vehicleDamagedInstigator
+	vehicleDamagedHealthRemaining
+)
+
+var newGameRegex = regexp.MustCompile(`^LogWorld: Bringing World /[A-Za-z]+/(?:Maps/)?([A-Za-z0-9-]+)/(?:.+/)?([A-Za-z0-9_-]+)(?:\.[A-Za-z0-9_-]+) up for play`)
+
+const (
+	_ = iota
+	newGameLevel
+	newGameLayer
+)
+
+var roundEndedRegex = regexp.MustCompile(`^LogSquadGameEvents: Display: Team (\d+), (.*) \( ?(.*?) ?\) has won the match with (\d+) Tickets on layer (.*) \(level (.*)\)!`)
+
+const (
+	_ = iota
+	roundEndedTeamIndex
+	roundEndedUnit
+	roundEndedFaction
+	roundEndedTickets
+	roundEndedLayer
+	roundEndedLevel
+)
+
+func defaultLinePatterns() []LinePattern {
+	return []LinePattern{
+		{Regex: playerDamagedRegex, Parse: parsePlayerDamaged},
+		{Regex: playerWoundedRegex, Parse: parsePlayerWounded},
+		{Regex: playerDiedRegex, Parse: parsePlayerDied},
+		{Regex: playerRevivedRegex, Parse: parsePlayerRevived},
+		{Regex: deployableDamagedRegex, Parse: parseDeployableDamaged},
+		{Regex: vehicleDamagedRegex, Parse: parseVehicleDamaged},
+		{Regex: playerConnectedRegex, Parse: parsePlayerConnected},
+		{Regex: playerDisconnectedRegex, Parse: parsePlayerDisconnected},
+		{Regex: newGameRegex, Parse: parseNewGame},
+		{Regex: roundEndedRegex, Parse: parseRoundEnded},
+	}
+}
+
+// parseOnlineIds parses the IDs of `Online IDs: EOS: <id> steam: <id>`. The Steam ID is optional.
+func parseOnlineIds(eosId string, steamId string) (squadrcon.SteamID64, squadrcon.EOSID, error) {
+	parsedEosId, err := squadrcon.ParseEOSID(eosId)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var parsedSteamId squadrcon.SteamID64
+	if steamId != "" {
+		if parsedSteamId, err = squadrcon.ParseSteamID64(steamId); err != nil {
+			return 0, "", err
+		}
+	}
+
+	return parsedSteamId, parsedEosId, nil
+}
+
+func parsePlayerConnected(header LineHeader, matches []string) (Event, error) {
+	steamId, eosId, err := parseOnlineIds(
+		matches[playerConnectedEosId],
+		matches[playerConnectedSteamId],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return PlayerConnectedEvent{
+		LineHeader: header,
+		Player: PlayerRef{
+			SteamId:    steamId,
+			EosId:      eosId,
+			Controller: matches[playerConnectedController],
+		},
+		Ip: matches[playerConnectedIp],
+	}, nil
+}
+
+func parsePlayerDisconnected(header LineHeader, matches []string) (Event, error) {
+	eosId, err := squadrcon.ParseEOSID(matches[playerDisconnectedEosId])
+	if err != nil {
+		return nil, err
+	}
+
+	return PlayerDisconnectedEvent{
+		LineHeader: header,
+		Player: PlayerRef{
+			EosId:      eosId,
+			Controller: matches[playerDisconnectedController],
+		},
+		Ip: matches[playerDisconnectedIp],
+	}, nil
+}
+
+func parsePlayerDamaged(header LineHeader, matches []string) (Event, error) {
+	damage, err := strconv.ParseFloat(matches[playerDamagedDamage], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	steamId, eosId, err := parseOnlineIds(
+		matches[playerDamagedAttackerEosId],
+		matches[playerDamagedAttackerSteamId],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return PlayerDamagedEvent{
+		LineHeader: header,
+		Victim: PlayerRef{
+			Name: matches[playerDamagedVictimName],
+		},
+		Attacker: PlayerRef{
+			Name:       matches[playerDamagedAttackerName],
+			SteamId:    steamId,
+			EosId:      eosId,
+			Controller: matches[playerDamagedAttackerController],
+		},
+		Damage: damage,
+		Weapon: matches[playerDamagedWeapon],
+	}, nil
+}
+
+// parsePlayerKilled parses the shared format of wound and death lines.
+func parsePlayerKilled(matches []string) (victim PlayerRef, attacker PlayerRef, damage float64, err error) {
+	damage, err = strconv.ParseFloat(matches[playerKilledDamage], 64)
+	if err != nil {
+		return
+	}
+
+	steamId, eosId, err := parseOnlineIds(
+		matches[playerKilledAttackerEosId],
+		matches[playerKilledAttackerSteamId],
+	)
+	if err != nil {
+		return
+	}
+
+	victim = PlayerRef{
+		Name: matches[playerKilledVictimName],
+	}
+	attacker = PlayerRef{
+		SteamId:    steamId,
+		EosId:      eosId,
+		Controller: matches[playerKilledAttackerController],
+	}
+
+	return
+}
+
+func parsePlayerWounded(header LineHeader, matches []string) (Event, error) {
+	victim, attacker, damage, err := parsePlayerKilled(matches)
+	if err != nil {
+		return nil, err
+	}
+
+	return PlayerWoundedEvent{
+		LineHeader: header,
+		Victim:     victim,
+		Attacker:   attacker,
+		Damage:     damage,
+		Weapon:     matches[playerKilledWeapon],
+	}, nil
+}
+
+func parsePlayerDied(header LineHeader, matches []string) (Event, error) {
+	victim, attacker, damage, err := parsePlayerKilled(matches)
+	if err != nil {
+		return nil, err
+	}
+
+	return PlayerDiedEvent{
+		LineHeader: header,
+		Victim:     victim,
+		Attacker:   attacker,
+		Damage:     damage,
+		Weapon:     matches[playerKilledWeapon],
+	}, nil
+}
+
+func parsePlayerRevived(header LineHeader, matches []string) (Event, error) {
+	reviverSteamId, reviverEosId, err := parseOnlineIds(
+		matches[playerRevivedReviverEosId],
+		matches[playerRevivedReviverSteamId],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	victimSteamId, victimEosId, err := parseOnlineIds(
+		matches[playerRevivedVictimEosId],
+		matches[playerRevivedVictimSteamId],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return PlayerRevivedEvent{
+		LineHeader: header,
+		Reviver: PlayerRef{
+			Name:    matches[playerRevivedReviverName],
+			SteamId: reviverSteamId,
+			EosId:   reviverEosId,
+		},
+		Victim: PlayerRef{
+			Name:    matches[playerRevivedVictimName],
+			SteamId: victimSteamId,
+			EosId:   victimEosId,
+		},
+	}, nil
+}
+
+// DeployablePlacedPattern returns a pattern that parses lines matching regex as
+// DeployablePlacedEvent. The submatches of regex are the deployable, the name of the placer and the
+// EOS ID and optional Steam ID of the placer, e.g. the end of a pattern could be
+// `([A-Za-z0-9_]+)_C_\d+ placed by (.+) \(Online IDs: EOS: ([0-9a-fA-F]{32})(?: steam: (\d+))?\)`.
+func DeployablePlacedPattern(regex *regexp.Regexp) LinePattern {
+	return LinePattern{
+		Regex: regex,
+		Parse: func(header LineHeader, matches []string) (Event, error) {
+			if len(matches) <= deployablePlacedPlacerSteamId {
+				return nil, fmt.Errorf("deployable placed pattern %s has fewer than 4 submatches", regex)
+			}
+
+			return parseDeployablePlaced(header, matches)
+		},
+	}
+}
+
+func parseDeployablePlaced(header LineHeader, matches []string) (Event, error) {
+	steamId, eosId, err := parseOnlineIds(
+		matches[deployablePlacedPlacerEosId],
+		matches[deployablePlacedPlacerSteamId],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeployablePlacedEvent{
+		LineHeader: header,
+		Deployable: matches[deployablePlacedDeployable],
+		Placer: PlayerRef{
+			Name:    matches[deployablePlacedPlacerName],
+			SteamId: steamId,
+			EosId:   eosId,
+		},
+	}, nil
+}
+
+func parseDeployableDamaged(header LineHeader, matches []string) (Event, error) {
+	damage, err := strconv.ParseFloat(matches[deployableDamagedDamage], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	healthRemaining, err := strconv.ParseFloat(matches[deployableDamagedHealthRemaining], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeployableDamagedEvent{
+		LineHeader: header,
+		Deployable: matches[deployableDamagedDeployable],
+		Damage:     damage,
+		Weapon:     matches[deployableDamagedWeapon],
+		DamageType: matches[deployableDamagedDamageType],
+		Attacker: PlayerRef{
+			Name: matches[deployableDamagedAttackerName],
+		},
+		HealthRemaining: healthRemaining,
+	}, nil
+}
+
+func parseVehicleDamaged(header LineHeader, matches []string) (Event, error) {
+	damage, err := strconv.ParseFloat(matches[vehicleDamagedDamage], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	healthRemaining, err := strconv.ParseFloat(matches[vehicleDamagedHealthRemaining], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return VehicleDamagedEvent{
+		LineHeader: header,
+		Vehicle:    matches[vehicleDamagedVehicle],
+		Damage:     damage,
+		Causer:     matches[vehicleDamagedCauser],
+		Attacker: PlayerRef{
+			Name:       matches[vehicleDamagedAttackerName],
+			Controller: matches[vehicleDamagedInstigator],
+		},
+		HealthRemaining: healthRemaining,
+	}, nil
+}
+
+func parseNewGame(header LineHeader, matches []string) (Event, error) {
+	return NewGameEvent{
+		LineHeader: header,
+		Level:      matches[newGameLevel],
+		Layer:      matches[newGameLayer],
+	}, nil
+}
+
+func parseRoundEnded(header LineHeader, matches []string) (Event, error) {
+	teamIndex, err := strconv.Atoi(matches[roundEndedTeamIndex])
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, err := strconv.Atoi(matches[roundEndedTickets])
+	if err != nil {
+		return nil, err
+	}
+
+	return RoundEndedEvent{
+		LineHeader:       header,
+		WinningTeamIndex: teamIndex,
+		WinningFaction:   matches[roundEndedFaction],
+		Tickets:          tickets,
+		Layer:            matches[roundEndedLayer],
+		Level:            matches[roundEndedLevel],
+	}, nil
+}
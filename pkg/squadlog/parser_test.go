@@ -0,0 +1,241 @@
+package squadlog
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readLogSample returns the lines of the code blocks of data/SquadGame.log.md.
+func readLogSample(t *testing.T) []string {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("..", "..", "data", "SquadGame.log.md"))
+	if err != nil {
+		t.Fatalf("could not open sample: %v", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	inCodeBlock := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "```"):
+			inCodeBlock = !inCodeBlock
+		case inCodeBlock:
+			lines = append(lines, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("could not read sample: %v", err)
+	}
+
+	return lines
+}
+
+// withHeader returns a copy of the event with the header replaced.
+func withHeader(event Event, header LineHeader) Event {
+	value := reflect.New(reflect.TypeOf(event)).Elem()
+	value.Set(reflect.ValueOf(event))
+	value.FieldByName("LineHeader").Set(reflect.ValueOf(header))
+	return value.Interface().(Event)
+}
+
+func TestParserSamples(t *testing.T) {
+	const (
+		jonSteamId     = squadrcon.SteamID64(76561197999957991)
+		jonEosId       = squadrcon.EOSID("0002a10386f3487ba4b12b5e5f6a6a1a")
+		jonController  = "BP_PlayerController_C_2130438728"
+		creamanSteamId = squadrcon.SteamID64(76561197989362395)
+		creamanEosId   = squadrcon.EOSID("00022a3b2c1d4e5f60718293a4b5c6d7")
+	)
+
+	jon := PlayerRef{Name: "✯RAIDR✯Jon", SteamId: jonSteamId, EosId: jonEosId}
+	jonAttacker := PlayerRef{Name: "✯RAIDR✯Jon", SteamId: jonSteamId, EosId: jonEosId, Controller: jonController}
+	jonKiller := PlayerRef{SteamId: jonSteamId, EosId: jonEosId, Controller: jonController}
+	creaman := PlayerRef{Name: "✯RAIDR✯creaman"}
+
+	// Expected event of each line of the sample, nil for lines without event.
+	tests := []struct {
+		name  string
+		event Event
+	}{
+		{"new game", NewGameEvent{Level: "Narva", Layer: "Narva_AAS_v1"}},
+		{"connected", PlayerConnectedEvent{
+			Player: PlayerRef{SteamId: jonSteamId, EosId: jonEosId, Controller: jonController},
+			Ip:     "192.168.1.10",
+		}},
+		{"connected without Steam ID", PlayerConnectedEvent{
+			Player: PlayerRef{EosId: "0002f00dbaadf00dbaadf00dbaadf00d", Controller: "BP_PlayerController_C_2130401015"},
+			Ip:     "192.168.1.11",
+		}},
+		{"unknown line", nil},
+		{"damaged", PlayerDamagedEvent{Victim: creaman, Attacker: jonAttacker, Damage: 30, Weapon: "BP_M4_M68"}},
+		{"damaged before wound", PlayerDamagedEvent{Victim: creaman, Attacker: jonAttacker, Damage: 199, Weapon: "BP_M4_M68"}},
+		{"wounded", PlayerWoundedEvent{Victim: creaman, Attacker: jonKiller, Damage: 199, Weapon: "BP_M4_M68"}},
+		{"revived", PlayerRevivedEvent{
+			Reviver: jon,
+			Victim:  PlayerRef{Name: "✯RAIDR✯creaman", SteamId: creamanSteamId, EosId: creamanEosId},
+		}},
+		{"died", PlayerDiedEvent{Victim: creaman, Attacker: jonKiller, Damage: 250, Weapon: "BP_M4_M68"}},
+		{"deployable damaged", DeployableDamagedEvent{
+			Deployable:      "BP_FOBRadio_Woodland",
+			Damage:          350,
+			Weapon:          "BP_Mortarround4",
+			DamageType:      "BP_Fragmentation_DamageType",
+			Attacker:        creaman,
+			HealthRemaining: 150,
+		}},
+		{"vehicle damaged", VehicleDamagedEvent{
+			Vehicle:         "BP_BTR80_RU",
+			Damage:          150,
+			Causer:          "BP_RPG7_Heat_Proj",
+			Attacker:        PlayerRef{Name: "✯RAIDR✯creaman", Controller: "BP_PlayerController_C_2130401015"},
+			HealthRemaining: 850,
+		}},
+		{"disconnected", PlayerDisconnectedEvent{
+			Player: PlayerRef{EosId: jonEosId, Controller: jonController},
+			Ip:     "192.168.1.10",
+		}},
+		{"round ended", RoundEndedEvent{
+			WinningTeamIndex: 1,
+			WinningFaction:   "United States Army",
+			Tickets:          250,
+			Layer:            "Narva AAS v1",
+			Level:            "Narva",
+		}},
+		{"line without header", nil},
+	}
+
+	lines := readLogSample(t)
+	if len(lines) != len(tests) {
+		t.Fatalf("got %d sample lines, expected %d", len(lines), len(tests))
+	}
+
+	parser := NewParser()
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event, ok := parser.Parse(lines[i])
+			if ok != (test.event != nil) {
+				t.Fatalf("Parse() ok = %v for %s", ok, lines[i])
+			}
+			if !ok {
+				return
+			}
+
+			if event.GetRaw() != lines[i] {
+				t.Errorf("GetRaw() = %s, expected %s", event.GetRaw(), lines[i])
+			}
+
+			if got := withHeader(event, LineHeader{}); !reflect.DeepEqual(got, test.event) {
+				t.Errorf("Parse() = %+v, expected %+v", got, test.event)
+			}
+		})
+	}
+}
+
+func TestParserRegister(t *testing.T) {
+	line := "[2023.12.01-00.05.47:513][231]LogSquad: USQGameState: Server Tick Rate: 49.95"
+
+	parser := NewParser()
+	if _, ok := parser.Parse(line); ok {
+		t.Fatalf("Parse() recognised %s", line)
+	}
+
+	type tickRateEvent struct{ LineHeader }
+	parser.Register(LinePattern{
+		Regex: regexp.MustCompile(`^LogSquad: USQGameState: Server Tick Rate: ([0-9.]+)`),
+		Parse: func(header LineHeader, matches []string) (Event, error) {
+			return tickRateEvent{header}, nil
+		},
+	})
+	event, ok := parser.Parse(line)
+	if _, isTickRate := event.(tickRateEvent); !ok || !isTickRate {
+		t.Errorf("Parse() = %v, %v, expected the registered event", event, ok)
+	}
+}
+
+func TestDeployablePlacedPattern(t *testing.T) {
+	// A synthetic line, the format of deployable placements has not been captured yet.
+	line := "[2023.12.01-00.07.12:330][612]LogTest: Placed BP_Hab_Test_C_1 by Test Player (Online IDs: EOS: 0002a10386f3487ba4b12b5e5f6a6a1a steam: 76561197999957991)"
+
+	parser := NewParser()
+	if _, ok := parser.Parse(line); ok {
+		t.Fatalf("Parse() recognised %s without a registered pattern", line)
+	}
+
+	parser.Register(DeployablePlacedPattern(regexp.MustCompile(`^LogTest: Placed ([A-Za-z0-9_]+)_C_\d+ by (.+) \(Online IDs: EOS: ([0-9a-f]{32})(?: steam: (\d+))?\)`)))
+	event, ok := parser.Parse(line)
+	if !ok {
+		t.Fatalf("Parse() did not recognise %s", line)
+	}
+
+	expected := DeployablePlacedEvent{
+		Deployable: "BP_Hab_Test",
+		Placer:     PlayerRef{Name: "Test Player", SteamId: 76561197999957991, EosId: "0002a10386f3487ba4b12b5e5f6a6a1a"},
+	}
+	if got := withHeader(event, LineHeader{}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Parse() = %+v, expected %+v", got, expected)
+	}
+
+	// Patterns with too few submatches do not match.
+	parser = NewParser()
+	parser.Register(DeployablePlacedPattern(regexp.MustCompile(`^LogTest: Placed ([A-Za-z0-9_]+)_C_\d+ by (.+) \(`)))
+	if event, ok := parser.Parse(line); ok {
+		t.Errorf("Parse() = %+v, expected the pattern to be rejected", event)
+	}
+}
+
+func TestParseLineHeader(t *testing.T) {
+	tests := []struct {
+		line    string
+		time    time.Time
+		chainId int
+		body    string
+		err     error
+	}{
+		{
+			line:    "[2023.12.01-00.05.51:123][456]LogSquad: text",
+			time:    time.Date(2023, 12, 1, 0, 5, 51, 123_000_000, time.UTC),
+			chainId: 456,
+			body:    "LogSquad: text",
+		},
+		{
+			line:    "[2023.12.01-00.10.02:617][  3]LogSquadTrace: text",
+			time:    time.Date(2023, 12, 1, 0, 10, 2, 617_000_000, time.UTC),
+			chainId: 3,
+			body:    "LogSquadTrace: text",
+		},
+		{
+			line: "LogSquad: text",
+			err:  ErrLineHasNoHeader,
+		},
+	}
+
+	for _, test := range tests {
+		header, body, err := ParseLineHeader(test.line)
+		if !errors.Is(err, test.err) {
+			t.Errorf("ParseLineHeader(%s) error = %v, expected %v", test.line, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if !header.Time.Equal(test.time) || header.ChainId != test.chainId || header.Raw != test.line || body != test.body {
+			t.Errorf("ParseLineHeader(%s) = %+v, %s", test.line, header, body)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package squadlog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTailPollInterval = time.Second
+)
+
+type TailerSettings struct {
+	// PollInterval is the time between checks for new data when the end of the file is reached.
+	// Defaults to 1 second.
+	PollInterval time.Duration
+
+	// FromStart reads the lines already in the file. By default, only lines written after the
+	// tailer is created are read.
+	FromStart bool
+
+	// OnError is called when reading fails. Reading is retried after PollInterval. Optional.
+	OnError func(err error)
+}
+
+// Tailer follows a log file and emits every complete line. Rotation, where the file is replaced by
+// a new file, and truncation are detected, after which reading continues from the start of the
+// file.
+type Tailer struct {
+	path     string
+	settings TailerSettings
+	lines    chan string
+	done     chan struct{}
+	stopOnce sync.Once
+
+	file   *os.File
+	info   fs.FileInfo
+	reader *bufio.Reader
+	offset int64
+
+	// Data of an incomplete line.
+	partial strings.Builder
+}
+
+// NewTailer creates a Tailer and starts following the file. The file does not need to exist yet.
+// Close stops following.
+func NewTailer(path string, settings TailerSettings) *Tailer {
+	if settings.PollInterval <= 0 {
+		settings.PollInterval = defaultTailPollInterval
+	}
+
+	t := &Tailer{
+		path:     path,
+		settings: settings,
+		lines:    make(chan string, 256),
+		done:     make(chan struct{}),
+	}
+
+	go t.run()
+
+	return t
+}
+
+// Lines returns the channel on which lines are delivered, without line terminators. It is closed
+// after Close.
+func (t *Tailer) Lines() <-chan string {
+	return t.lines
+}
+
+func (t *Tailer) Close() {
+	t.stopOnce.Do(func() {
+		close(t.done)
+	})
+}
+
+func (t *Tailer) run() {
+	defer close(t.lines)
+	defer func() {
+		if t.file != nil {
+			_ = t.file.Close()
+		}
+	}()
+
+	seekEnd := !t.settings.FromStart
+
+	for {
+		if t.file == nil {
+			if err := t.open(seekEnd); err != nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.reportError(err)
+				}
+
+				// A file that is created later is read from the start.
+				seekEnd = false
+				if !t.wait() {
+					return
+				}
+				continue
+			}
+		}
+
+		if !t.readLines() {
+			return
+		}
+
+		if err := t.checkReplaced(); err != nil {
+			t.reportError(err)
+		}
+
+		if !t.wait() {
+			return
+		}
+	}
+}
+
+func (t *Tailer) open(seekEnd bool) error {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	t.offset = 0
+	if seekEnd {
+		if t.offset, err = file.Seek(0, io.SeekEnd); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+
+	t.file = file
+	t.info = info
+	t.reader = bufio.NewReader(file)
+	t.partial.Reset()
+
+	return nil
+}
+
+// readLines emits all complete lines up to the end of the file. Returns false if the tailer is
+// closed.
+func (t *Tailer) readLines() bool {
+	for {
+		chunk, err := t.reader.ReadString('\n')
+		t.offset += int64(len(chunk))
+		t.partial.WriteString(chunk)
+
+		if strings.HasSuffix(chunk, "\n") {
+			line := strings.TrimRight(t.partial.String(), "\r\n")
+			t.partial.Reset()
+
+			select {
+			case t.lines <- line:
+			case <-t.done:
+				return false
+			}
+		}
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.reportError(err)
+			}
+			return true
+		}
+	}
+}
+
+// checkReplaced reopens the file when it was rotated and rewinds when it was truncated.
+func (t *Tailer) checkReplaced() error {
+	info, err := os.Stat(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Rotation in progress, the new file is picked up once it exists.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(info, t.info) {
+		// The remainder of the old file was read by readLines, continue with the new file.
+		_ = t.file.Close()
+		t.file = nil
+		if err := t.open(false); err != nil {
+			return fmt.Errorf("failed to open rotated log file: %w", err)
+		}
+		return nil
+	}
+
+	if info.Size() < t.offset {
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind truncated log file: %w", err)
+		}
+
+		t.offset = 0
+		t.reader.Reset(t.file)
+		t.partial.Reset()
+	}
+
+	return nil
+}
+
+// wait waits for the poll interval. Returns false if the tailer is closed.
+func (t *Tailer) wait() bool {
+	timer := time.NewTimer(t.settings.PollInterval)
+	defer timer.Stop()
+
+	select {
+	case <-t.done:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (t *Tailer) reportError(err error) {
+	if t.settings.OnError != nil {
+		t.settings.OnError(err)
+	}
+}
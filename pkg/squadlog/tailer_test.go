@@ -0,0 +1,188 @@
+package squadlog
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testPollInterval = 10 * time.Millisecond
+
+// nextLine returns the next line of the tailer, waiting up to a second.
+func nextLine(t *testing.T, tailer *Tailer) string {
+	t.Helper()
+
+	select {
+	case line := <-tailer.Lines():
+		return line
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a line")
+		return ""
+	}
+}
+
+// expectLines fails the test unless the tailer emits exactly the lines.
+func expectLines(t *testing.T, tailer *Tailer, lines ...string) {
+	t.Helper()
+
+	for _, expected := range lines {
+		if line := nextLine(t, tailer); line != expected {
+			t.Fatalf("got %q, expected %q", line, expected)
+		}
+	}
+
+	select {
+	case line := <-tailer.Lines():
+		t.Fatalf("got %q, expected no more lines", line)
+	case <-time.After(5 * testPollInterval):
+	}
+}
+
+func appendFile(t *testing.T, path string, data string) {
+	t.Helper()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("could not open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(data); err != nil {
+		t.Fatalf("could not write %s: %v", path, err)
+	}
+}
+
+func newTestTailer(t *testing.T, path string, fromStart bool) *Tailer {
+	t.Helper()
+
+	tailer := NewTailer(path, TailerSettings{
+		PollInterval: testPollInterval,
+		FromStart:    fromStart,
+		OnError: func(err error) {
+			t.Errorf("unexpected error: %v", err)
+		},
+	})
+	t.Cleanup(tailer.Close)
+
+	return tailer
+}
+
+func TestTailerPartialLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SquadGame.log")
+	appendFile(t, path, "first\r\nsec")
+
+	tailer := newTestTailer(t, path, true)
+	expectLines(t, tailer, "first")
+
+	appendFile(t, path, "ond\nthird\n")
+	expectLines(t, tailer, "second", "third")
+}
+
+func TestTailerSkipsExistingLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SquadGame.log")
+	appendFile(t, path, "existing\n")
+
+	tailer := newTestTailer(t, path, false)
+
+	// The tailer seeks to the end asynchronously, lines written until then are skipped as well.
+	for i := 0; ; i++ {
+		appendFile(t, path, "new "+strconv.Itoa(i)+"\n")
+
+		select {
+		case line := <-tailer.Lines():
+			if line == "existing" {
+				t.Fatal("got the existing line, expected it to be skipped")
+			}
+			return
+		case <-time.After(testPollInterval):
+		}
+
+		if i == 100 {
+			t.Fatal("timed out waiting for a line")
+		}
+	}
+}
+
+func TestTailerWaitsForFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SquadGame.log")
+	tailer := newTestTailer(t, path, false)
+	time.Sleep(2 * testPollInterval)
+
+	// A file created after the tailer is read from the start.
+	appendFile(t, path, "first\n")
+	expectLines(t, tailer, "first")
+}
+
+func TestTailerRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SquadGame.log")
+	appendFile(t, path, "first\n")
+
+	tailer := newTestTailer(t, path, true)
+	expectLines(t, tailer, "first")
+
+	// Lines written before the rename are read from the old file, then the new file is read.
+	appendFile(t, path, "last of old file\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("could not rotate: %v", err)
+	}
+	appendFile(t, path, "first of new file\n")
+	expectLines(t, tailer, "last of old file", "first of new file")
+
+	// The old file is not followed after the rotation.
+	appendFile(t, path+".1", "written to old file\n")
+	appendFile(t, path, "second of new file\n")
+	expectLines(t, tailer, "second of new file")
+}
+
+func TestTailerRecreation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SquadGame.log")
+	appendFile(t, path, "first\n")
+
+	tailer := newTestTailer(t, path, true)
+	expectLines(t, tailer, "first")
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("could not remove: %v", err)
+	}
+	time.Sleep(2 * testPollInterval)
+
+	appendFile(t, path, "recreated\n")
+	expectLines(t, tailer, "recreated")
+}
+
+func TestTailerTruncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SquadGame.log")
+	appendFile(t, path, "first line\nsecond line\n")
+
+	tailer := newTestTailer(t, path, true)
+	expectLines(t, tailer, "first line", "second line")
+
+	// The file is shorter than the offset of the tailer, reading restarts at the beginning.
+	if err := os.WriteFile(path, []byte("truncated\n"), 0o644); err != nil {
+		t.Fatalf("could not truncate: %v", err)
+	}
+	expectLines(t, tailer, "truncated")
+
+	appendFile(t, path, "appended\n")
+	expectLines(t, tailer, "appended")
+}
+
+func TestTailerClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SquadGame.log")
+	appendFile(t, path, "first\n")
+
+	tailer := newTestTailer(t, path, true)
+	expectLines(t, tailer, "first")
+	tailer.Close()
+
+	select {
+	case _, ok := <-tailer.Lines():
+		if ok {
+			t.Error("got a line, expected the channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("timed out waiting for the channel to be closed")
+	}
+}
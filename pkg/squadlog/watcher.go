@@ -0,0 +1,83 @@
+package squadlog
+
+import (
+	"squad-rcon-go/pkg/squadrcon"
+	"sync"
+)
+
+// TopicGameLog carries the events of a Watcher, see Event.
+const TopicGameLog squadrcon.Topic = "gamelog"
+
+type WatcherSettings struct {
+	// Tailer configures how the log file is followed.
+	Tailer TailerSettings
+
+	// Parser converts lines into events. Defaults to NewParser().
+	Parser *Parser
+
+	// Players returns the current RCON roster, used to correlate events with players, e.g.
+	// RosterTracker.Players. Optional, events are not correlated if nil.
+	Players func() []squadrcon.ActivePlayer
+
+	// EventBus receives all events on the game log topic. Optional.
+	EventBus *squadrcon.EventBus
+
+	// OnEvent is called for every event. Optional.
+	OnEvent func(event Event)
+}
+
+// Watcher tails a SquadGame.log, parses its lines and publishes the resulting events.
+type Watcher struct {
+	tailer   *Tailer
+	settings WatcherSettings
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher creates a Watcher and starts following the log file. Close stops following.
+func NewWatcher(path string, settings WatcherSettings) *Watcher {
+	if settings.Parser == nil {
+		settings.Parser = NewParser()
+	}
+
+	w := &Watcher{
+		tailer:   NewTailer(path, settings.Tailer),
+		settings: settings,
+		done:     make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Close stops following the log file. Returns once no more events are emitted.
+func (w *Watcher) Close() {
+	w.stopOnce.Do(func() {
+		w.tailer.Close()
+	})
+	<-w.done
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	for line := range w.tailer.Lines() {
+		event, ok := w.settings.Parser.Parse(line)
+		if !ok {
+			continue
+		}
+
+		if w.settings.Players != nil {
+			event = Correlate(event, w.settings.Players())
+		}
+
+		if w.settings.EventBus != nil {
+			w.settings.EventBus.Publish(TopicGameLog, event)
+		}
+
+		if w.settings.OnEvent != nil {
+			w.settings.OnEvent(event)
+		}
+	}
+}
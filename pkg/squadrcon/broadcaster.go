@@ -0,0 +1,296 @@
+package squadrcon
+
+import (
+	"container/heap"
+	"squad-rcon-go/pkg/rcon"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type BroadcastPriority int
+
+const (
+	BroadcastPriorityLow BroadcastPriority = iota
+	BroadcastPriorityNormal
+	// BroadcastPriorityUrgent messages pre-empt the display of lower priority messages.
+	BroadcastPriorityUrgent
+)
+
+const (
+	defaultBroadcastDisplayDuration  = 10 * time.Second
+	defaultBroadcastMaxMessageLength = 200
+)
+
+type BroadcasterSettings struct {
+	// DisplayDuration is the time a message is shown before the next message is broadcast.
+	// Defaults to 10 seconds.
+	DisplayDuration time.Duration
+
+	// DeduplicationWindow is the period in which a message identical to a previously queued
+	// message is dropped. Zero disables deduplication.
+	DeduplicationWindow time.Duration
+
+	// MaxMessageLength is the maximum amount of characters of a single broadcast. Longer messages
+	// are split on word boundaries and broadcast consecutively. Defaults to 200.
+	MaxMessageLength int
+
+	// OnError is called when a broadcast fails. Optional.
+	OnError func(err error)
+}
+
+// Broadcaster queues messages for AdminBroadcast so that each message is shown for the configured
+// display duration before the next one replaces it.
+type Broadcaster struct {
+	rcon     rcon.Rcon
+	settings BroadcasterSettings
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// Closed when the broadcaster is closed.
+	done chan struct{}
+
+	// Contains the priority of the message that is currently displayed, if any.
+	displaying *BroadcastPriority
+
+	// Messages waiting to be broadcast.
+	queue broadcastQueue
+
+	// The time at which each message was last queued, used for deduplication.
+	recent map[string]time.Time
+
+	// Counter used to keep broadcasts of the same priority in order.
+	sequence uint64
+
+	// Signals that the queue changed.
+	queued chan struct{}
+
+	// Signals that a message with a higher priority than the displayed message was queued.
+	preempt chan struct{}
+}
+
+// NewBroadcaster creates a Broadcaster and starts processing its queue. Close stops processing.
+func NewBroadcaster(rcon rcon.Rcon, settings BroadcasterSettings) *Broadcaster {
+	if settings.DisplayDuration <= 0 {
+		settings.DisplayDuration = defaultBroadcastDisplayDuration
+	}
+
+	if settings.MaxMessageLength <= 0 {
+		settings.MaxMessageLength = defaultBroadcastMaxMessageLength
+	}
+
+	b := &Broadcaster{
+		rcon:     rcon,
+		settings: settings,
+		done:     make(chan struct{}),
+		recent:   make(map[string]time.Time),
+		queued:   make(chan struct{}, 1),
+		preempt:  make(chan struct{}, 1),
+	}
+
+	go b.run()
+
+	return b
+}
+
+// Broadcast queues the message. Returns false if the message was dropped because it is empty,
+// the broadcaster is closed or an identical message was queued within the deduplication window.
+func (b *Broadcaster) Broadcast(message string, priority BroadcastPriority) bool {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return false
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	select {
+	case <-b.done:
+		return false
+	default:
+	}
+
+	now := time.Now()
+	if b.settings.DeduplicationWindow > 0 {
+		for recentMessage, queuedAt := range b.recent {
+			if now.Sub(queuedAt) >= b.settings.DeduplicationWindow {
+				delete(b.recent, recentMessage)
+			}
+		}
+
+		if _, exists := b.recent[message]; exists {
+			return false
+		}
+
+		b.recent[message] = now
+	}
+
+	for _, part := range splitMessage(message, b.settings.MaxMessageLength) {
+		heap.Push(&b.queue, &queuedBroadcast{
+			message:  part,
+			priority: priority,
+			sequence: b.sequence,
+		})
+		b.sequence++
+	}
+
+	signal(b.queued)
+	if b.displaying != nil && priority > *b.displaying {
+		signal(b.preempt)
+	}
+
+	return true
+}
+
+// Pending returns the amount of queued broadcasts, including the parts of split messages.
+func (b *Broadcaster) Pending() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.queue.Len()
+}
+
+// Close stops broadcasting. Queued messages are discarded.
+func (b *Broadcaster) Close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	select {
+	case <-b.done:
+	default:
+		close(b.done)
+	}
+}
+
+func (b *Broadcaster) run() {
+	for {
+		b.lock.Lock()
+		for b.queue.Len() == 0 {
+			b.displaying = nil
+			b.lock.Unlock()
+
+			select {
+			case <-b.done:
+				return
+			case <-b.queued:
+			}
+
+			b.lock.Lock()
+		}
+
+		next := heap.Pop(&b.queue).(*queuedBroadcast)
+		b.displaying = &next.priority
+
+		// Only messages queued during the display of this message may pre-empt it.
+		select {
+		case <-b.preempt:
+		default:
+		}
+		b.lock.Unlock()
+
+		if err := AdminBroadcast(b.rcon, next.message); err != nil && b.settings.OnError != nil {
+			b.settings.OnError(err)
+		}
+
+		timer := time.NewTimer(b.settings.DisplayDuration)
+		select {
+		case <-b.done:
+			timer.Stop()
+			return
+		case <-b.preempt:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// signal performs a non-blocking send on a channel with a buffer of one.
+func signal(channel chan struct{}) {
+	select {
+	case channel <- struct{}{}:
+	default:
+	}
+}
+
+// splitMessage splits the message into parts of at most maxLength characters. Messages are split
+// on whitespace when possible.
+func splitMessage(message string, maxLength int) []string {
+	var parts []string
+	var current strings.Builder
+	currentLength := 0
+
+	flush := func() {
+		if currentLength > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+			currentLength = 0
+		}
+	}
+
+	for _, word := range strings.Fields(message) {
+		wordLength := utf8.RuneCountInString(word)
+
+		for wordLength > maxLength {
+			flush()
+			runes := []rune(word)
+			parts = append(parts, string(runes[:maxLength]))
+			word = string(runes[maxLength:])
+			wordLength -= maxLength
+		}
+
+		if currentLength > 0 && currentLength+1+wordLength > maxLength {
+			flush()
+		}
+
+		if currentLength > 0 {
+			current.WriteByte(' ')
+			currentLength++
+		}
+
+		current.WriteString(word)
+		currentLength += wordLength
+	}
+
+	flush()
+
+	return parts
+}
+
+type queuedBroadcast struct {
+	message  string
+	priority BroadcastPriority
+	sequence uint64
+}
+
+// broadcastQueue implements heap.Interface. Higher priorities come first, equal priorities are
+// ordered first in, first out.
+type broadcastQueue []*queuedBroadcast
+
+func (q broadcastQueue) Len() int {
+	return len(q)
+}
+
+func (q broadcastQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+
+	return q[i].sequence < q[j].sequence
+}
+
+func (q broadcastQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *broadcastQueue) Push(x any) {
+	*q = append(*q, x.(*queuedBroadcast))
+}
+
+func (q *broadcastQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return item
+}
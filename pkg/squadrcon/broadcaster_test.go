@@ -0,0 +1,178 @@
+package squadrcon
+
+import (
+	"container/heap"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRcon passes executed commands to a channel and answers them with canned responses.
+type fakeRcon struct {
+	responses map[string]string
+	commands  chan string
+}
+
+func newFakeRcon(responses map[string]string) *fakeRcon {
+	return &fakeRcon{responses: responses, commands: make(chan string, 64)}
+}
+
+func (r *fakeRcon) Execute(command string) (string, error) {
+	r.commands <- command
+	return r.responses[command], nil
+}
+
+func (r *fakeRcon) Close() error {
+	return nil
+}
+
+// next returns the next executed command, waiting up to a second.
+func (r *fakeRcon) next(t *testing.T) string {
+	t.Helper()
+
+	select {
+	case command := <-r.commands:
+		return command
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a command")
+		return ""
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		maxLength int
+		expected  []string
+	}{
+		{"empty", "  ", 10, nil},
+		{"short", "Seeding rules apply", 50, []string{"Seeding rules apply"}},
+		{"exactly the limit", "0123456789", 10, []string{"0123456789"}},
+		{"whitespace is collapsed", " Seeding \n rules\tapply ", 50, []string{"Seeding rules apply"}},
+		{"word boundary at the limit", "Seeding rules apply", 13, []string{"Seeding rules", "apply"}},
+		{"word boundary before the limit", "Seeding rules apply", 12, []string{"Seeding", "rules apply"}},
+		{"long word", "abcdefghijklmnopqrstuvwxyz end", 10, []string{"abcdefghij", "klmnopqrst", "uvwxyz end"}},
+		{"long word at the limit", "ab abcdefghij", 10, []string{"ab", "abcdefghij"}},
+		{"runes are counted", "✯✯✯✯✯ ✯✯✯✯", 10, []string{"✯✯✯✯✯ ✯✯✯✯"}},
+		{"runes are not split", "✯✯✯✯✯✯", 4, []string{"✯✯✯✯", "✯✯"}},
+	}
+
+	for _, test := range tests {
+		parts := splitMessage(test.message, test.maxLength)
+		if !reflect.DeepEqual(parts, test.expected) {
+			t.Errorf("%s: got %q, expected %q", test.name, parts, test.expected)
+		}
+	}
+}
+
+func TestBroadcastQueue(t *testing.T) {
+	// A broadcaster that does not run, so the queue can be inspected.
+	b := &Broadcaster{
+		settings: BroadcasterSettings{MaxMessageLength: 20, DeduplicationWindow: time.Hour},
+		done:     make(chan struct{}),
+		recent:   make(map[string]time.Time),
+		queued:   make(chan struct{}, 1),
+		preempt:  make(chan struct{}, 1),
+	}
+
+	tests := []struct {
+		message  string
+		priority BroadcastPriority
+		queued   bool
+	}{
+		{"first low", BroadcastPriorityLow, true},
+		{"first normal", BroadcastPriorityNormal, true},
+		{"urgent message that is split", BroadcastPriorityUrgent, true},
+		{"second low", BroadcastPriorityLow, true},
+		{"second normal", BroadcastPriorityNormal, true},
+		{" first normal ", BroadcastPriorityUrgent, false},
+		{"", BroadcastPriorityUrgent, false},
+	}
+
+	for _, test := range tests {
+		if queued := b.Broadcast(test.message, test.priority); queued != test.queued {
+			t.Errorf("Broadcast(%q) = %v, expected %v", test.message, queued, test.queued)
+		}
+	}
+
+	if pending := b.Pending(); pending != 6 {
+		t.Errorf("got %d pending broadcasts, expected 6", pending)
+	}
+
+	var messages []string
+	for b.queue.Len() > 0 {
+		messages = append(messages, heap.Pop(&b.queue).(*queuedBroadcast).message)
+	}
+
+	expected := []string{"urgent message that", "is split", "first normal", "second normal", "first low", "second low"}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("got %q, expected %q", messages, expected)
+	}
+
+	b.Close()
+	if b.Broadcast("after close", BroadcastPriorityUrgent) {
+		t.Error("expected broadcasts to be dropped after Close")
+	}
+}
+
+func TestBroadcasterDeduplicationWindow(t *testing.T) {
+	rcon := newFakeRcon(nil)
+	b := NewBroadcaster(rcon, BroadcasterSettings{DisplayDuration: time.Millisecond, DeduplicationWindow: 50 * time.Millisecond})
+	defer b.Close()
+
+	if !b.Broadcast("Seeding rules apply", BroadcastPriorityNormal) || b.Broadcast("Seeding rules apply", BroadcastPriorityNormal) {
+		t.Fatal("expected the duplicate to be dropped")
+	}
+	rcon.next(t)
+
+	time.Sleep(60 * time.Millisecond)
+	if !b.Broadcast("Seeding rules apply", BroadcastPriorityNormal) {
+		t.Error("expected the message to be queued again after the window")
+	}
+	if command := rcon.next(t); command != "AdminBroadcast Seeding rules apply" {
+		t.Errorf("got %q, expected the message to be broadcast again", command)
+	}
+}
+
+func TestBroadcasterPreemption(t *testing.T) {
+	rcon := newFakeRcon(nil)
+	b := NewBroadcaster(rcon, BroadcasterSettings{DisplayDuration: time.Hour})
+	defer b.Close()
+
+	b.Broadcast("low", BroadcastPriorityLow)
+	if command := rcon.next(t); command != "AdminBroadcast low" {
+		t.Fatalf("got %q, expected the low priority message", command)
+	}
+
+	// Normal messages wait for the display duration, urgent messages replace the low message.
+	b.Broadcast("normal", BroadcastPriorityNormal)
+	b.Broadcast("urgent", BroadcastPriorityUrgent)
+	if command := rcon.next(t); command != "AdminBroadcast urgent" {
+		t.Errorf("got %q, expected the urgent message to pre-empt the low message", command)
+	}
+
+	select {
+	case command := <-rcon.commands:
+		t.Errorf("got %q, expected the normal message to wait for the urgent message", command)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if pending := b.Pending(); pending != 1 {
+		t.Errorf("got %d pending broadcasts, expected the normal message", pending)
+	}
+}
+
+func TestBroadcasterSplitsLongMessages(t *testing.T) {
+	rcon := newFakeRcon(nil)
+	b := NewBroadcaster(rcon, BroadcasterSettings{DisplayDuration: time.Millisecond})
+	defer b.Close()
+
+	message := strings.Repeat("word ", 60)
+	b.Broadcast(message, BroadcastPriorityNormal)
+
+	first, second := rcon.next(t), rcon.next(t)
+	if len(first) != len("AdminBroadcast ")+199 || len(second) != len("AdminBroadcast ")+99 {
+		t.Errorf("got %q and %q, expected the message to be split at 200 characters", first, second)
+	}
+}
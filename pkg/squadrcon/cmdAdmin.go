@@ -0,0 +1,45 @@
+package squadrcon
+
+import (
+	"squad-rcon-go/pkg/rcon"
+	"strconv"
+)
+
+// AdminBroadcast shows a message to all players on the server.
+func AdminBroadcast(rcon rcon.Rcon, message string) error {
+	_, err := execute(rcon, "AdminBroadcast", message)
+	return err
+}
+
+// AdminWarn shows a message to a single player. The player is identified by name, Steam ID or EOS
+// ID. Names containing spaces cannot be used, use an ID instead.
+func AdminWarn(rcon rcon.Rcon, player string, message string) error {
+	_, err := execute(rcon, "AdminWarn", player, message)
+	return err
+}
+
+// AdminWarnById shows a message to the player with the match ID, see ActivePlayer.MatchId.
+func AdminWarnById(rcon rcon.Rcon, matchId int, message string) error {
+	_, err := execute(rcon, "AdminWarnById", strconv.Itoa(matchId), message)
+	return err
+}
+
+// AdminKick kicks a player from the server. The player is identified by name, Steam ID or EOS ID.
+// Names containing spaces cannot be used, use an ID instead.
+func AdminKick(rcon rcon.Rcon, player string, reason string) error {
+	_, err := execute(rcon, "AdminKick", player, reason)
+	return err
+}
+
+// AdminKickById kicks the player with the match ID, see ActivePlayer.MatchId.
+func AdminKickById(rcon rcon.Rcon, matchId int, reason string) error {
+	_, err := execute(rcon, "AdminKickById", strconv.Itoa(matchId), reason)
+	return err
+}
+
+// AdminBan bans a player from the server. The player is identified by name, Steam ID or EOS ID.
+// The interval is the ban length, e.g. `1d`, `12h` or `0` for a permanent ban.
+func AdminBan(rcon rcon.Rcon, player string, interval string, reason string) error {
+	_, err := execute(rcon, "AdminBan", player, interval, reason)
+	return err
+}
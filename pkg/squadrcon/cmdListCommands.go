@@ -0,0 +1,145 @@
+package squadrcon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"squad-rcon-go/pkg/rcon"
+	"strings"
+	"unicode"
+)
+
+// Command describes an RCON command as returned by ListCommands.
+type Command struct {
+	Name string
+
+	// Arguments contains the names of the arguments of the command, without the surrounding
+	// brackets. E.g. `<Name Or Steam Id>` results in `Name Or Steam Id`.
+	Arguments []string
+
+	// Help contains the description of the command. Empty if the command list was requested
+	// without details.
+	Help string
+}
+
+var (
+	ErrResponseIsNotCommandList = errors.New("response returned from rcon is not a command list")
+)
+
+// ListCommands returns the commands that can be executed using the current RCON connection.
+func ListCommands(rcon rcon.Rcon) ([]Command, error) {
+	response, err := execute(rcon, "ListCommands", "1")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseCommandList(response)
+}
+
+// ParseCommandList parses the response of `ListCommands`, with or without details.
+// Lines are expected in the form `Name <Argument> <Argument> (Help text)`.
+func ParseCommandList(commandListString string) ([]Command, error) {
+	var commands []Command
+	var errs []error
+	scanner := bufio.NewScanner(strings.NewReader(commandListString))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		command, err := parseCommandLine(line)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		commands = append(commands, command)
+	}
+
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(commands) == 0 && len(errs) != 0 {
+		return nil, fmt.Errorf("%w: %w", ErrResponseIsNotCommandList, errors.Join(errs...))
+	}
+
+	if len(errs) != 0 {
+		return commands, errors.Join(errs...)
+	}
+
+	return commands, nil
+}
+
+func parseCommandLine(line string) (Command, error) {
+	name, rest, _ := strings.Cut(line, " ")
+	if !isCommandName(name) {
+		return Command{}, fmt.Errorf("line cannot be parsed as a command: %s", line)
+	}
+
+	command := Command{
+		Name: name,
+	}
+	rest = strings.TrimSpace(rest)
+
+	// The help text is the last parenthesized group. It can contain parentheses itself.
+	if strings.HasSuffix(rest, ")") {
+		depth := 0
+		for i := len(rest) - 1; i >= 0; i-- {
+			switch rest[i] {
+			case ')':
+				depth++
+			case '(':
+				depth--
+			}
+
+			if depth == 0 {
+				command.Help = strings.TrimSpace(rest[i+1 : len(rest)-1])
+				rest = strings.TrimSpace(rest[:i])
+				break
+			}
+		}
+	}
+
+	for rest != "" {
+		open := strings.IndexAny(rest, "<[")
+		if open == -1 {
+			break
+		}
+
+		closing := byte('>')
+		if rest[open] == '[' {
+			closing = ']'
+		}
+
+		length := strings.IndexByte(rest[open+1:], closing)
+		if length == -1 {
+			return Command{}, fmt.Errorf("unterminated argument in command line: %s", line)
+		}
+
+		command.Arguments = append(command.Arguments, strings.TrimSpace(rest[open+1:open+1+length]))
+		rest = rest[open+1+length+1:]
+	}
+
+	return command, nil
+}
+
+func isCommandName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		if i == 0 && !unicode.IsLetter(r) {
+			return false
+		}
+
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,79 @@
+package squadrcon
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseCommandList(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected []Command
+	}{
+		{
+			name:     "details",
+			response: "ListPlayers (List player ids with associated player name and SteamId)\nAdminKick <NameOrSteamId> <KickReason> (Kicks a player from the server)\n",
+			expected: []Command{
+				{Name: "ListPlayers", Help: "List player ids with associated player name and SteamId"},
+				{Name: "AdminKick", Arguments: []string{"NameOrSteamId", "KickReason"}, Help: "Kicks a player from the server"},
+			},
+		},
+		{
+			name:     "without details",
+			response: "ListCommands [ShowDetails]\nAdminEndMatch\n",
+			expected: []Command{
+				{Name: "ListCommands", Arguments: []string{"ShowDetails"}},
+				{Name: "AdminEndMatch"},
+			},
+		},
+		{
+			name:     "parentheses in help",
+			response: "AdminSlomo <TimeDilation> (Sets the game speed (1.0 is normal speed))",
+			expected: []Command{
+				{Name: "AdminSlomo", Arguments: []string{"TimeDilation"}, Help: "Sets the game speed (1.0 is normal speed)"},
+			},
+		},
+		{
+			name:     "arguments with spaces",
+			response: "AdminDisbandSquad <TeamNumber = [1|2]> <SquadIndex> (Disbands the specified Squad)",
+			expected: []Command{
+				{Name: "AdminDisbandSquad", Arguments: []string{"TeamNumber = [1|2]", "SquadIndex"}, Help: "Disbands the specified Squad"},
+			},
+		},
+		{
+			name:     "empty lines",
+			response: "\nAdminEndMatch\n\n",
+			expected: []Command{{Name: "AdminEndMatch"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			commands, err := ParseCommandList(test.response)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(commands, test.expected) {
+				t.Errorf("got %+v, expected %+v", commands, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseCommandListInvalid(t *testing.T) {
+	_, err := ParseCommandList("----- Active Players -----\n----- Recently Disconnected Players [Max of 15] -----\n")
+	if !errors.Is(err, ErrResponseIsNotCommandList) {
+		t.Errorf("got %v, expected %v", err, ErrResponseIsNotCommandList)
+	}
+
+	commands, err := ParseCommandList("AdminEndMatch\nAdminKick <NameOrSteamId (Kicks a player)\n")
+	if err == nil {
+		t.Error("expected an error for the unterminated argument")
+	}
+	if len(commands) != 1 || commands[0].Name != "AdminEndMatch" {
+		t.Errorf("expected the valid command to be returned, got %+v", commands)
+	}
+}
@@ -0,0 +1,65 @@
+package squadrcon
+
+import (
+	"errors"
+	"squad-rcon-go/pkg/rcon"
+	"strings"
+)
+
+var (
+	ErrResponseIsNotLayerList = errors.New("response returned from rcon is not a layer list")
+	ErrResponseIsNotLevelList = errors.New("response returned from rcon is not a level list")
+)
+
+const (
+	layerListHeader = "List of available layers :"
+	levelListHeader = "List of available levels :"
+)
+
+// ListLayers returns the names of all layers the server can load, e.g. `Narva_AAS_v1`.
+func ListLayers(rcon rcon.Rcon) ([]string, error) {
+	response, err := execute(rcon, "ListLayers")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseLayerList(response)
+}
+
+// ListLevels returns the names of all levels the server can load, e.g. `Narva`.
+func ListLevels(rcon rcon.Rcon) ([]string, error) {
+	response, err := execute(rcon, "ListLevels")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseLevelList(response)
+}
+
+// ParseLayerList parses the response of ListLayers, which starts with
+// `List of available layers :` followed by one layer per line.
+func ParseLayerList(response string) ([]string, error) {
+	return parseNameList(response, layerListHeader, ErrResponseIsNotLayerList)
+}
+
+// ParseLevelList parses the response of ListLevels, which starts with
+// `List of available levels :` followed by one level per line.
+func ParseLevelList(response string) ([]string, error) {
+	return parseNameList(response, levelListHeader, ErrResponseIsNotLevelList)
+}
+
+func parseNameList(response string, header string, errNotList error) ([]string, error) {
+	lines := strings.Split(strings.ReplaceAll(response, "\r\n", "\n"), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != header {
+		return nil, errNotList
+	}
+
+	var names []string
+	for _, line := range lines[1:] {
+		if name := strings.TrimSpace(line); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
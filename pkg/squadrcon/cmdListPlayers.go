@@ -14,7 +14,11 @@ import (
 type ActivePlayer struct {
 	MatchId int
 
-	SteamId string
+	SteamId SteamID64
+
+	// EosId contains the Epic Online Services ID. Only reported by Squad versions that list
+	// `Online IDs`.
+	EosId EOSID
 
 	Name string
 
@@ -30,10 +34,21 @@ type ActivePlayer struct {
 	Kit string
 }
 
+// PlayerKey returns the ID used to identify a player across polls: the Steam ID, else the EOS ID.
+// Match IDs are reused by the server, so they are only used for players that have neither.
+func PlayerKey(player ActivePlayer) string {
+	if key := idKey(player.SteamId, player.EosId); key != "" {
+		return key
+	}
+	return "match:" + strconv.Itoa(player.MatchId)
+}
+
 type DisconnectedPlayer struct {
 	MatchId int
 
-	SteamId string
+	SteamId SteamID64
+
+	EosId EOSID
 
 	Name string
 
@@ -50,7 +65,7 @@ var (
 )
 
 func ListPlayers(rcon rcon.Rcon) (PlayerList, error) {
-	response, err := rcon.Execute("ListPlayers")
+	response, err := execute(rcon, "ListPlayers")
 	if err != nil {
 		return PlayerList{}, err
 	}
@@ -80,11 +95,18 @@ const (
 const activePlayersHeader = "----- Active Players -----"
 const disconnectedPlayersPrefix = "----- Recently Disconnected Players "
 
-var playerListActivePlayerRegex = regexp.MustCompile(`^ID: (\d+) \| SteamID: (\d+) \| Name: (.+) \| Team ID: (\d+) \| Squad ID: ([^|]+) \| Is Leader: (\w+) \| Role: ([^|]+)$`)
+// onlineIdsPattern matches the player IDs of both the legacy `SteamID: <id>` format and the
+// `Online IDs: EOS: <id> steam: <id>` format. Contains three groups: legacy Steam ID, EOS ID and
+// Steam ID. At most one of the Steam ID groups is non-empty.
+const onlineIdsPattern = `(?:SteamID: (\d+)|Online IDs:(?: EOS: ([0-9a-fA-F]+))?(?: steam: (\d+))?)`
+
+var playerListActivePlayerRegex = regexp.MustCompile(`^ID: (\d+) \| ` + onlineIdsPattern + ` \| Name: (.+) \| Team ID: (\d+) \| Squad ID: ([^|]+) \| Is Leader: (\w+) \| Role: ([^|]+)$`)
 
 const (
 	_ = iota
 	activePlayerMatchId
+	activePlayerLegacySteamId
+	activePlayerEosId
 	activePlayerSteamId
 	activePlayerName
 	activePlayerTeamIndex
@@ -93,11 +115,13 @@ const (
 	activePlayerRole
 )
 
-var playerListDisconnectedPlayerRegex = regexp.MustCompile(`^ID: (\d+) \| SteamID: (\d+) \| Since Disconnect: (\d+)m.(\d+)s \| Name: (.+)$`)
+var playerListDisconnectedPlayerRegex = regexp.MustCompile(`^ID: (\d+) \| ` + onlineIdsPattern + ` \| Since Disconnect: (\d+)m.(\d+)s \| Name: (.+)$`)
 
 const (
 	_ = iota
 	disconnectedPlayerMatchIdIndex
+	disconnectedPlayerLegacySteamIdIndex
+	disconnectedPlayerEosIdIndex
 	disconnectedPlayerSteamIdIndex
 	disconnectedPlayerMinutesIndex
 	disconnectedPlayerSecondsIndex
@@ -138,7 +162,6 @@ func ParsePlayersList(playerListString string) (PlayerList, error) {
 			}
 
 			matches := playerListActivePlayerRegex.FindStringSubmatch(line)
-			fmt.Printf("%q\n", matches)
 
 			if matches == nil {
 				errs = append(
@@ -158,6 +181,15 @@ func ParsePlayersList(playerListString string) (PlayerList, error) {
 				continue
 			}
 
+			steamId, eosId, err := parsePlayerIds(
+				matches[activePlayerLegacySteamId]+matches[activePlayerSteamId],
+				matches[activePlayerEosId],
+			)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("could not parse active player IDs: %w", err))
+				continue
+			}
+
 			var playerSquadIndexString = matches[activePlayerSquadIndex]
 			var playerSquadIndex = 0
 			if playerSquadIndexString != "N/A" {
@@ -193,7 +225,8 @@ func ParsePlayersList(playerListString string) (PlayerList, error) {
 				MatchId:     playerMatchId,
 				Name:        matches[activePlayerName],
 				SquadIndex:  playerSquadIndex,
-				SteamId:     matches[activePlayerSteamId],
+				SteamId:     steamId,
+				EosId:       eosId,
 				TeamIndex:   playerTeamIndex,
 			})
 		case ReadingDisconnectedPlayers:
@@ -220,6 +253,15 @@ func ParsePlayersList(playerListString string) (PlayerList, error) {
 				continue
 			}
 
+			steamId, eosId, err := parsePlayerIds(
+				matches[disconnectedPlayerLegacySteamIdIndex]+matches[disconnectedPlayerSteamIdIndex],
+				matches[disconnectedPlayerEosIdIndex],
+			)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("could not parse disconnected player IDs: %w", err))
+				continue
+			}
+
 			var disconnectedPlayerMinutesString = matches[disconnectedPlayerMinutesIndex]
 			disconnectedMinutes, err := strconv.Atoi(disconnectedPlayerMinutesString)
 			if err != nil {
@@ -249,7 +291,8 @@ func ParsePlayersList(playerListString string) (PlayerList, error) {
 
 			playerList.DisconnectedPlayers = append(playerList.DisconnectedPlayers, DisconnectedPlayer{
 				MatchId:        playerMatchId,
-				SteamId:        matches[disconnectedPlayerSteamIdIndex],
+				SteamId:        steamId,
+				EosId:          eosId,
 				Name:           matches[disconnectedPlayerNameIndex],
 				DisconnectTime: disconnectTime,
 			})
@@ -0,0 +1,142 @@
+package squadrcon
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePlayersListSamples(t *testing.T) {
+	samples := readSamples(t, "ListPlayers.md")
+	if len(samples) == 0 {
+		t.Fatal("no samples")
+	}
+
+	for _, sample := range samples {
+		list, err := ParsePlayersList(sample)
+		if !strings.HasPrefix(sample, activePlayersHeader) {
+			if !errors.Is(err, ErrResponseIsNotPlayerList) {
+				t.Errorf("got %v, expected %v for %q", err, ErrResponseIsNotPlayerList, sample)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", sample, err)
+		}
+		if got, expected := len(list.ActivePlayers)+len(list.DisconnectedPlayers), strings.Count("\n"+sample, "\nID: "); got != expected {
+			t.Errorf("got %d players, expected %d for %q", got, expected, sample)
+		}
+	}
+}
+
+func TestParsePlayersList(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     string
+		active       []ActivePlayer
+		disconnected []DisconnectedPlayer
+		since        time.Duration
+	}{
+		{
+			name: "legacy Steam IDs",
+			response: "----- Active Players -----\n" +
+				"ID: 0 | SteamID: 76561197999957991 | Name: ✯RAIDR✯Jon | Team ID: 1 | Squad ID: 1 | Is Leader: True | Role: USA_SL_01\n" +
+				"ID: 1 | SteamID: 76561197989362395 | Name: ✯RAIDR✯creaman | Team ID: 2 | Squad ID: N/A | Is Leader: False | Role: INS_Rifleman_01\n" +
+				"----- Recently Disconnected Players [Max of 15] -----\n",
+			active: []ActivePlayer{
+				{MatchId: 0, SteamId: 76561197999957991, Name: "✯RAIDR✯Jon", TeamIndex: 1, SquadIndex: 1, IsSquadLead: true, Kit: "USA_SL_01"},
+				{MatchId: 1, SteamId: 76561197989362395, Name: "✯RAIDR✯creaman", TeamIndex: 2, Kit: "INS_Rifleman_01"},
+			},
+		},
+		{
+			name: "disconnected",
+			response: "----- Active Players -----\n" +
+				"ID: 0 | SteamID: 76561197999957991 | Name: ✯RAIDR✯Jon | Team ID: 1 | Squad ID: 1 | Is Leader: True | Role: USA_Pilot_01\n" +
+				"----- Recently Disconnected Players [Max of 15] -----\n" +
+				"ID: 1 | SteamID: 76561197989362395 | Since Disconnect: 01m.04s | Name: creaman\n",
+			active: []ActivePlayer{
+				{MatchId: 0, SteamId: 76561197999957991, Name: "✯RAIDR✯Jon", TeamIndex: 1, SquadIndex: 1, IsSquadLead: true, Kit: "USA_Pilot_01"},
+			},
+			disconnected: []DisconnectedPlayer{
+				{MatchId: 1, SteamId: 76561197989362395, Name: "creaman"},
+			},
+			since: 64 * time.Second,
+		},
+		{
+			name: "online IDs",
+			response: "----- Active Players -----\n" +
+				"ID: 0 | Online IDs: EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561197999957991 | Name: Jon | Team ID: 1 | Squad ID: N/A | Is Leader: False | Role: USA_Rifleman_01\n" +
+				"ID: 1 | Online IDs: EOS: 0002B2C3D4E5F60718293A4B5C6D7E8F | Name: console player | Team ID: 2 | Squad ID: 3 | Is Leader: False | Role: INS_Medic_01\n" +
+				"----- Recently Disconnected Players [Max of 15] -----\n" +
+				"ID: 2 | Online IDs: EOS: 0002c10186d9414496bf20d22d3860ba steam: 76561197989362395 | Since Disconnect: 00m.30s | Name: creaman\n",
+			active: []ActivePlayer{
+				{MatchId: 0, SteamId: 76561197999957991, EosId: "0002a10186d9414496bf20d22d3860ba", Name: "Jon", TeamIndex: 1, Kit: "USA_Rifleman_01"},
+				{MatchId: 1, EosId: "0002b2c3d4e5f60718293a4b5c6d7e8f", Name: "console player", TeamIndex: 2, SquadIndex: 3, Kit: "INS_Medic_01"},
+			},
+			disconnected: []DisconnectedPlayer{
+				{MatchId: 2, SteamId: 76561197989362395, EosId: "0002c10186d9414496bf20d22d3860ba", Name: "creaman"},
+			},
+			since: 30 * time.Second,
+		},
+		{
+			name:     "name containing separator",
+			response: "----- Active Players -----\nID: 4 | SteamID: 76561197999957991 | Name: Jon | TWS | Team ID: 1 | Squad ID: N/A | Is Leader: False | Role: USA_Rifleman_01\n----- Recently Disconnected Players [Max of 15] -----\n",
+			active: []ActivePlayer{
+				{MatchId: 4, SteamId: 76561197999957991, Name: "Jon | TWS", TeamIndex: 1, Kit: "USA_Rifleman_01"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := time.Now()
+			list, err := ParsePlayersList(test.response)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(list.ActivePlayers, test.active) {
+				t.Errorf("got active players %+v, expected %+v", list.ActivePlayers, test.active)
+			}
+
+			if len(list.DisconnectedPlayers) != len(test.disconnected) {
+				t.Fatalf("got disconnected players %+v, expected %+v", list.DisconnectedPlayers, test.disconnected)
+			}
+			for i, player := range list.DisconnectedPlayers {
+				if since := before.Sub(player.DisconnectTime); since < test.since-time.Second || since > test.since+time.Second {
+					t.Errorf("got disconnect time %s ago, expected %s", since, test.since)
+				}
+
+				player.DisconnectTime = time.Time{}
+				if player != test.disconnected[i] {
+					t.Errorf("got disconnected player %+v, expected %+v", player, test.disconnected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParsePlayersListInvalid(t *testing.T) {
+	list, err := ParsePlayersList("")
+	if err != nil || len(list.ActivePlayers) != 0 {
+		t.Errorf("got %+v, %v, expected an empty list", list, err)
+	}
+
+	_, err = ParsePlayersList("Jon (Steam ID: 76561197999957991) has created Squad 1 (Squad Name: TESTICLES) on United States Army")
+	if !errors.Is(err, ErrResponseIsNotPlayerList) {
+		t.Errorf("got %v, expected %v", err, ErrResponseIsNotPlayerList)
+	}
+
+	list, err = ParsePlayersList("----- Active Players -----\n" +
+		"ID: 0 | SteamID: 12 | Name: Jon | Team ID: 1 | Squad ID: N/A | Is Leader: False | Role: USA_Rifleman_01\n" +
+		"ID: 1 | SteamID: 76561197989362395 | Name: creaman | Team ID: 2 | Squad ID: N/A | Is Leader: False | Role: INS_Rifleman_01\n")
+	if !errors.Is(err, ErrInvalidSteamId) {
+		t.Errorf("got %v, expected %v", err, ErrInvalidSteamId)
+	}
+	if len(list.ActivePlayers) != 1 || list.ActivePlayers[0].Name != "creaman" {
+		t.Errorf("expected the valid player to be returned, got %+v", list.ActivePlayers)
+	}
+}
@@ -0,0 +1,186 @@
+package squadrcon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"squad-rcon-go/pkg/rcon"
+	"strconv"
+	"strings"
+)
+
+type Team struct {
+	Index int
+
+	// Faction contains the name of the team's faction, e.g. `III Corps`.
+	Faction string
+}
+
+type Squad struct {
+	// Id contains the 1-indexed index of the squad within its team.
+	Id int
+
+	TeamIndex int
+
+	Name string
+
+	// Size contains the amount of players in the squad.
+	Size int
+
+	Locked bool
+
+	CreatorName string
+
+	CreatorSteamId SteamID64
+
+	CreatorEosId EOSID
+}
+
+type SquadList struct {
+	Teams  []Team
+	Squads []Squad
+}
+
+var (
+	ErrResponseIsNotSquadList = errors.New("response returned from rcon is not a squad list")
+)
+
+func ListSquads(rcon rcon.Rcon) (SquadList, error) {
+	response, err := execute(rcon, "ListSquads")
+	if err != nil {
+		return SquadList{}, err
+	}
+
+	list, err := ParseSquadList(response)
+	if err != nil {
+		return SquadList{}, err
+	}
+
+	return list, nil
+}
+
+const activeSquadsHeader = "----- Active Squads -----"
+
+var squadListTeamRegex = regexp.MustCompile(`^Team ID: (\d+) \((.*)\)$`)
+
+const (
+	_ = iota
+	squadListTeamIndex
+	squadListTeamFaction
+)
+
+var squadListSquadRegex = regexp.MustCompile(`^ID: (\d+) \| Name: (.+) \| Size: (\d+) \| Locked: (\w+) \| Creator Name: (.+) \| Creator (?:Steam ID: (\d+)|Online IDs:(?: EOS: ([0-9a-fA-F]+))?(?: steam: (\d+))?)$`)
+
+const (
+	_ = iota
+	squadListSquadId
+	squadListSquadName
+	squadListSquadSize
+	squadListSquadLocked
+	squadListSquadCreatorName
+	squadListSquadCreatorLegacySteamId
+	squadListSquadCreatorEosId
+	squadListSquadCreatorSteamId
+)
+
+func ParseSquadList(squadListString string) (SquadList, error) {
+	if squadListString == "" {
+		return SquadList{}, nil
+	}
+
+	if !strings.HasPrefix(squadListString, activeSquadsHeader) {
+		return SquadList{}, ErrResponseIsNotSquadList
+	}
+
+	var squadList SquadList
+	var errs []error
+	var currentTeam *Team
+	scanner := bufio.NewScanner(strings.NewReader(squadListString))
+
+	for scanner.Scan() {
+		var line = scanner.Text()
+
+		if line == "" || strings.HasPrefix(line, activeSquadsHeader) {
+			continue
+		}
+
+		if matches := squadListTeamRegex.FindStringSubmatch(line); matches != nil {
+			teamIndex, err := strconv.Atoi(matches[squadListTeamIndex])
+			if err != nil {
+				errs = append(
+					errs,
+					fmt.Errorf("could not parse team index \"%s\"", matches[squadListTeamIndex]),
+				)
+				currentTeam = nil
+				continue
+			}
+
+			squadList.Teams = append(squadList.Teams, Team{
+				Index:   teamIndex,
+				Faction: matches[squadListTeamFaction],
+			})
+			currentTeam = &squadList.Teams[len(squadList.Teams)-1]
+			continue
+		}
+
+		matches := squadListSquadRegex.FindStringSubmatch(line)
+		if matches == nil {
+			errs = append(errs, fmt.Errorf("line cannot be parsed as a squad: %s", line))
+			continue
+		}
+
+		if currentTeam == nil {
+			errs = append(errs, fmt.Errorf("squad is not preceded by a team: %s", line))
+			continue
+		}
+
+		squadId, err := strconv.Atoi(matches[squadListSquadId])
+		if err != nil {
+			errs = append(
+				errs,
+				fmt.Errorf("could not parse squad ID \"%s\"", matches[squadListSquadId]),
+			)
+			continue
+		}
+
+		squadSize, err := strconv.Atoi(matches[squadListSquadSize])
+		if err != nil {
+			errs = append(
+				errs,
+				fmt.Errorf("could not parse squad size \"%s\"", matches[squadListSquadSize]),
+			)
+			continue
+		}
+
+		steamId, eosId, err := parsePlayerIds(
+			matches[squadListSquadCreatorLegacySteamId]+matches[squadListSquadCreatorSteamId],
+			matches[squadListSquadCreatorEosId],
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not parse squad creator IDs: %w", err))
+			continue
+		}
+
+		squadList.Squads = append(squadList.Squads, Squad{
+			Id:             squadId,
+			TeamIndex:      currentTeam.Index,
+			Name:           matches[squadListSquadName],
+			Size:           squadSize,
+			Locked:         matches[squadListSquadLocked] == "True",
+			CreatorName:    matches[squadListSquadCreatorName],
+			CreatorSteamId: steamId,
+			CreatorEosId:   eosId,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) != 0 {
+		return squadList, errors.Join(errs...)
+	}
+
+	return squadList, nil
+}
@@ -0,0 +1,158 @@
+package squadrcon
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"squad-rcon-go/pkg/rcon"
+	"strings"
+	"testing"
+)
+
+func TestParseSquadListSamples(t *testing.T) {
+	samples := readSamples(t, "ListSquads.md")
+	if len(samples) == 0 {
+		t.Fatal("no samples")
+	}
+
+	for _, sample := range samples {
+		list, err := ParseSquadList(sample)
+		if !strings.HasPrefix(sample, activeSquadsHeader) {
+			if !errors.Is(err, ErrResponseIsNotSquadList) {
+				t.Errorf("got %v, expected %v for %q", err, ErrResponseIsNotSquadList, sample)
+			}
+			continue
+		}
+
+		expected := SquadList{
+			Teams: []Team{{Index: 1, Faction: "III Corps"}, {Index: 2, Faction: "Local Insurgent Cell"}},
+			Squads: []Squad{{
+				Id:             1,
+				TeamIndex:      1,
+				Name:           "TESICULAR FORTITUDE",
+				Size:           2,
+				CreatorName:    "Jon",
+				CreatorSteamId: 76561197999957991,
+			}},
+		}
+		if err != nil || !reflect.DeepEqual(list, expected) {
+			t.Errorf("got %+v, %v, expected %+v for %q", list, err, expected, sample)
+		}
+	}
+}
+
+func TestParseSquadList(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected SquadList
+	}{
+		{
+			name: "separator in names",
+			response: "----- Active Squads -----\n" +
+				"Team ID: 1 (United States Army)\n" +
+				"ID: 1 | Name: INF | MIC | Size: 9 | Locked: True | Creator Name: [TWS] Jon | Main | Creator Steam ID: 76561197999957991\n" +
+				"ID: 2 | Name: LOGI | Size: 1 | Locked: False | Creator Name: creaman | Creator Steam ID: 76561197989362395\n" +
+				"Team ID: 2 (Insurgent Forces)\n",
+			expected: SquadList{
+				Teams: []Team{{Index: 1, Faction: "United States Army"}, {Index: 2, Faction: "Insurgent Forces"}},
+				Squads: []Squad{
+					{Id: 1, TeamIndex: 1, Name: "INF | MIC", Size: 9, Locked: true, CreatorName: "[TWS] Jon | Main", CreatorSteamId: 76561197999957991},
+					{Id: 2, TeamIndex: 1, Name: "LOGI", Size: 1, CreatorName: "creaman", CreatorSteamId: 76561197989362395},
+				},
+			},
+		},
+		{
+			name: "online IDs",
+			response: "----- Active Squads -----\n" +
+				"Team ID: 1 (III Corps)\n" +
+				"Team ID: 2 (Local Insurgent Cell)\n" +
+				"ID: 1 | Name: Squad 1 | Size: 2 | Locked: False | Creator Name: Jon | Creator Online IDs: EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561197999957991\n" +
+				"ID: 2 | Name: Squad 2 | Size: 1 | Locked: False | Creator Name: console player | Creator Online IDs: EOS: 0002b2c3d4e5f60718293a4b5c6d7e8f\n",
+			expected: SquadList{
+				Teams: []Team{{Index: 1, Faction: "III Corps"}, {Index: 2, Faction: "Local Insurgent Cell"}},
+				Squads: []Squad{
+					{Id: 1, TeamIndex: 2, Name: "Squad 1", Size: 2, CreatorName: "Jon", CreatorSteamId: 76561197999957991, CreatorEosId: "0002a10186d9414496bf20d22d3860ba"},
+					{Id: 2, TeamIndex: 2, Name: "Squad 2", Size: 1, CreatorName: "console player", CreatorEosId: "0002b2c3d4e5f60718293a4b5c6d7e8f"},
+				},
+			},
+		},
+		{
+			name:     "empty",
+			response: "",
+			expected: SquadList{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			list, err := ParseSquadList(test.response)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(list, test.expected) {
+				t.Errorf("got %+v, expected %+v", list, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseSquadListInvalid(t *testing.T) {
+	list, err := ParseSquadList("----- Active Squads -----\n" +
+		"ID: 1 | Name: ALPHA | Size: 2 | Locked: False | Creator Name: Jon | Creator Steam ID: 76561197999957991\n" +
+		"Team ID: 1 (III Corps)\n" +
+		"ID: 2 | Name: BRAVO | Size: 1 | Locked: False | Creator Name: Jon | Creator Steam ID: 76561197999957991\n")
+	if err == nil {
+		t.Error("expected an error for the squad without team")
+	}
+	if len(list.Squads) != 1 || list.Squads[0].Name != "BRAVO" {
+		t.Errorf("expected the valid squad to be returned, got %+v", list.Squads)
+	}
+}
+
+func TestListSquadsReplay(t *testing.T) {
+	packets, err := rcon.ReadRecording(filepath.Join("..", "..", "data", "ListSquads.jsonl"))
+	if err != nil {
+		t.Fatalf("could not read recording: %v", err)
+	}
+
+	messages := make(chan ServerMessage, 1)
+	squadRcon, err := ConnectConn(rcon.NewReplayConn(packets), "password", Settings{
+		SkipCommandProbe: true,
+		OnServerMessage: func(message ServerMessage) {
+			messages <- message
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer squadRcon.Close()
+
+	list, err := ListSquads(squadRcon)
+	expected := SquadList{
+		Teams: []Team{{Index: 1, Faction: "III Corps"}, {Index: 2, Faction: "Local Insurgent Cell"}},
+		Squads: []Squad{{
+			Id:             1,
+			TeamIndex:      1,
+			Name:           "TESICULAR FORTITUDE",
+			Size:           2,
+			CreatorName:    "Jon",
+			CreatorSteamId: 76561197999957991,
+		}},
+	}
+	if err != nil || !reflect.DeepEqual(list, expected) {
+		t.Errorf("got %+v, %v, expected %+v", list, err, expected)
+	}
+
+	// The chat is received before the response is complete.
+	select {
+	case message := <-messages:
+		chat, ok := message.(ChatMessage)
+		if !ok || chat.Channel != ChatAll || chat.SteamId != 76561197989362395 || chat.PlayerName != "✯RAIDR✯creaman" || chat.Message != "SL1 SQUAD IS SQUADBAITING" {
+			t.Errorf("got %+v, expected the chat message of the recording", message)
+		}
+	default:
+		t.Error("chat message was not passed to OnServerMessage")
+	}
+}
@@ -0,0 +1,87 @@
+package squadrcon
+
+import (
+	"errors"
+	"regexp"
+	"squad-rcon-go/pkg/rcon"
+	"strings"
+)
+
+// LayerInfo describes a layer as returned by ShowCurrentMap and ShowNextMap.
+type LayerInfo struct {
+	Level string
+
+	Layer string
+
+	// Factions contains the faction of each team. Only reported by newer Squad versions.
+	Factions []string
+}
+
+// IsEmpty returns true if no layer is set, e.g. when the next layer has not been determined.
+func (l LayerInfo) IsEmpty() bool {
+	return l.Level == "" && l.Layer == ""
+}
+
+// Equal returns whether both describe the same level, layer and factions.
+func (l LayerInfo) Equal(other LayerInfo) bool {
+	return l.Level == other.Level &&
+		l.Layer == other.Layer &&
+		strings.Join(l.Factions, " ") == strings.Join(other.Factions, " ")
+}
+
+var (
+	ErrResponseIsNotLayerInfo = errors.New("response returned from rcon is not layer information")
+)
+
+var currentMapRegex = regexp.MustCompile(`^Current level is (.*), layer is ?(.*?)(?:, factions (.*))?$`)
+var nextMapRegex = regexp.MustCompile(`^Next level is (.*), layer is ?(.*?)(?:, factions (.*))?$`)
+
+const (
+	_ = iota
+	layerInfoLevel
+	layerInfoLayer
+	layerInfoFactions
+)
+
+func ShowCurrentMap(rcon rcon.Rcon) (LayerInfo, error) {
+	response, err := execute(rcon, "ShowCurrentMap")
+	if err != nil {
+		return LayerInfo{}, err
+	}
+
+	return parseLayerInfo(currentMapRegex, response)
+}
+
+func ShowNextMap(rcon rcon.Rcon) (LayerInfo, error) {
+	response, err := execute(rcon, "ShowNextMap")
+	if err != nil {
+		return LayerInfo{}, err
+	}
+
+	return parseLayerInfo(nextMapRegex, response)
+}
+
+// ParseCurrentMap parses the response of ShowCurrentMap, e.g.
+// `Current level is Narva, layer is Narva_AAS_v1, factions USA RGF`.
+func ParseCurrentMap(response string) (LayerInfo, error) {
+	return parseLayerInfo(currentMapRegex, response)
+}
+
+// ParseNextMap parses the response of ShowNextMap, e.g.
+// `Next level is Narva, layer is Narva_AAS_v1, factions USA RGF`.
+func ParseNextMap(response string) (LayerInfo, error) {
+	return parseLayerInfo(nextMapRegex, response)
+}
+
+func parseLayerInfo(regex *regexp.Regexp, response string) (LayerInfo, error) {
+	matches := regex.FindStringSubmatch(strings.TrimSpace(response))
+	if matches == nil {
+		return LayerInfo{}, ErrResponseIsNotLayerInfo
+	}
+
+	return LayerInfo{
+		Level:    strings.TrimSpace(matches[layerInfoLevel]),
+		Layer:    strings.TrimSpace(matches[layerInfoLayer]),
+		Factions: strings.Fields(matches[layerInfoFactions]),
+	}, nil
+}
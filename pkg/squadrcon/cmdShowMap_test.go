@@ -0,0 +1,61 @@
+package squadrcon
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCurrentMap(t *testing.T) {
+	tests := []struct {
+		response string
+		expected LayerInfo
+		err      error
+	}{
+		{
+			response: "Current level is Narva, layer is Narva_AAS_v1, factions USA RGF",
+			expected: LayerInfo{Level: "Narva", Layer: "Narva_AAS_v1", Factions: []string{"USA", "RGF"}},
+		},
+		{
+			response: "Current level is Al Basrah, layer is Al_Basrah_Invasion_v1\n",
+			expected: LayerInfo{Level: "Al Basrah", Layer: "Al_Basrah_Invasion_v1"},
+		},
+		{
+			response: "Current level is , layer is ",
+			expected: LayerInfo{},
+		},
+		{
+			response: "Next level is Narva, layer is Narva_AAS_v1",
+			err:      ErrResponseIsNotLayerInfo,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.response, func(t *testing.T) {
+			layer, err := ParseCurrentMap(test.response)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, expected %v", err, test.err)
+			}
+
+			if !layer.Equal(test.expected) {
+				t.Errorf("got %+v, expected %+v", layer, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseNextMap(t *testing.T) {
+	layer, err := ParseNextMap("Next level is Yehorivka, layer is Yehorivka_RAAS_v2, factions CAF VDV")
+	expected := LayerInfo{Level: "Yehorivka", Layer: "Yehorivka_RAAS_v2", Factions: []string{"CAF", "VDV"}}
+	if err != nil || !layer.Equal(expected) {
+		t.Errorf("got %+v, %v, expected %+v", layer, err, expected)
+	}
+
+	layer, err = ParseNextMap("Next level is , layer is ")
+	if err != nil || !layer.IsEmpty() {
+		t.Errorf("got %+v, %v, expected an empty layer", layer, err)
+	}
+
+	if _, err := ParseNextMap("Current level is Narva, layer is Narva_AAS_v1"); !errors.Is(err, ErrResponseIsNotLayerInfo) {
+		t.Errorf("got %v, expected %v", err, ErrResponseIsNotLayerInfo)
+	}
+}
@@ -0,0 +1,88 @@
+package squadrcon
+
+import (
+	"encoding/json"
+	"fmt"
+	"squad-rcon-go/pkg/rcon"
+	"strconv"
+	"time"
+)
+
+// ServerInfo contains the commonly used fields of the ShowServerInfo response.
+type ServerInfo struct {
+	ServerName    string
+	GameVersion   string
+	MaxPlayers    int
+	PlayerCount   int
+	PublicQueue   int
+	ReservedQueue int
+
+	// CurrentLayer and NextLayer contain layer names, e.g. `Narva_AAS_v1`.
+	CurrentLayer string
+	NextLayer    string
+
+	TeamOne string
+	TeamTwo string
+
+	// PlayTime contains the time since the start of the current match.
+	PlayTime time.Duration
+
+	// Raw contains all fields of the response.
+	Raw map[string]any
+}
+
+func ShowServerInfo(rcon rcon.Rcon) (ServerInfo, error) {
+	response, err := execute(rcon, "ShowServerInfo")
+	if err != nil {
+		return ServerInfo{}, err
+	}
+
+	return ParseServerInfo(response)
+}
+
+// ParseServerInfo parses the JSON response of ShowServerInfo. Numeric fields are reported as
+// either JSON numbers or strings depending on the Squad version, both are accepted.
+func ParseServerInfo(response string) (ServerInfo, error) {
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(response), &raw); err != nil {
+		return ServerInfo{}, fmt.Errorf("failed to parse server info: %w", err)
+	}
+
+	return ServerInfo{
+		ServerName:    rawString(raw, "ServerName_s"),
+		GameVersion:   rawString(raw, "GameVersion_s"),
+		MaxPlayers:    rawInt(raw, "MaxPlayers"),
+		PlayerCount:   rawInt(raw, "PlayerCount_I"),
+		PublicQueue:   rawInt(raw, "PublicQueue_I"),
+		ReservedQueue: rawInt(raw, "ReservedQueue_I"),
+		CurrentLayer:  rawString(raw, "MapName_s"),
+		NextLayer:     rawString(raw, "NextLayer_s"),
+		TeamOne:       rawString(raw, "TeamOne_s"),
+		TeamTwo:       rawString(raw, "TeamTwo_s"),
+		PlayTime:      time.Duration(rawInt(raw, "PLAYTIME_I")) * time.Second,
+		Raw:           raw,
+	}, nil
+}
+
+func rawString(raw map[string]any, key string) string {
+	switch value := raw[key].(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+func rawInt(raw map[string]any, key string) int {
+	switch value := raw[key].(type) {
+	case float64:
+		return int(value)
+	case string:
+		parsed, _ := strconv.ParseFloat(value, 64)
+		return int(parsed)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,61 @@
+package squadrcon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseServerInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{
+			name:     "numbers",
+			response: `{"ServerName_s":"Squad RCON Emulator","GameVersion_s":"v7.0.0.123","MaxPlayers":100,"PlayerCount_I":42,"PublicQueue_I":3,"ReservedQueue_I":1,"MapName_s":"Narva_AAS_v1","NextLayer_s":"Yehorivka_RAAS_v2","TeamOne_s":"USA","TeamTwo_s":"RGF","PLAYTIME_I":754}`,
+		},
+		{
+			name:     "strings",
+			response: `{"ServerName_s":"Squad RCON Emulator","GameVersion_s":"v7.0.0.123","MaxPlayers":"100","PlayerCount_I":"42","PublicQueue_I":"3","ReservedQueue_I":"1","MapName_s":"Narva_AAS_v1","NextLayer_s":"Yehorivka_RAAS_v2","TeamOne_s":"USA","TeamTwo_s":"RGF","PLAYTIME_I":"754"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info, err := ParseServerInfo(test.response)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			info.Raw = nil
+			expected := ServerInfo{
+				ServerName:    "Squad RCON Emulator",
+				GameVersion:   "v7.0.0.123",
+				MaxPlayers:    100,
+				PlayerCount:   42,
+				PublicQueue:   3,
+				ReservedQueue: 1,
+				CurrentLayer:  "Narva_AAS_v1",
+				NextLayer:     "Yehorivka_RAAS_v2",
+				TeamOne:       "USA",
+				TeamTwo:       "RGF",
+				PlayTime:      754 * time.Second,
+			}
+			if !reflect.DeepEqual(info, expected) {
+				t.Errorf("got %+v, expected %+v", info, expected)
+			}
+		})
+	}
+}
+
+func TestParseServerInfoInvalid(t *testing.T) {
+	if _, err := ParseServerInfo("Current level is Narva, layer is Narva_AAS_v1"); err == nil {
+		t.Error("expected an error")
+	}
+
+	info, err := ParseServerInfo(`{"ServerName_s":"Squad"}`)
+	if err != nil || info.ServerName != "Squad" || info.MaxPlayers != 0 {
+		t.Errorf("got %+v, %v, expected missing fields to be zero", info, err)
+	}
+}
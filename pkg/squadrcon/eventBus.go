@@ -0,0 +1,247 @@
+package squadrcon
+
+import (
+	"sync"
+	"time"
+)
+
+type Topic string
+
+const (
+	// TopicChat carries ChatMessage payloads.
+	TopicChat Topic = "chat"
+
+	// TopicNotification carries all other ServerMessage payloads.
+	TopicNotification Topic = "notification"
+
+	// TopicRoster carries RosterEvent payloads.
+	TopicRoster Topic = "roster"
+
+	// TopicSquad carries SquadEvent payloads.
+	TopicSquad Topic = "squad"
+
+	// TopicMatch carries MatchEvent payloads.
+	TopicMatch Topic = "match"
+)
+
+// Event is the envelope of everything published on an EventBus.
+type Event struct {
+	// ServerId identifies the server the event originates from, see EventBusSettings.ServerId.
+	ServerId string
+
+	Time time.Time
+
+	// Sequence increases by one for every event published on the bus, starting at 1.
+	Sequence uint64
+
+	Topic Topic
+
+	// Payload contains the typed event, e.g. a ChatMessage or a PlayerJoinedEvent.
+	Payload any
+}
+
+type EventBusSettings struct {
+	// ServerId is copied to every event. Useful when events of multiple servers are combined.
+	ServerId string
+
+	// Synchronous makes Publish call the handlers of SubscribeFunc before returning, rather than
+	// from a separate goroutine. Intended for tests.
+	Synchronous bool
+}
+
+type SubscribeOptions struct {
+	// Topics to receive. All topics are received if empty.
+	Topics []Topic
+
+	// BufferSize is the amount of events that can be pending. Defaults to 64.
+	BufferSize int
+
+	// Overflow determines what happens when the buffer is full. Defaults to OverflowDropNewest.
+	Overflow OverflowPolicy
+}
+
+const defaultEventBusBufferSize = 64
+
+// EventBus combines the events of all sources into a single stream. Subscribers choose the topics
+// they receive and have their own bounded queue.
+type EventBus struct {
+	settings    EventBusSettings
+	subscribers subscribers[Event]
+
+	// Held by Publish while delivering, so that events reach every subscriber in sequence.
+	publishLock sync.Mutex
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// The sequence number of the last published event.
+	sequence uint64
+
+	// Topics of each subscription, nil if all topics are received.
+	topics map[*Subscription[Event]]map[Topic]struct{}
+
+	// Handlers called by Publish in synchronous mode.
+	handlers map[*Subscription[Event]]func(event Event)
+}
+
+func NewEventBus(settings EventBusSettings) *EventBus {
+	return &EventBus{
+		settings: settings,
+		topics:   make(map[*Subscription[Event]]map[Topic]struct{}),
+		handlers: make(map[*Subscription[Event]]func(event Event)),
+	}
+}
+
+// Publish wraps the payload in an Event and delivers it to the subscribers of the topic.
+func (b *EventBus) Publish(topic Topic, payload any) Event {
+	b.publishLock.Lock()
+
+	b.lock.Lock()
+	b.sequence++
+	event := Event{
+		ServerId: b.settings.ServerId,
+		Time:     time.Now(),
+		Sequence: b.sequence,
+		Topic:    topic,
+		Payload:  payload,
+	}
+
+	subscriptions := b.subscribers.filter(func(subscription *Subscription[Event]) bool {
+		return b.accepts(subscription, topic)
+	})
+
+	var handlers []func(event Event)
+	for subscription, handler := range b.handlers {
+		if b.accepts(subscription, topic) {
+			handlers = append(handlers, handler)
+		}
+	}
+	b.lock.Unlock()
+
+	// Delivering outside the lock lets subscriptions come and go while an OverflowBlock
+	// subscription blocks the publisher.
+	for _, subscription := range subscriptions {
+		subscription.deliver(event)
+	}
+	b.publishLock.Unlock()
+
+	// Handlers are called unlocked so they can publish events themselves.
+	for _, handler := range handlers {
+		handler(event)
+	}
+
+	return event
+}
+
+// Subscribe returns a subscription receiving the events of the chosen topics through a channel.
+func (b *EventBus) Subscribe(options SubscribeOptions) *Subscription[Event] {
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultEventBusBufferSize
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	subscription := b.subscribers.subscribe(options.BufferSize, options.Overflow)
+	b.setTopics(subscription, options.Topics)
+
+	unsubscribe := subscription.unsubscribe
+	subscription.unsubscribe = func() {
+		unsubscribe()
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		delete(b.topics, subscription)
+	}
+
+	return subscription
+}
+
+// SubscribeFunc calls handler for every event of the chosen topics. Handlers are called one event
+// at a time from a separate goroutine, or from Publish in synchronous mode. Unsubscribe stops the
+// calls.
+func (b *EventBus) SubscribeFunc(options SubscribeOptions, handler func(event Event)) *Subscription[Event] {
+	if !b.settings.Synchronous {
+		subscription := b.Subscribe(options)
+
+		go func() {
+			for event := range subscription.Events() {
+				handler(event)
+			}
+		}()
+
+		return subscription
+	}
+
+	// The subscription only serves as handle, events are passed to the handler directly.
+	subscription := &Subscription[Event]{
+		events: make(chan Event),
+		done:   make(chan struct{}),
+	}
+	close(subscription.events)
+	subscription.unsubscribe = func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		delete(b.handlers, subscription)
+		delete(b.topics, subscription)
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.handlers[subscription] = handler
+	b.setTopics(subscription, options.Topics)
+
+	return subscription
+}
+
+// Close closes all subscriptions, releasing a publisher blocked by an OverflowBlock subscription.
+func (b *EventBus) Close() {
+	b.subscribers.close()
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.handlers = make(map[*Subscription[Event]]func(event Event))
+}
+
+func (b *EventBus) setTopics(subscription *Subscription[Event], topics []Topic) {
+	if len(topics) == 0 {
+		return
+	}
+
+	accepted := make(map[Topic]struct{}, len(topics))
+	for _, topic := range topics {
+		accepted[topic] = struct{}{}
+	}
+	b.topics[subscription] = accepted
+}
+
+func (b *EventBus) accepts(subscription *Subscription[Event], topic Topic) bool {
+	topics, filtered := b.topics[subscription]
+	if !filtered {
+		return true
+	}
+
+	_, accepted := topics[topic]
+	return accepted
+}
+
+// publishServerMessage publishes a message on the chat or notification topic.
+func (b *EventBus) publishServerMessage(message ServerMessage) {
+	if _, isChat := message.(ChatMessage); isChat {
+		b.Publish(TopicChat, message)
+	} else {
+		b.Publish(TopicNotification, message)
+	}
+}
+
+// publishAll publishes each event on the topic. It accepts a nil bus so that sources can call it
+// unconditionally.
+func publishAll[T any](b *EventBus, topic Topic, events []T) {
+	if b == nil {
+		return
+	}
+
+	for _, event := range events {
+		b.Publish(topic, event)
+	}
+}
@@ -0,0 +1,177 @@
+package squadrcon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// receive returns the sequence numbers of the buffered events.
+func receive(subscription *Subscription[Event]) []uint64 {
+	var sequences []uint64
+	for {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return sequences
+			}
+			sequences = append(sequences, event.Sequence)
+		default:
+			return sequences
+		}
+	}
+}
+
+// waitFor fails the test unless done is closed within a second.
+func waitFor(t *testing.T, done <-chan struct{}, what string) {
+	t.Helper()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestEventBusTopics(t *testing.T) {
+	bus := NewEventBus(EventBusSettings{ServerId: "server"})
+	defer bus.Close()
+
+	all := bus.Subscribe(SubscribeOptions{})
+	chat := bus.Subscribe(SubscribeOptions{Topics: []Topic{TopicChat}})
+	players := bus.Subscribe(SubscribeOptions{Topics: []Topic{TopicRoster, TopicSquad}})
+
+	bus.Publish(TopicChat, ChatMessage{Message: "hello"})
+	bus.Publish(TopicRoster, PlayerJoinedEvent{})
+	event := bus.Publish(TopicMatch, MatchStartedEvent{})
+	bus.Publish(TopicSquad, SquadCreatedEvent{})
+
+	if event.ServerId != "server" || event.Sequence != 3 || event.Topic != TopicMatch {
+		t.Errorf("got %+v, expected the third event on the match topic", event)
+	}
+
+	tests := []struct {
+		name         string
+		subscription *Subscription[Event]
+		expected     []uint64
+	}{
+		{"all topics", all, []uint64{1, 2, 3, 4}},
+		{"chat", chat, []uint64{1}},
+		{"roster and squad", players, []uint64{2, 4}},
+	}
+
+	for _, test := range tests {
+		if sequences := receive(test.subscription); !reflect.DeepEqual(sequences, test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.name, sequences, test.expected)
+		}
+	}
+
+	chat.Unsubscribe()
+	bus.Publish(TopicChat, ChatMessage{})
+	if _, ok := <-chat.Events(); ok {
+		t.Error("expected the events channel to be closed on Unsubscribe")
+	}
+}
+
+func TestEventBusOverflow(t *testing.T) {
+	tests := []struct {
+		overflow OverflowPolicy
+		expected []uint64
+	}{
+		{OverflowDropNewest, []uint64{1, 2}},
+		{OverflowDropOldest, []uint64{2, 3}},
+	}
+
+	for _, test := range tests {
+		bus := NewEventBus(EventBusSettings{})
+		subscription := bus.Subscribe(SubscribeOptions{BufferSize: 2, Overflow: test.overflow})
+
+		for i := 0; i < 3; i++ {
+			bus.Publish(TopicChat, ChatMessage{})
+		}
+
+		if sequences := receive(subscription); !reflect.DeepEqual(sequences, test.expected) {
+			t.Errorf("overflow %d: got %v, expected %v", test.overflow, sequences, test.expected)
+		}
+		if subscription.Dropped() != 1 {
+			t.Errorf("overflow %d: got %d dropped events, expected 1", test.overflow, subscription.Dropped())
+		}
+
+		bus.Close()
+	}
+}
+
+func TestEventBusOverflowBlock(t *testing.T) {
+	bus := NewEventBus(EventBusSettings{})
+	blocking := bus.Subscribe(SubscribeOptions{BufferSize: 1, Overflow: OverflowBlock})
+	bus.Publish(TopicChat, ChatMessage{})
+
+	published := make(chan struct{})
+	go func() {
+		bus.Publish(TopicChat, ChatMessage{})
+		close(published)
+	}()
+
+	// Other subscriptions can come and go while the publisher is blocked.
+	subscribed := make(chan struct{})
+	go func() {
+		bus.Subscribe(SubscribeOptions{}).Unsubscribe()
+		close(subscribed)
+	}()
+	waitFor(t, subscribed, "Subscribe while a publisher is blocked")
+
+	select {
+	case <-published:
+		t.Fatal("expected Publish to block while the buffer is full")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	<-blocking.Events()
+	waitFor(t, published, "Publish after the buffer was drained")
+
+	// Fill the buffer again, Close must release the blocked publisher.
+	published = make(chan struct{})
+	go func() {
+		bus.Publish(TopicChat, ChatMessage{})
+		close(published)
+	}()
+
+	closed := make(chan struct{})
+	go func() {
+		bus.Close()
+		close(closed)
+	}()
+	waitFor(t, closed, "Close while a publisher is blocked")
+	waitFor(t, published, "Publish after Close")
+
+	if sequences := receive(blocking); !reflect.DeepEqual(sequences, []uint64{2}) {
+		t.Errorf("got %v, expected the buffered event before the channel was closed", sequences)
+	}
+}
+
+func TestEventBusSynchronous(t *testing.T) {
+	bus := NewEventBus(EventBusSettings{Synchronous: true})
+	defer bus.Close()
+
+	var chat, all []uint64
+	chatSubscription := bus.SubscribeFunc(SubscribeOptions{Topics: []Topic{TopicChat}}, func(event Event) {
+		chat = append(chat, event.Sequence)
+
+		// Handlers may publish themselves.
+		bus.Publish(TopicNotification, PlayerWarnedMessage{})
+	})
+	bus.SubscribeFunc(SubscribeOptions{}, func(event Event) {
+		all = append(all, event.Sequence)
+	})
+
+	bus.Publish(TopicChat, ChatMessage{})
+	if !reflect.DeepEqual(chat, []uint64{1}) || len(all) != 2 {
+		t.Errorf("got chat %v and all %v, expected the handlers to be called before Publish returns", chat, all)
+	}
+
+	chatSubscription.Unsubscribe()
+	bus.Publish(TopicChat, ChatMessage{})
+	if len(chat) != 1 || len(all) != 3 {
+		t.Errorf("got chat %v and all %v, expected no calls after Unsubscribe", chat, all)
+	}
+}
@@ -0,0 +1,176 @@
+package squadrcon
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidSteamId = errors.New("invalid Steam ID")
+	ErrInvalidEosId   = errors.New("invalid EOS ID")
+)
+
+// SteamID64 is the 64-bit representation of an individual's Steam account. The zero value
+// represents an unknown Steam ID.
+type SteamID64 uint64
+
+const (
+	steamUniversePublic   = 1
+	steamAccountTypeUser  = 1
+	steamInstanceDesktop  = 1
+	steamIdAccountIdBits  = 32
+	steamIdInstanceBits   = 20
+	steamIdAccountTypeBit = steamIdAccountIdBits + steamIdInstanceBits
+	steamIdUniverseBit    = steamIdAccountTypeBit + 4
+)
+
+// ParseSteamID64 parses the decimal representation of a SteamID64. Only IDs of individual accounts
+// in the public universe are accepted.
+func ParseSteamID64(steamId string) (SteamID64, error) {
+	value, err := strconv.ParseUint(steamId, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w \"%s\": %w", ErrInvalidSteamId, steamId, err)
+	}
+
+	id := SteamID64(value)
+	if !id.IsValid() {
+		return 0, fmt.Errorf("%w \"%s\": not an individual account", ErrInvalidSteamId, steamId)
+	}
+
+	return id, nil
+}
+
+// IsValid returns whether the ID refers to an individual account in the public universe.
+func (id SteamID64) IsValid() bool {
+	return id.AccountId() != 0 &&
+		uint64(id)>>steamIdUniverseBit == steamUniversePublic &&
+		(uint64(id)>>steamIdAccountTypeBit)&0xF == steamAccountTypeUser &&
+		(uint64(id)>>steamIdAccountIdBits)&(1<<steamIdInstanceBits-1) == steamInstanceDesktop
+}
+
+// AccountId returns the 32-bit account number, as used in SteamID3.
+func (id SteamID64) AccountId() uint32 {
+	return uint32(id)
+}
+
+// SteamID2 returns the legacy textual representation, e.g. `STEAM_1:1:19846131`.
+func (id SteamID64) SteamID2() string {
+	return fmt.Sprintf("STEAM_%d:%d:%d", steamUniversePublic, id.AccountId()&1, id.AccountId()>>1)
+}
+
+// SteamID3 returns the textual representation used by newer Source games, e.g. `[U:1:39692263]`.
+func (id SteamID64) SteamID3() string {
+	return fmt.Sprintf("[U:%d:%d]", steamUniversePublic, id.AccountId())
+}
+
+// ProfileURL returns the URL of the Steam community profile.
+func (id SteamID64) ProfileURL() string {
+	return "https://steamcommunity.com/profiles/" + id.String()
+}
+
+// String returns the decimal representation, or an empty string for the zero value.
+func (id SteamID64) String() string {
+	if id == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func (id SteamID64) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+func (id *SteamID64) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*id = 0
+		return nil
+	}
+
+	parsed, err := ParseSteamID64(string(text))
+	if err != nil {
+		return err
+	}
+
+	*id = parsed
+	return nil
+}
+
+// EOSID is an Epic Online Services product user ID, 32 lowercase hexadecimal characters. The zero
+// value represents an unknown EOS ID.
+type EOSID string
+
+const eosIdLength = 32
+
+// ParseEOSID validates an EOS ID and normalizes it to lowercase.
+func ParseEOSID(eosId string) (EOSID, error) {
+	if len(eosId) != eosIdLength {
+		return "", fmt.Errorf("%w \"%s\": expected %d characters", ErrInvalidEosId, eosId, eosIdLength)
+	}
+
+	for _, r := range eosId {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return "", fmt.Errorf("%w \"%s\": not hexadecimal", ErrInvalidEosId, eosId)
+		}
+	}
+
+	return EOSID(strings.ToLower(eosId)), nil
+}
+
+func (id EOSID) String() string {
+	return string(id)
+}
+
+func (id EOSID) MarshalText() ([]byte, error) {
+	return []byte(id), nil
+}
+
+func (id *EOSID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*id = ""
+		return nil
+	}
+
+	parsed, err := ParseEOSID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*id = parsed
+	return nil
+}
+
+// parsePlayerIds parses the IDs of a player as reported by RCON. Empty IDs result in zero values.
+func parsePlayerIds(steamId string, eosId string) (SteamID64, EOSID, error) {
+	var parsedSteamId SteamID64
+	var parsedEosId EOSID
+	var err error
+
+	if steamId != "" {
+		if parsedSteamId, err = ParseSteamID64(steamId); err != nil {
+			return 0, "", err
+		}
+	}
+
+	if eosId != "" {
+		if parsedEosId, err = ParseEOSID(eosId); err != nil {
+			return 0, "", err
+		}
+	}
+
+	return parsedSteamId, parsedEosId, nil
+}
+
+// idKey returns the Steam ID, else the EOS ID. Empty if the player has neither.
+func idKey(steamId SteamID64, eosId EOSID) string {
+	switch {
+	case steamId != 0:
+		return steamId.String()
+	case eosId != "":
+		return eosId.String()
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,135 @@
+package squadrcon
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSteamID64(t *testing.T) {
+	tests := []struct {
+		steamId  string
+		expected SteamID64
+		err      error
+	}{
+		{steamId: "76561197999957991", expected: 76561197999957991},
+		{steamId: "76561197989362395", expected: 76561197989362395},
+		{steamId: "", err: ErrInvalidSteamId},
+		{steamId: "STEAM_1:1:19846131", err: ErrInvalidSteamId},
+		{steamId: "-76561197999957991", err: ErrInvalidSteamId},
+		// Account number 0.
+		{steamId: "76561197960265728", err: ErrInvalidSteamId},
+		// Group, not an individual account.
+		{steamId: "103582791429521408", err: ErrInvalidSteamId},
+		// Account ID of 76561197999957991 without universe, type and instance.
+		{steamId: "39692263", err: ErrInvalidSteamId},
+	}
+
+	for _, test := range tests {
+		t.Run(test.steamId, func(t *testing.T) {
+			steamId, err := ParseSteamID64(test.steamId)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, expected %v", err, test.err)
+			}
+
+			if steamId != test.expected {
+				t.Errorf("got %d, expected %d", steamId, test.expected)
+			}
+		})
+	}
+}
+
+func TestSteamID64Conversion(t *testing.T) {
+	tests := []struct {
+		steamId   SteamID64
+		accountId uint32
+		steamId2  string
+		steamId3  string
+	}{
+		{
+			steamId:   76561197999957991,
+			accountId: 39692263,
+			steamId2:  "STEAM_1:1:19846131",
+			steamId3:  "[U:1:39692263]",
+		},
+		{
+			steamId:   76561197989362395,
+			accountId: 29096667,
+			steamId2:  "STEAM_1:1:14548333",
+			steamId3:  "[U:1:29096667]",
+		},
+		{
+			steamId:   76561197960265730,
+			accountId: 2,
+			steamId2:  "STEAM_1:0:1",
+			steamId3:  "[U:1:2]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.steamId.String(), func(t *testing.T) {
+			if accountId := test.steamId.AccountId(); accountId != test.accountId {
+				t.Errorf("AccountId: got %d, expected %d", accountId, test.accountId)
+			}
+
+			if steamId2 := test.steamId.SteamID2(); steamId2 != test.steamId2 {
+				t.Errorf("SteamID2: got %s, expected %s", steamId2, test.steamId2)
+			}
+
+			if steamId3 := test.steamId.SteamID3(); steamId3 != test.steamId3 {
+				t.Errorf("SteamID3: got %s, expected %s", steamId3, test.steamId3)
+			}
+
+			text, err := test.steamId.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText: %v", err)
+			}
+
+			var unmarshalled SteamID64
+			if err := unmarshalled.UnmarshalText(text); err != nil {
+				t.Fatalf("UnmarshalText: %v", err)
+			}
+			if unmarshalled != test.steamId {
+				t.Errorf("UnmarshalText: got %d, expected %d", unmarshalled, test.steamId)
+			}
+		})
+	}
+}
+
+func TestSteamID64Zero(t *testing.T) {
+	var steamId SteamID64
+	if steamId.String() != "" {
+		t.Errorf("got %q, expected an empty string", steamId.String())
+	}
+
+	if err := steamId.UnmarshalText(nil); err != nil || steamId != 0 {
+		t.Errorf("got %d, %v, expected the zero value", steamId, err)
+	}
+}
+
+func TestParseEOSID(t *testing.T) {
+	tests := []struct {
+		eosId    string
+		expected EOSID
+		err      error
+	}{
+		{eosId: "0002a10186d9414496bf20d22d3860ba", expected: "0002a10186d9414496bf20d22d3860ba"},
+		{eosId: "0002A10186D9414496BF20D22D3860BA", expected: "0002a10186d9414496bf20d22d3860ba"},
+		{eosId: "", err: ErrInvalidEosId},
+		{eosId: "0002a10186d9414496bf20d22d3860b", err: ErrInvalidEosId},
+		{eosId: "0002a10186d9414496bf20d22d3860bag", err: ErrInvalidEosId},
+		{eosId: "0002a10186d9414496bf20d22d3860bg", err: ErrInvalidEosId},
+	}
+
+	for _, test := range tests {
+		t.Run(test.eosId, func(t *testing.T) {
+			eosId, err := ParseEOSID(test.eosId)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, expected %v", err, test.err)
+			}
+
+			if eosId != test.expected {
+				t.Errorf("got %q, expected %q", eosId, test.expected)
+			}
+		})
+	}
+}
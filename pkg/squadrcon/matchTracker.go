@@ -0,0 +1,273 @@
+package squadrcon
+
+import (
+	"squad-rcon-go/pkg/rcon"
+	"sync"
+	"time"
+)
+
+// Match is a single game on a layer.
+type Match struct {
+	// Id is assigned locally by the MatchTracker, starting at 1. It is not known to the server.
+	Id int
+
+	Layer LayerInfo
+
+	// StartTime is estimated from the play time reported by ShowServerInfo when available,
+	// otherwise it is the time the match was first observed.
+	StartTime time.Time
+
+	// StartObserved is false for the match that was in progress when tracking started.
+	StartObserved bool
+
+	// EndTime is zero while the match is in progress.
+	EndTime time.Time
+}
+
+// MatchEvent is implemented by all events emitted by the MatchTracker.
+type MatchEvent interface {
+	GetMatch() Match
+
+	GetTime() time.Time
+}
+
+type matchEventBase struct {
+	Time  time.Time
+	Match Match
+}
+
+func (e matchEventBase) GetMatch() Match {
+	return e.Match
+}
+
+func (e matchEventBase) GetTime() time.Time {
+	return e.Time
+}
+
+type MatchStartedEvent struct {
+	matchEventBase
+}
+
+type MatchEndedEvent struct {
+	matchEventBase
+}
+
+// NextLayerChangedEvent is emitted when the next layer of the current match changes.
+type NextLayerChangedEvent struct {
+	matchEventBase
+	PreviousNextLayer LayerInfo
+	NextLayer         LayerInfo
+}
+
+// MatchObservation is the state of the server used by the MatchTracker to detect changes.
+type MatchObservation struct {
+	Time        time.Time
+	Current     LayerInfo
+	Next        LayerInfo
+	PlayTime    time.Duration
+	HasPlayTime bool
+}
+
+const (
+	defaultMatchPollInterval = 10 * time.Second
+)
+
+type MatchTrackerSettings struct {
+	// Interval between polls. Defaults to 10 seconds.
+	Interval time.Duration
+
+	// EventBus receives all events on the match topic. Optional.
+	EventBus *EventBus
+
+	// OnError is called when polling fails. Optional.
+	OnError func(err error)
+}
+
+// MatchTracker polls ShowCurrentMap, ShowNextMap and, where available, ShowServerInfo to detect
+// new matches and changes of the next layer.
+//
+// A new match is detected when the current layer changes or, if ShowServerInfo is available,
+// when the play time decreases, which also detects a layer being played twice in a row.
+// The match in progress when tracking starts is reported with a MatchStartedEvent whose match has
+// StartObserved set to false.
+type MatchTracker struct {
+	rcon        rcon.Rcon
+	settings    MatchTrackerSettings
+	subscribers subscribers[MatchEvent]
+	done        chan struct{}
+	stopOnce    sync.Once
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// Nil until the first update.
+	current *Match
+
+	next LayerInfo
+
+	// Play time of the last observation, zero if unknown.
+	playTime time.Duration
+
+	// The ID of the last match.
+	lastId int
+}
+
+// NewMatchTracker creates a MatchTracker and starts polling. Close stops polling.
+func NewMatchTracker(rcon rcon.Rcon, settings MatchTrackerSettings) *MatchTracker {
+	if settings.Interval <= 0 {
+		settings.Interval = defaultMatchPollInterval
+	}
+
+	t := &MatchTracker{
+		rcon:     rcon,
+		settings: settings,
+		done:     make(chan struct{}),
+	}
+
+	go t.run()
+
+	return t
+}
+
+// Subscribe returns a subscription that receives all subsequent events. Events are dropped when
+// more than bufferSize events are pending.
+func (t *MatchTracker) Subscribe(bufferSize int) *Subscription[MatchEvent] {
+	return t.subscribers.subscribe(bufferSize, OverflowDropNewest)
+}
+
+// CurrentMatch returns the match in progress. Returns false before the first successful poll.
+func (t *MatchTracker) CurrentMatch() (Match, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.current == nil {
+		return Match{}, false
+	}
+
+	return *t.current, true
+}
+
+// NextLayer returns the next layer as of the last poll.
+func (t *MatchTracker) NextLayer() LayerInfo {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.next
+}
+
+// Close stops polling and closes all subscriptions.
+func (t *MatchTracker) Close() {
+	t.stopOnce.Do(func() {
+		close(t.done)
+		t.subscribers.close()
+	})
+}
+
+func (t *MatchTracker) run() {
+	ticker := time.NewTicker(t.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		t.poll()
+
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *MatchTracker) poll() {
+	current, err := ShowCurrentMap(t.rcon)
+	if err != nil {
+		t.reportError(err)
+		return
+	}
+
+	next, err := ShowNextMap(t.rcon)
+	if err != nil {
+		t.reportError(err)
+		return
+	}
+
+	observation := MatchObservation{
+		Time:    time.Now(),
+		Current: current,
+		Next:    next,
+	}
+
+	// ShowServerInfo is not available on all servers, it only improves detection.
+	if info, err := ShowServerInfo(t.rcon); err == nil {
+		observation.PlayTime = info.PlayTime
+		observation.HasPlayTime = true
+	}
+
+	t.Update(observation)
+}
+
+func (t *MatchTracker) reportError(err error) {
+	if t.settings.OnError != nil {
+		t.settings.OnError(err)
+	}
+}
+
+// Update applies an observation and emits the resulting events. It is called for every poll but
+// can also be used to apply observations obtained elsewhere.
+func (t *MatchTracker) Update(observation MatchObservation) {
+	events := t.apply(observation)
+
+	// Published unlocked so that synchronous handlers can query the tracker.
+	publishAll(t.settings.EventBus, TopicMatch, events)
+}
+
+// apply updates the state and publishes the resulting events to the subscriptions.
+func (t *MatchTracker) apply(observation MatchObservation) []MatchEvent {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if observation.Current.IsEmpty() {
+		return nil
+	}
+
+	var events []MatchEvent
+
+	restarted := observation.HasPlayTime && t.playTime > 0 && observation.PlayTime < t.playTime
+	if t.current == nil || !t.current.Layer.Equal(observation.Current) || restarted {
+		if t.current != nil {
+			t.current.EndTime = observation.Time
+			events = append(events, MatchEndedEvent{matchEventBase{observation.Time, *t.current}})
+		}
+
+		t.lastId++
+		match := &Match{
+			Id:            t.lastId,
+			Layer:         observation.Current,
+			StartTime:     observation.Time,
+			StartObserved: t.current != nil,
+		}
+
+		if observation.HasPlayTime {
+			match.StartTime = observation.Time.Add(-observation.PlayTime)
+		}
+
+		t.current = match
+		events = append(events, MatchStartedEvent{matchEventBase{observation.Time, *match}})
+	} else if !t.next.Equal(observation.Next) {
+		events = append(events, NextLayerChangedEvent{
+			matchEventBase{observation.Time, *t.current},
+			t.next,
+			observation.Next,
+		})
+	}
+
+	t.next = observation.Next
+	t.playTime = 0
+	if observation.HasPlayTime {
+		t.playTime = observation.PlayTime
+	}
+
+	// Publishing does not block, doing so while locked keeps concurrent updates in order.
+	t.subscribers.publish(events...)
+
+	return events
+}
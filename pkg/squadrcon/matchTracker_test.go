@@ -0,0 +1,198 @@
+package squadrcon
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// describeMatchEvents returns the type, match ID and layer of each event.
+func describeMatchEvents(events []MatchEvent) []string {
+	var descriptions []string
+	for _, event := range events {
+		match := event.GetMatch()
+		description := fmt.Sprintf("%T %d %s", event, match.Id, match.Layer.Layer)
+
+		switch event := event.(type) {
+		case MatchStartedEvent:
+			if !match.StartObserved {
+				description += " in progress"
+			}
+		case NextLayerChangedEvent:
+			description += fmt.Sprintf(" next %s to %s", event.PreviousNextLayer.Layer, event.NextLayer.Layer)
+		}
+
+		descriptions = append(descriptions, strings.TrimPrefix(description, "squadrcon."))
+	}
+	return descriptions
+}
+
+func TestMatchTrackerApply(t *testing.T) {
+	tracker := &MatchTracker{}
+	subscription := tracker.Subscribe(16)
+	start := time.Unix(1700000000, 0)
+
+	narva := LayerInfo{Level: "Narva", Layer: "Narva_AAS_v1", Factions: []string{"USA", "RGF"}}
+	gorodok := LayerInfo{Level: "Gorodok", Layer: "Gorodok_RAAS_v1", Factions: []string{"CAF", "RGF"}}
+	yehorivka := LayerInfo{Level: "Yehorivka", Layer: "Yehorivka_RAAS_v2", Factions: []string{"CAF", "VDV"}}
+
+	steps := []struct {
+		name        string
+		current     LayerInfo
+		next        LayerInfo
+		playTime    time.Duration
+		hasPlayTime bool
+		expected    []string
+		startTime   time.Time
+	}{
+		{
+			name: "empty current layer before the first match",
+			next: gorodok,
+		},
+		{
+			name:        "match in progress",
+			current:     narva,
+			next:        gorodok,
+			playTime:    600 * time.Second,
+			hasPlayTime: true,
+			expected:    []string{"MatchStartedEvent 1 Narva_AAS_v1 in progress"},
+			startTime:   start.Add(-599 * time.Second),
+		},
+		{
+			name:        "unchanged",
+			current:     narva,
+			next:        gorodok,
+			playTime:    610 * time.Second,
+			hasPlayTime: true,
+			startTime:   start.Add(-599 * time.Second),
+		},
+		{
+			name:        "next layer changed",
+			current:     narva,
+			next:        yehorivka,
+			playTime:    620 * time.Second,
+			hasPlayTime: true,
+			expected:    []string{"NextLayerChangedEvent 1 Narva_AAS_v1 next Gorodok_RAAS_v1 to Yehorivka_RAAS_v2"},
+			startTime:   start.Add(-599 * time.Second),
+		},
+		{
+			name:        "layer changed",
+			current:     yehorivka,
+			next:        gorodok,
+			playTime:    3 * time.Second,
+			hasPlayTime: true,
+			expected:    []string{"MatchEndedEvent 1 Narva_AAS_v1", "MatchStartedEvent 2 Yehorivka_RAAS_v2"},
+			startTime:   start.Add(time.Second),
+		},
+		{
+			name:        "layer restarted",
+			current:     yehorivka,
+			next:        gorodok,
+			playTime:    2 * time.Second,
+			hasPlayTime: true,
+			expected:    []string{"MatchEndedEvent 2 Yehorivka_RAAS_v2", "MatchStartedEvent 3 Yehorivka_RAAS_v2"},
+			startTime:   start.Add(3 * time.Second),
+		},
+		{
+			name:      "empty current layer during a layer change",
+			next:      narva,
+			startTime: start.Add(3 * time.Second),
+		},
+		{
+			name:      "play time unavailable",
+			current:   yehorivka,
+			next:      gorodok,
+			startTime: start.Add(3 * time.Second),
+		},
+		{
+			name:        "play time available again",
+			current:     yehorivka,
+			next:        gorodok,
+			playTime:    time.Second,
+			hasPlayTime: true,
+			startTime:   start.Add(3 * time.Second),
+		},
+		{
+			name:      "layer changed without play time",
+			current:   narva,
+			next:      gorodok,
+			expected:  []string{"MatchEndedEvent 3 Yehorivka_RAAS_v2", "MatchStartedEvent 4 Narva_AAS_v1"},
+			startTime: start.Add(9 * time.Second),
+		},
+	}
+
+	for i, step := range steps {
+		events := tracker.apply(MatchObservation{
+			Time:        start.Add(time.Duration(i) * time.Second),
+			Current:     step.current,
+			Next:        step.next,
+			PlayTime:    step.playTime,
+			HasPlayTime: step.hasPlayTime,
+		})
+
+		if descriptions := describeMatchEvents(events); !reflect.DeepEqual(descriptions, step.expected) {
+			t.Errorf("%s: got %q, expected %q", step.name, descriptions, step.expected)
+		}
+
+		match, ok := tracker.CurrentMatch()
+		if ok != !step.startTime.IsZero() {
+			t.Fatalf("%s: got current match %+v, %v", step.name, match, ok)
+		}
+		if ok && !match.StartTime.Equal(step.startTime) {
+			t.Errorf("%s: got start time %s, expected %s", step.name, match.StartTime, step.startTime)
+		}
+	}
+
+	if next := tracker.NextLayer(); !next.Equal(gorodok) {
+		t.Errorf("got next layer %+v, expected %+v", next, gorodok)
+	}
+
+	var received []MatchEvent
+	for len(subscription.Events()) > 0 {
+		received = append(received, <-subscription.Events())
+	}
+	if len(received) != 8 {
+		t.Fatalf("got %q, expected the subscription to receive all events", describeMatchEvents(received))
+	}
+	if ended, ok := received[2].(MatchEndedEvent); !ok || !ended.Match.EndTime.Equal(start.Add(4*time.Second)) {
+		t.Errorf("got %+v, expected the first match to end at the layer change", received[2])
+	}
+}
+
+func TestMatchTrackerPoll(t *testing.T) {
+	rcon := newFakeRcon(map[string]string{
+		"ShowCurrentMap": "Current level is Narva, layer is Narva_AAS_v1, factions USA RGF",
+		"ShowNextMap":    "Next level is Yehorivka, layer is Yehorivka_RAAS_v2, factions CAF VDV",
+		"ShowServerInfo": `{"ServerName_s":"Squad RCON Emulator","MapName_s":"Narva_AAS_v1","NextLayer_s":"Yehorivka_RAAS_v2","PLAYTIME_I":754}`,
+	})
+	bus := NewEventBus(EventBusSettings{})
+	defer bus.Close()
+	subscription := bus.Subscribe(SubscribeOptions{Topics: []Topic{TopicMatch}})
+
+	tracker := &MatchTracker{rcon: rcon, settings: MatchTrackerSettings{EventBus: bus}}
+	before := time.Now()
+	tracker.poll()
+	after := time.Now()
+
+	match, ok := tracker.CurrentMatch()
+	if !ok || match.Layer.Layer != "Narva_AAS_v1" || match.StartObserved {
+		t.Fatalf("got %+v, %v, expected the match in progress on Narva", match, ok)
+	}
+	if match.StartTime.Before(before.Add(-754*time.Second)) || match.StartTime.After(after.Add(-754*time.Second)) {
+		t.Errorf("got start time %s, expected 754 seconds before the poll", match.StartTime)
+	}
+	if next := tracker.NextLayer(); next.Layer != "Yehorivka_RAAS_v2" {
+		t.Errorf("got next layer %+v, expected Yehorivka", next)
+	}
+
+	select {
+	case event := <-subscription.Events():
+		if _, ok := event.Payload.(MatchStartedEvent); !ok || event.Topic != TopicMatch {
+			t.Errorf("got %+v, expected the match to be published", event)
+		}
+	default:
+		t.Error("expected the match to be published on the event bus")
+	}
+}
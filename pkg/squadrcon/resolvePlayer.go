@@ -0,0 +1,95 @@
+package squadrcon
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrPlayerNotFound = errors.New("no player matches the selector")
+)
+
+// AmbiguousPlayerError is returned by ResolvePlayer when a selector matches multiple players.
+type AmbiguousPlayerError struct {
+	Selector   string
+	Candidates []ActivePlayer
+}
+
+func (e *AmbiguousPlayerError) Error() string {
+	candidates := make([]string, len(e.Candidates))
+	for i, candidate := range e.Candidates {
+		candidates[i] = fmt.Sprintf("%s (ID: %d)", candidate.Name, candidate.MatchId)
+	}
+
+	return fmt.Sprintf(
+		"selector \"%s\" matches %d players: %s",
+		e.Selector,
+		len(e.Candidates),
+		strings.Join(candidates, ", "),
+	)
+}
+
+var steamIdSelectorRegex = regexp.MustCompile(`^\d{17}$`)
+var eosIdSelectorRegex = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// ResolvePlayer finds the active player identified by selector. The selector is matched against,
+// in order of precedence, the Steam ID, EOS ID, match ID, exact name, case-insensitive name and
+// case-insensitive substring of the name. The first criterion that matches any player decides the
+// result. If this criterion matches multiple players, an *AmbiguousPlayerError is returned.
+// ErrPlayerNotFound is returned when no player matches.
+func ResolvePlayer(selector string, players PlayerList) (ActivePlayer, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return ActivePlayer{}, fmt.Errorf("%w: selector is empty", ErrPlayerNotFound)
+	}
+
+	lowerSelector := strings.ToLower(selector)
+	matchId, matchIdErr := strconv.Atoi(selector)
+
+	criteria := []func(player ActivePlayer) bool{
+		func(player ActivePlayer) bool {
+			return steamIdSelectorRegex.MatchString(selector) && player.SteamId.String() == selector
+		},
+		func(player ActivePlayer) bool {
+			return eosIdSelectorRegex.MatchString(selector) && player.EosId == EOSID(lowerSelector)
+		},
+		func(player ActivePlayer) bool {
+			return matchIdErr == nil && player.MatchId == matchId
+		},
+		func(player ActivePlayer) bool {
+			return player.Name == selector
+		},
+		func(player ActivePlayer) bool {
+			return strings.EqualFold(player.Name, selector)
+		},
+		func(player ActivePlayer) bool {
+			return strings.Contains(strings.ToLower(player.Name), lowerSelector)
+		},
+	}
+
+	for _, matches := range criteria {
+		var candidates []ActivePlayer
+		for _, player := range players.ActivePlayers {
+			if matches(player) {
+				candidates = append(candidates, player)
+			}
+		}
+
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			return candidates[0], nil
+		default:
+			return ActivePlayer{}, &AmbiguousPlayerError{
+				Selector:   selector,
+				Candidates: candidates,
+			}
+		}
+	}
+
+	return ActivePlayer{}, fmt.Errorf("%w: %s", ErrPlayerNotFound, selector)
+}
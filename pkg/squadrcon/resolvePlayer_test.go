@@ -0,0 +1,90 @@
+package squadrcon
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolvePlayer(t *testing.T) {
+	players := PlayerList{
+		ActivePlayers: []ActivePlayer{
+			{MatchId: 0, SteamId: 76561197999957991, EosId: "0002a10186d9414496bf20d22d3860ba", Name: "✯RAIDR✯Jon"},
+			{MatchId: 1, SteamId: 76561197989362395, Name: "Jon"},
+			{MatchId: 2, EosId: "0002b2c3d4e5f60718293a4b5c6d7e8f", Name: "creaman"},
+			{MatchId: 3, SteamId: 76561198000000001, Name: "CREAMAN"},
+			{MatchId: 76561198000000002, Name: "76561197999957991"},
+			{MatchId: 10, Name: "jonathan"},
+			{MatchId: 11, Name: "10"},
+		},
+		DisconnectedPlayers: []DisconnectedPlayer{
+			{MatchId: 4, SteamId: 76561198000000003, Name: "gone"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		selector   string
+		matchId    int
+		err        error
+		candidates []int
+	}{
+		{name: "Steam ID", selector: "76561197989362395", matchId: 1},
+		{name: "Steam ID takes precedence over name", selector: "76561197999957991", matchId: 0},
+		{name: "EOS ID", selector: "0002b2c3d4e5f60718293a4b5c6d7e8f", matchId: 2},
+		{name: "EOS ID is case-insensitive", selector: "0002A10186D9414496BF20D22D3860BA", matchId: 0},
+		{name: "match ID", selector: "3", matchId: 3},
+		{name: "match ID takes precedence over name", selector: "10", matchId: 10},
+		{name: "exact name beats substring", selector: "Jon", matchId: 1},
+		{name: "exact name beats case-insensitive name", selector: "creaman", matchId: 2},
+		{name: "case-insensitive name", selector: "JONATHAN", matchId: 10},
+		{name: "substring", selector: "raidr", matchId: 0},
+		{name: "surrounding whitespace", selector: "  Jon ", matchId: 1},
+		{name: "ambiguous case-insensitive name", selector: "Creaman", candidates: []int{2, 3}},
+		{name: "ambiguous substring", selector: "jo", candidates: []int{0, 1, 10}},
+		{name: "disconnected player", selector: "gone", err: ErrPlayerNotFound},
+		{name: "unknown Steam ID", selector: "76561198000000009", err: ErrPlayerNotFound},
+		{name: "empty", selector: " ", err: ErrPlayerNotFound},
+	}
+
+	for _, test := range tests {
+		player, err := ResolvePlayer(test.selector, players)
+
+		if test.candidates != nil {
+			var ambiguous *AmbiguousPlayerError
+			if !errors.As(err, &ambiguous) {
+				t.Errorf("%s: got %+v, %v, expected an ambiguous selector", test.name, player, err)
+				continue
+			}
+
+			var candidates []int
+			for _, candidate := range ambiguous.Candidates {
+				candidates = append(candidates, candidate.MatchId)
+			}
+			if !reflect.DeepEqual(candidates, test.candidates) {
+				t.Errorf("%s: got candidates %v, expected %v", test.name, candidates, test.candidates)
+			}
+			continue
+		}
+
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v, expected %v", test.name, err, test.err)
+			continue
+		}
+		if err == nil && player.MatchId != test.matchId {
+			t.Errorf("%s: got %s (ID: %d), expected ID %d", test.name, player.Name, player.MatchId, test.matchId)
+		}
+	}
+}
+
+func TestAmbiguousPlayerError(t *testing.T) {
+	err := &AmbiguousPlayerError{
+		Selector:   "jon",
+		Candidates: []ActivePlayer{{MatchId: 0, Name: "✯RAIDR✯Jon"}, {MatchId: 1, Name: "Jon"}},
+	}
+
+	expected := `selector "jon" matches 2 players: ✯RAIDR✯Jon (ID: 0), Jon (ID: 1)`
+	if err.Error() != expected {
+		t.Errorf("got %s, expected %s", err.Error(), expected)
+	}
+}
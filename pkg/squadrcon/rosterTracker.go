@@ -0,0 +1,312 @@
+package squadrcon
+
+import (
+	"errors"
+	"sort"
+	"squad-rcon-go/pkg/rcon"
+	"sync"
+	"time"
+)
+
+// RosterEvent is implemented by all events emitted by the RosterTracker.
+type RosterEvent interface {
+	// GetPlayer returns the player as seen in the poll that triggered the event. For
+	// PlayerLeftEvent, this is the last known state of the player.
+	GetPlayer() ActivePlayer
+
+	GetTime() time.Time
+}
+
+type rosterEventBase struct {
+	Time   time.Time
+	Player ActivePlayer
+}
+
+func (e rosterEventBase) GetPlayer() ActivePlayer {
+	return e.Player
+}
+
+func (e rosterEventBase) GetTime() time.Time {
+	return e.Time
+}
+
+type PlayerJoinedEvent struct {
+	rosterEventBase
+}
+
+type PlayerLeftEvent struct {
+	rosterEventBase
+}
+
+// PlayerReconnectedEvent is emitted instead of PlayerJoinedEvent when a player rejoins within the
+// reconnect window.
+type PlayerReconnectedEvent struct {
+	rosterEventBase
+	LeftAt time.Time
+}
+
+type TeamChangedEvent struct {
+	rosterEventBase
+	PreviousTeamIndex int
+}
+
+type SquadJoinedEvent struct {
+	rosterEventBase
+}
+
+type SquadLeftEvent struct {
+	rosterEventBase
+	PreviousTeamIndex  int
+	PreviousSquadIndex int
+}
+
+type BecameSquadLeadEvent struct {
+	rosterEventBase
+}
+
+type LostSquadLeadEvent struct {
+	rosterEventBase
+	PreviousTeamIndex  int
+	PreviousSquadIndex int
+}
+
+type KitChangedEvent struct {
+	rosterEventBase
+	PreviousKit string
+}
+
+type NameChangedEvent struct {
+	rosterEventBase
+	PreviousName string
+}
+
+const (
+	defaultRosterPollInterval    = 5 * time.Second
+	defaultRosterReconnectWindow = 5 * time.Minute
+)
+
+type RosterTrackerSettings struct {
+	// Interval between ListPlayers polls. Defaults to 5 seconds.
+	Interval time.Duration
+
+	// ReconnectWindow is the period after leaving in which a player rejoining is reported as
+	// reconnected rather than joined. Defaults to 5 minutes.
+	ReconnectWindow time.Duration
+
+	// EventBus receives all events on the roster topic. Optional.
+	EventBus *EventBus
+
+	// OnError is called when polling fails. Optional.
+	OnError func(err error)
+}
+
+// RosterTracker polls ListPlayers and emits events for the differences between polls.
+// The first successful poll establishes the initial roster and does not emit events.
+type RosterTracker struct {
+	rcon        rcon.Rcon
+	settings    RosterTrackerSettings
+	subscribers subscribers[RosterEvent]
+	done        chan struct{}
+	stopOnce    sync.Once
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// False until the first update.
+	initialized bool
+
+	// The current players, keyed by player ID.
+	players map[string]ActivePlayer
+
+	// The time players left, keyed by player ID.
+	departed map[string]time.Time
+}
+
+// NewRosterTracker creates a RosterTracker and starts polling. Close stops polling.
+func NewRosterTracker(rcon rcon.Rcon, settings RosterTrackerSettings) *RosterTracker {
+	if settings.Interval <= 0 {
+		settings.Interval = defaultRosterPollInterval
+	}
+
+	if settings.ReconnectWindow <= 0 {
+		settings.ReconnectWindow = defaultRosterReconnectWindow
+	}
+
+	t := &RosterTracker{
+		rcon:     rcon,
+		settings: settings,
+		done:     make(chan struct{}),
+		players:  make(map[string]ActivePlayer),
+		departed: make(map[string]time.Time),
+	}
+
+	go t.run()
+
+	return t
+}
+
+// Subscribe returns a subscription that receives all subsequent events. Events are dropped when
+// more than bufferSize events are pending.
+func (t *RosterTracker) Subscribe(bufferSize int) *Subscription[RosterEvent] {
+	return t.subscribers.subscribe(bufferSize, OverflowDropNewest)
+}
+
+// Players returns the current players ordered by match ID.
+func (t *RosterTracker) Players() []ActivePlayer {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	players := make([]ActivePlayer, 0, len(t.players))
+	for _, player := range t.players {
+		players = append(players, player)
+	}
+
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].MatchId < players[j].MatchId
+	})
+
+	return players
+}
+
+// Close stops polling and closes all subscriptions.
+func (t *RosterTracker) Close() {
+	t.stopOnce.Do(func() {
+		close(t.done)
+		t.subscribers.close()
+	})
+}
+
+func (t *RosterTracker) run() {
+	ticker := time.NewTicker(t.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		t.poll()
+
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *RosterTracker) poll() {
+	list, err := ListPlayers(t.rcon)
+	if err != nil {
+		// A response that is not a player list does not mean that all players left.
+		if !errors.Is(err, ErrResponseIsNotPlayerList) && t.settings.OnError != nil {
+			t.settings.OnError(err)
+		}
+		return
+	}
+
+	t.Update(list, time.Now())
+}
+
+// Update applies a player list to the roster and emits the resulting events. It is called for
+// every poll but can also be used to apply player lists obtained elsewhere.
+func (t *RosterTracker) Update(list PlayerList, at time.Time) {
+	events := t.apply(list, at)
+
+	// Published unlocked so that synchronous handlers can query the tracker.
+	publishAll(t.settings.EventBus, TopicRoster, events)
+}
+
+// apply updates the state and publishes the resulting events to the subscriptions.
+func (t *RosterTracker) apply(list PlayerList, at time.Time) []RosterEvent {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	current := make(map[string]ActivePlayer, len(list.ActivePlayers))
+	for _, player := range list.ActivePlayers {
+		current[PlayerKey(player)] = player
+	}
+
+	var events []RosterEvent
+	if t.initialized {
+		events = t.diff(current, at)
+	}
+
+	for key, leftAt := range t.departed {
+		if at.Sub(leftAt) > t.settings.ReconnectWindow {
+			delete(t.departed, key)
+		}
+	}
+
+	t.players = current
+	t.initialized = true
+
+	// Publishing does not block, doing so while locked keeps concurrent updates in order.
+	t.subscribers.publish(events...)
+
+	return events
+}
+
+func (t *RosterTracker) diff(current map[string]ActivePlayer, at time.Time) []RosterEvent {
+	var events []RosterEvent
+
+	for key, previous := range t.players {
+		if _, exists := current[key]; !exists {
+			events = append(events, PlayerLeftEvent{rosterEventBase{at, previous}})
+			t.departed[key] = at
+		}
+	}
+
+	for key, player := range current {
+		base := rosterEventBase{at, player}
+		previous, exists := t.players[key]
+
+		if !exists {
+			if leftAt, reconnected := t.departed[key]; reconnected {
+				delete(t.departed, key)
+				events = append(events, PlayerReconnectedEvent{base, leftAt})
+			} else {
+				events = append(events, PlayerJoinedEvent{base})
+			}
+			continue
+		}
+
+		if previous.Name != player.Name {
+			events = append(events, NameChangedEvent{base, previous.Name})
+		}
+
+		if previous.TeamIndex != player.TeamIndex {
+			events = append(events, TeamChangedEvent{base, previous.TeamIndex})
+		}
+
+		changedSquad := previous.TeamIndex != player.TeamIndex ||
+			previous.SquadIndex != player.SquadIndex
+
+		if previous.IsSquadLead && (!player.IsSquadLead || changedSquad) {
+			events = append(events, LostSquadLeadEvent{
+				base,
+				previous.TeamIndex,
+				previous.SquadIndex,
+			})
+		}
+
+		if changedSquad && previous.SquadIndex != 0 {
+			events = append(events, SquadLeftEvent{base, previous.TeamIndex, previous.SquadIndex})
+		}
+
+		if changedSquad && player.SquadIndex != 0 {
+			events = append(events, SquadJoinedEvent{base})
+		}
+
+		if player.IsSquadLead && (!previous.IsSquadLead || changedSquad) {
+			events = append(events, BecameSquadLeadEvent{base})
+		}
+
+		if previous.Kit != player.Kit {
+			events = append(events, KitChangedEvent{base, previous.Kit})
+		}
+	}
+
+	// Iterating maps results in a random order, keep the events ordered by player.
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].GetPlayer().MatchId < events[j].GetPlayer().MatchId
+	})
+
+	return events
+}
@@ -0,0 +1,79 @@
+package squadrcon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestRosterTracker() *RosterTracker {
+	return &RosterTracker{
+		settings: RosterTrackerSettings{ReconnectWindow: defaultRosterReconnectWindow},
+		players:  make(map[string]ActivePlayer),
+		departed: make(map[string]time.Time),
+	}
+}
+
+func TestRosterTrackerEvents(t *testing.T) {
+	tracker := newTestRosterTracker()
+	start := time.Unix(1700000000, 0)
+
+	jon := ActivePlayer{MatchId: 0, SteamId: 76561197999957991, Name: "Jon", TeamIndex: 1, Kit: "USA_Rifleman_01"}
+	creaman := ActivePlayer{MatchId: 1, SteamId: 76561197989362395, Name: "creaman", TeamIndex: 2, Kit: "INS_Rifleman_01"}
+
+	if events := tracker.apply(PlayerList{ActivePlayers: []ActivePlayer{jon}}, start); len(events) != 0 {
+		t.Fatalf("expected the first update not to emit events, got %+v", events)
+	}
+
+	renamed := jon
+	renamed.Name = "✯RAIDR✯Jon"
+	renamed.SquadIndex = 1
+	renamed.IsSquadLead = true
+	renamed.Kit = "USA_SL_01"
+	at := start.Add(time.Second)
+
+	expected := []RosterEvent{
+		NameChangedEvent{rosterEventBase{at, renamed}, "Jon"},
+		SquadJoinedEvent{rosterEventBase{at, renamed}},
+		BecameSquadLeadEvent{rosterEventBase{at, renamed}},
+		KitChangedEvent{rosterEventBase{at, renamed}, "USA_Rifleman_01"},
+		PlayerJoinedEvent{rosterEventBase{at, creaman}},
+	}
+	events := tracker.apply(PlayerList{ActivePlayers: []ActivePlayer{renamed, creaman}}, at)
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("got %+v, expected %+v", events, expected)
+	}
+
+	left := start.Add(2 * time.Second)
+	events = tracker.apply(PlayerList{ActivePlayers: []ActivePlayer{renamed}}, left)
+	if !reflect.DeepEqual(events, []RosterEvent{PlayerLeftEvent{rosterEventBase{left, creaman}}}) {
+		t.Errorf("got %+v, expected creaman to leave", events)
+	}
+
+	rejoined := creaman
+	rejoined.MatchId = 2
+	at = start.Add(time.Minute)
+	events = tracker.apply(PlayerList{ActivePlayers: []ActivePlayer{renamed, rejoined}}, at)
+	if !reflect.DeepEqual(events, []RosterEvent{PlayerReconnectedEvent{rosterEventBase{at, rejoined}, left}}) {
+		t.Errorf("got %+v, expected creaman to reconnect", events)
+	}
+}
+
+func TestRosterTrackerPlayersWithoutIds(t *testing.T) {
+	tracker := newTestRosterTracker()
+	start := time.Unix(1700000000, 0)
+
+	first := ActivePlayer{MatchId: 3, Name: "first", TeamIndex: 1}
+	second := ActivePlayer{MatchId: 4, Name: "second", TeamIndex: 2}
+	list := PlayerList{ActivePlayers: []ActivePlayer{first, second}}
+
+	tracker.apply(list, start)
+	if players := tracker.Players(); !reflect.DeepEqual(players, list.ActivePlayers) {
+		t.Fatalf("got %+v, expected both players", players)
+	}
+
+	// Polling the same players again must not be reported as changes.
+	if events := tracker.apply(list, start.Add(time.Second)); len(events) != 0 {
+		t.Errorf("got %+v, expected no events", events)
+	}
+}
@@ -0,0 +1,57 @@
+package squadrcon
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// sampleTimestampRegex matches the timestamps separating the responses captured in data/.
+var sampleTimestampRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-\d{2}:\d{2}:\d{2}$`)
+
+// readSamples returns the responses captured in the code blocks of data/<name>. Responses are
+// separated by timestamps, empty responses are skipped.
+func readSamples(t *testing.T, name string) []string {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("..", "..", "data", name))
+	if err != nil {
+		t.Fatalf("could not open sample: %v", err)
+	}
+	defer file.Close()
+
+	var samples []string
+	var sample strings.Builder
+	inCodeBlock := false
+	flush := func() {
+		if sample.Len() != 0 {
+			samples = append(samples, sample.String())
+			sample.Reset()
+		}
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "```"):
+			flush()
+			inCodeBlock = !inCodeBlock
+		case !inCodeBlock:
+		case sampleTimestampRegex.MatchString(line):
+			flush()
+		default:
+			sample.WriteString(line + "\n")
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("could not read sample: %v", err)
+	}
+
+	return samples
+}
@@ -0,0 +1,437 @@
+package squadrcon
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// ServerMessage is implemented by all messages returned by ParseServerMessage.
+type ServerMessage interface {
+	// GetRaw returns the message as sent by the server.
+	GetRaw() string
+}
+
+type serverMessageBase struct {
+	Raw string
+}
+
+func (m serverMessageBase) GetRaw() string {
+	return m.Raw
+}
+
+// UnknownMessage is returned for messages that do not match any pattern.
+type UnknownMessage struct {
+	serverMessageBase
+}
+
+type ChatChannel string
+
+const (
+	ChatAll   ChatChannel = "ChatAll"
+	ChatTeam  ChatChannel = "ChatTeam"
+	ChatSquad ChatChannel = "ChatSquad"
+	ChatAdmin ChatChannel = "ChatAdmin"
+)
+
+// ChatMessage is sent when a player chats, e.g.
+// `[ChatAll] [SteamID:76561197989362395] ✯RAIDR✯creaman : SL1 SQUAD IS SQUADBAITING`.
+type ChatMessage struct {
+	serverMessageBase
+	Channel    ChatChannel
+	SteamId    SteamID64
+	EosId      EOSID
+	PlayerName string
+	Message    string
+}
+
+// SenderKey returns the ID of the sender as returned by PlayerKey for the sending player. Empty if
+// the message has no IDs, which does not occur for messages parsed from the server.
+func (m ChatMessage) SenderKey() string {
+	return idKey(m.SteamId, m.EosId)
+}
+
+// AdminCameraPossessedMessage is sent when an admin enters the admin camera, e.g.
+// `[SteamID:76561197999957991] Jon has possessed admin camera.`.
+type AdminCameraPossessedMessage struct {
+	serverMessageBase
+	SteamId    SteamID64
+	EosId      EOSID
+	PlayerName string
+}
+
+// AdminCameraUnpossessedMessage is sent when an admin leaves the admin camera.
+type AdminCameraUnpossessedMessage struct {
+	serverMessageBase
+	SteamId    SteamID64
+	EosId      EOSID
+	PlayerName string
+}
+
+// PlayerWarnedMessage confirms AdminWarn, e.g.
+// `Remote admin has warned player Jon. Message was "Stop"`.
+type PlayerWarnedMessage struct {
+	serverMessageBase
+	PlayerName string
+	Message    string
+}
+
+// PlayerKickedMessage confirms a kick, e.g.
+// `Kicked player 1. [steamid=76561197989362395] creaman`.
+type PlayerKickedMessage struct {
+	serverMessageBase
+	MatchId    int
+	SteamId    SteamID64
+	EosId      EOSID
+	PlayerName string
+}
+
+// PlayerBannedMessage confirms a ban, e.g.
+// `Banned player 1. [steamid=76561197989362395] creaman for interval 1d`.
+type PlayerBannedMessage struct {
+	serverMessageBase
+	MatchId    int
+	SteamId    SteamID64
+	EosId      EOSID
+	PlayerName string
+
+	// Interval contains the ban length as passed to AdminBan, e.g. `1d` or `0` for permanent.
+	Interval string
+}
+
+// TeamKillMessage is a team kill reported by the server. The format of these messages has not been
+// captured yet, so no default pattern exists. Register one using TeamKillPattern to receive them.
+type TeamKillMessage struct {
+	serverMessageBase
+	AttackerName string
+	VictimName   string
+}
+
+// SquadCreatedMessage is sent by the server when a player creates a squad, e.g.
+// `Jon (Steam ID: 76561197999957991) has created Squad 1 (Squad Name: ALPHA) on United States Army`.
+type SquadCreatedMessage struct {
+	serverMessageBase
+	CreatorName    string
+	CreatorSteamId SteamID64
+	CreatorEosId   EOSID
+	SquadId        int
+	SquadName      string
+
+	// Faction contains the full faction name, e.g. `United States Army`. This can differ from the
+	// faction in the ListSquads team header.
+	Faction string
+}
+
+// ServerMessagePattern recognises one message format.
+type ServerMessagePattern struct {
+	Regex *regexp.Regexp
+
+	// Parse converts the submatches of Regex into a message. Returning an error causes the next
+	// pattern to be tried.
+	Parse func(raw string, matches []string) (ServerMessage, error)
+}
+
+// ServerMessageParser converts messages sent by the server into typed messages using a table of
+// patterns. The first matching pattern determines the result.
+type ServerMessageParser struct {
+	// Lock to be used before accessing patterns.
+	lock sync.RWMutex
+
+	patterns []ServerMessagePattern
+}
+
+// NewServerMessageParser returns a parser that recognises the formats known to this package.
+func NewServerMessageParser() *ServerMessageParser {
+	return &ServerMessageParser{
+		patterns: defaultServerMessagePatterns(),
+	}
+}
+
+// Register adds a pattern. Registered patterns take precedence over the existing patterns so they
+// can be used to override the default patterns.
+func (p *ServerMessageParser) Register(pattern ServerMessagePattern) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.patterns = append([]ServerMessagePattern{pattern}, p.patterns...)
+}
+
+// Parse returns the typed message, or an UnknownMessage if no pattern matches.
+func (p *ServerMessageParser) Parse(message string) ServerMessage {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	for _, pattern := range p.patterns {
+		matches := pattern.Regex.FindStringSubmatch(message)
+		if matches == nil {
+			continue
+		}
+
+		parsed, err := pattern.Parse(message, matches)
+		if err == nil {
+			return parsed
+		}
+	}
+
+	return UnknownMessage{serverMessageBase{message}}
+}
+
+var defaultServerMessageParser = NewServerMessageParser()
+
+// RegisterServerMessagePattern registers a pattern on the parser used by ParseServerMessage.
+func RegisterServerMessagePattern(pattern ServerMessagePattern) {
+	defaultServerMessageParser.Register(pattern)
+}
+
+// ParseServerMessage parses a message sent by the server using the default parser.
+func ParseServerMessage(message string) ServerMessage {
+	return defaultServerMessageParser.Parse(message)
+}
+
+// ParseSquadCreatedMessage parses a squad creation message. Returns false if the message is not a
+// squad creation message.
+func ParseSquadCreatedMessage(message string) (SquadCreatedMessage, bool) {
+	matches := squadCreatedRegex.FindStringSubmatch(message)
+	if matches == nil {
+		return SquadCreatedMessage{}, false
+	}
+
+	parsed, err := parseSquadCreatedMessage(message, matches)
+	if err != nil {
+		return SquadCreatedMessage{}, false
+	}
+
+	return parsed.(SquadCreatedMessage), true
+}
+
+// messageIdsPattern matches the player IDs in chat and admin camera messages, both in the legacy
+// `[SteamID:<id>]` format and the `[Online IDs:EOS: <id> steam: <id>]` format. Contains three
+// groups: legacy Steam ID, EOS ID and Steam ID.
+const messageIdsPattern = `\[(?:SteamID:(\d+)|Online I[Dd]s:\s?EOS: ([0-9a-fA-F]+)(?: steam: (\d+))?)\]`
+
+// adminMessageIdsPattern matches the player IDs in kick and ban confirmations, both in the legacy
+// `[steamid=<id>]` format and the `[Online IDs= EOS: <id> steam: <id>]` format.
+const adminMessageIdsPattern = `\[(?:steamid=(\d+)|Online IDs=\s?EOS: ([0-9a-fA-F]+)(?: steam: (\d+))?)\]`
+
+var chatRegex = regexp.MustCompile(`^\[(ChatAll|ChatTeam|ChatSquad|ChatAdmin)\] ` + messageIdsPattern + ` (.+?) : (.*)$`)
+
+const (
+	_ = iota
+	chatChannel
+	chatLegacySteamId
+	chatEosId
+	chatSteamId
+	chatPlayerName
+	chatMessage
+)
+
+var adminCameraRegex = regexp.MustCompile(`^` + messageIdsPattern + ` (.+) has (possessed|unpossessed) admin camera\.?$`)
+
+const (
+	_ = iota
+	adminCameraLegacySteamId
+	adminCameraEosId
+	adminCameraSteamId
+	adminCameraPlayerName
+	adminCameraAction
+)
+
+var playerWarnedRegex = regexp.MustCompile(`^Remote admin has warned player (.+)\. Message was "(.*)"$`)
+
+const (
+	_ = iota
+	playerWarnedPlayerName
+	playerWarnedMessage
+)
+
+var playerKickedRegex = regexp.MustCompile(`^Kicked player (\d+)\. ` + adminMessageIdsPattern + ` (.+)$`)
+
+const (
+	_ = iota
+	playerKickedMatchId
+	playerKickedLegacySteamId
+	playerKickedEosId
+	playerKickedSteamId
+	playerKickedPlayerName
+)
+
+var playerBannedRegex = regexp.MustCompile(`^Banned player (\d+)\. ` + adminMessageIdsPattern + ` (.+) for interval (.+)$`)
+
+const (
+	_ = iota
+	playerBannedMatchId
+	playerBannedLegacySteamId
+	playerBannedEosId
+	playerBannedSteamId
+	playerBannedPlayerName
+	playerBannedInterval
+)
+
+var squadCreatedRegex = regexp.MustCompile(`^(.+) \((?:Steam ID: (\d+)|Online IDs:(?: EOS: ([0-9a-fA-F]+))?(?: steam: (\d+))?)\) has created Squad (\d+) \(Squad Name: (.+)\) on (.+)$`)
+
+const (
+	_ = iota
+	squadCreatedCreatorName
+	squadCreatedCreatorLegacySteamId
+	squadCreatedCreatorEosId
+	squadCreatedCreatorSteamId
+	squadCreatedSquadId
+	squadCreatedSquadName
+	squadCreatedFaction
+)
+
+func defaultServerMessagePatterns() []ServerMessagePattern {
+	return []ServerMessagePattern{
+		{Regex: chatRegex, Parse: parseChatMessage},
+		{Regex: adminCameraRegex, Parse: parseAdminCameraMessage},
+		{Regex: playerWarnedRegex, Parse: parsePlayerWarnedMessage},
+		{Regex: playerKickedRegex, Parse: parsePlayerKickedMessage},
+		{Regex: playerBannedRegex, Parse: parsePlayerBannedMessage},
+		{Regex: squadCreatedRegex, Parse: parseSquadCreatedMessage},
+	}
+}
+
+func parseChatMessage(raw string, matches []string) (ServerMessage, error) {
+	steamId, eosId, err := parsePlayerIds(
+		matches[chatLegacySteamId]+matches[chatSteamId],
+		matches[chatEosId],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ChatMessage{
+		serverMessageBase: serverMessageBase{raw},
+		Channel:           ChatChannel(matches[chatChannel]),
+		SteamId:           steamId,
+		EosId:             eosId,
+		PlayerName:        matches[chatPlayerName],
+		Message:           matches[chatMessage],
+	}, nil
+}
+
+func parseAdminCameraMessage(raw string, matches []string) (ServerMessage, error) {
+	steamId, eosId, err := parsePlayerIds(
+		matches[adminCameraLegacySteamId]+matches[adminCameraSteamId],
+		matches[adminCameraEosId],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if matches[adminCameraAction] == "unpossessed" {
+		return AdminCameraUnpossessedMessage{
+			serverMessageBase: serverMessageBase{raw},
+			SteamId:           steamId,
+			EosId:             eosId,
+			PlayerName:        matches[adminCameraPlayerName],
+		}, nil
+	}
+
+	return AdminCameraPossessedMessage{
+		serverMessageBase: serverMessageBase{raw},
+		SteamId:           steamId,
+		EosId:             eosId,
+		PlayerName:        matches[adminCameraPlayerName],
+	}, nil
+}
+
+func parsePlayerWarnedMessage(raw string, matches []string) (ServerMessage, error) {
+	return PlayerWarnedMessage{
+		serverMessageBase: serverMessageBase{raw},
+		PlayerName:        matches[playerWarnedPlayerName],
+		Message:           matches[playerWarnedMessage],
+	}, nil
+}
+
+func parsePlayerKickedMessage(raw string, matches []string) (ServerMessage, error) {
+	matchId, err := strconv.Atoi(matches[playerKickedMatchId])
+	if err != nil {
+		return nil, err
+	}
+
+	steamId, eosId, err := parsePlayerIds(
+		matches[playerKickedLegacySteamId]+matches[playerKickedSteamId],
+		matches[playerKickedEosId],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return PlayerKickedMessage{
+		serverMessageBase: serverMessageBase{raw},
+		MatchId:           matchId,
+		SteamId:           steamId,
+		EosId:             eosId,
+		PlayerName:        matches[playerKickedPlayerName],
+	}, nil
+}
+
+func parsePlayerBannedMessage(raw string, matches []string) (ServerMessage, error) {
+	matchId, err := strconv.Atoi(matches[playerBannedMatchId])
+	if err != nil {
+		return nil, err
+	}
+
+	steamId, eosId, err := parsePlayerIds(
+		matches[playerBannedLegacySteamId]+matches[playerBannedSteamId],
+		matches[playerBannedEosId],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return PlayerBannedMessage{
+		serverMessageBase: serverMessageBase{raw},
+		MatchId:           matchId,
+		SteamId:           steamId,
+		EosId:             eosId,
+		PlayerName:        matches[playerBannedPlayerName],
+		Interval:          matches[playerBannedInterval],
+	}, nil
+}
+
+// TeamKillPattern returns a pattern that parses messages matching regex as TeamKillMessage. The
+// first submatch of regex is the attacker name, the second the victim name, e.g.
+// `^(.+) team killed (.+)$`.
+func TeamKillPattern(regex *regexp.Regexp) ServerMessagePattern {
+	return ServerMessagePattern{
+		Regex: regex,
+		Parse: func(raw string, matches []string) (ServerMessage, error) {
+			if len(matches) < 3 {
+				return nil, fmt.Errorf("team kill pattern %s has fewer than 2 submatches", regex)
+			}
+
+			return TeamKillMessage{
+				serverMessageBase: serverMessageBase{raw},
+				AttackerName:      matches[1],
+				VictimName:        matches[2],
+			}, nil
+		},
+	}
+}
+
+func parseSquadCreatedMessage(raw string, matches []string) (ServerMessage, error) {
+	squadId, err := strconv.Atoi(matches[squadCreatedSquadId])
+	if err != nil {
+		return nil, err
+	}
+
+	steamId, eosId, err := parsePlayerIds(
+		matches[squadCreatedCreatorLegacySteamId]+matches[squadCreatedCreatorSteamId],
+		matches[squadCreatedCreatorEosId],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return SquadCreatedMessage{
+		serverMessageBase: serverMessageBase{raw},
+		CreatorName:       matches[squadCreatedCreatorName],
+		CreatorSteamId:    steamId,
+		CreatorEosId:      eosId,
+		SquadId:           squadId,
+		SquadName:         matches[squadCreatedSquadName],
+		Faction:           matches[squadCreatedFaction],
+	}, nil
+}
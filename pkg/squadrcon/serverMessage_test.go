@@ -0,0 +1,211 @@
+package squadrcon
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseServerMessageSamples(t *testing.T) {
+	var messages []string
+	for _, name := range []string{"ListPlayers.md", "ListSquads.md"} {
+		for _, sample := range readSamples(t, name) {
+			if !strings.HasPrefix(sample, "-----") {
+				messages = append(messages, strings.TrimSuffix(sample, "\n"))
+			}
+		}
+	}
+	if len(messages) == 0 {
+		t.Fatal("no samples")
+	}
+
+	for _, message := range messages {
+		if parsed, unknown := ParseServerMessage(message).(UnknownMessage); unknown {
+			t.Errorf("message was not recognised: %q", parsed.Raw)
+		}
+	}
+}
+
+func TestParseServerMessage(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected ServerMessage
+	}{
+		{
+			message: "[ChatAll] [SteamID:76561197989362395] ✯RAIDR✯creaman : SL1 SQUAD IS SQUADBAITING",
+			expected: ChatMessage{
+				Channel:    ChatAll,
+				SteamId:    76561197989362395,
+				PlayerName: "✯RAIDR✯creaman",
+				Message:    "SL1 SQUAD IS SQUADBAITING",
+			},
+		},
+		{
+			message: "[ChatSquad] [Online IDs:EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561197999957991] Jon : need ammo : now",
+			expected: ChatMessage{
+				Channel:    ChatSquad,
+				SteamId:    76561197999957991,
+				EosId:      "0002a10186d9414496bf20d22d3860ba",
+				PlayerName: "Jon",
+				Message:    "need ammo : now",
+			},
+		},
+		{
+			message: "[SteamID:76561197999957991] Jon has possessed admin camera.",
+			expected: AdminCameraPossessedMessage{
+				SteamId:    76561197999957991,
+				PlayerName: "Jon",
+			},
+		},
+		{
+			message: "[Online Ids:EOS: 0002a10186d9414496bf20d22d3860ba] Jon has unpossessed admin camera.",
+			expected: AdminCameraUnpossessedMessage{
+				EosId:      "0002a10186d9414496bf20d22d3860ba",
+				PlayerName: "Jon",
+			},
+		},
+		{
+			message: `Remote admin has warned player Jon. Message was "Stop. Now."`,
+			expected: PlayerWarnedMessage{
+				PlayerName: "Jon",
+				Message:    "Stop. Now.",
+			},
+		},
+		{
+			message: "Kicked player 1. [steamid=76561197989362395] creaman",
+			expected: PlayerKickedMessage{
+				MatchId:    1,
+				SteamId:    76561197989362395,
+				PlayerName: "creaman",
+			},
+		},
+		{
+			message: "Kicked player 3. [Online IDs= EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561197989362395] creaman",
+			expected: PlayerKickedMessage{
+				MatchId:    3,
+				SteamId:    76561197989362395,
+				EosId:      "0002a10186d9414496bf20d22d3860ba",
+				PlayerName: "creaman",
+			},
+		},
+		{
+			message: "Banned player 1. [steamid=76561197989362395] creaman for interval 1d",
+			expected: PlayerBannedMessage{
+				MatchId:    1,
+				SteamId:    76561197989362395,
+				PlayerName: "creaman",
+				Interval:   "1d",
+			},
+		},
+		{
+			message: "creaman (Steam ID: 76561197989362395) has created Squad 1 (Squad Name: ILU WAFFLES) on Insurgent Forces",
+			expected: SquadCreatedMessage{
+				CreatorName:    "creaman",
+				CreatorSteamId: 76561197989362395,
+				SquadId:        1,
+				SquadName:      "ILU WAFFLES",
+				Faction:        "Insurgent Forces",
+			},
+		},
+		{
+			message: "Jon (Online IDs: EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561197999957991) has created Squad 2 (Squad Name: INF (MIC)) on United States Army",
+			expected: SquadCreatedMessage{
+				CreatorName:    "Jon",
+				CreatorSteamId: 76561197999957991,
+				CreatorEosId:   "0002a10186d9414496bf20d22d3860ba",
+				SquadId:        2,
+				SquadName:      "INF (MIC)",
+				Faction:        "United States Army",
+			},
+		},
+		{
+			message:  "Jon team killed creaman",
+			expected: UnknownMessage{},
+		},
+		{
+			// Invalid Steam IDs are not accepted as chat.
+			message:  "[ChatAll] [SteamID:12] Jon : hi",
+			expected: UnknownMessage{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.message, func(t *testing.T) {
+			parsed := ParseServerMessage(test.message)
+			if parsed.GetRaw() != test.message {
+				t.Errorf("got raw %q, expected %q", parsed.GetRaw(), test.message)
+			}
+
+			if !reflect.DeepEqual(withoutRaw(parsed), test.expected) {
+				t.Errorf("got %#v, expected %#v", parsed, test.expected)
+			}
+		})
+	}
+}
+
+func TestServerMessageParserRegister(t *testing.T) {
+	parser := NewServerMessageParser()
+	message := "Jon team killed creaman"
+
+	if _, unknown := parser.Parse(message).(UnknownMessage); !unknown {
+		t.Fatalf("expected no default team kill pattern")
+	}
+
+	parser.Register(TeamKillPattern(regexp.MustCompile(`^(.+) team killed (.+)$`)))
+	expected := TeamKillMessage{serverMessageBase{message}, "Jon", "creaman"}
+	if parsed := parser.Parse(message); parsed != expected {
+		t.Errorf("got %#v, expected %#v", parsed, expected)
+	}
+
+	// Registering on a parser does not affect the default parser.
+	if _, unknown := ParseServerMessage(message).(UnknownMessage); !unknown {
+		t.Errorf("expected the default parser to be unchanged")
+	}
+}
+
+func TestParseSquadCreatedMessage(t *testing.T) {
+	if _, ok := ParseSquadCreatedMessage("[ChatAll] [SteamID:76561197989362395] creaman : hi"); ok {
+		t.Error("expected chat not to be parsed as squad creation")
+	}
+
+	message, ok := ParseSquadCreatedMessage("Jon (Steam ID: 76561197999957991) has created Squad 1 (Squad Name: TESTICLES) on United States Army")
+	if !ok || message.SquadName != "TESTICLES" || message.CreatorSteamId != 76561197999957991 {
+		t.Errorf("got %+v, %t", message, ok)
+	}
+}
+
+// withoutRaw returns the message with its raw text cleared.
+func withoutRaw(message ServerMessage) ServerMessage {
+	switch m := message.(type) {
+	case UnknownMessage:
+		m.serverMessageBase = serverMessageBase{}
+		return m
+	case ChatMessage:
+		m.serverMessageBase = serverMessageBase{}
+		return m
+	case AdminCameraPossessedMessage:
+		m.serverMessageBase = serverMessageBase{}
+		return m
+	case AdminCameraUnpossessedMessage:
+		m.serverMessageBase = serverMessageBase{}
+		return m
+	case PlayerWarnedMessage:
+		m.serverMessageBase = serverMessageBase{}
+		return m
+	case PlayerKickedMessage:
+		m.serverMessageBase = serverMessageBase{}
+		return m
+	case PlayerBannedMessage:
+		m.serverMessageBase = serverMessageBase{}
+		return m
+	case SquadCreatedMessage:
+		m.serverMessageBase = serverMessageBase{}
+		return m
+	case TeamKillMessage:
+		m.serverMessageBase = serverMessageBase{}
+		return m
+	default:
+		return message
+	}
+}
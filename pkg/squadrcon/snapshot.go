@@ -0,0 +1,176 @@
+package squadrcon
+
+import (
+	"fmt"
+	"sort"
+	"squad-rcon-go/pkg/rcon"
+	"time"
+)
+
+// Snapshot contains the teams, squads and players of the server at a point in time.
+type Snapshot struct {
+	Time time.Time
+
+	// Teams ordered by index.
+	Teams []SnapshotTeam
+
+	// Warnings contains inconsistencies found while combining the player and squad lists. These
+	// usually occur when the roster changes between ListPlayers and ListSquads.
+	Warnings []string
+}
+
+type SnapshotTeam struct {
+	Team
+
+	// Squads ordered by ID.
+	Squads []SnapshotSquad
+
+	// Unassigned contains the players of the team that are not part of a squad.
+	Unassigned []ActivePlayer
+}
+
+type SnapshotSquad struct {
+	Squad
+
+	// Leader is nil if no member is the squad leader.
+	Leader *ActivePlayer
+
+	Members []ActivePlayer
+}
+
+// TakeSnapshot executes ListPlayers and ListSquads back-to-back and combines the results.
+func TakeSnapshot(rcon rcon.Rcon) (Snapshot, error) {
+	players, err := ListPlayers(rcon)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to list players: %w", err)
+	}
+
+	squads, err := ListSquads(rcon)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to list squads: %w", err)
+	}
+
+	return NewSnapshot(players, squads, time.Now()), nil
+}
+
+// NewSnapshot combines the player and squad lists into teams, squads and players.
+// Players whose team or squad is missing from the squad list are added to a placeholder team or
+// squad, which is reported in the warnings.
+func NewSnapshot(players PlayerList, squads SquadList, at time.Time) Snapshot {
+	snapshot := Snapshot{
+		Time: at,
+	}
+	teams := make(map[int]*SnapshotTeam)
+	squadsByTeam := make(map[int]map[int]*SnapshotSquad)
+
+	getTeam := func(teamIndex int) *SnapshotTeam {
+		team, exists := teams[teamIndex]
+		if !exists {
+			team = &SnapshotTeam{
+				Team: Team{Index: teamIndex},
+			}
+			teams[teamIndex] = team
+			squadsByTeam[teamIndex] = make(map[int]*SnapshotSquad)
+		}
+
+		return team
+	}
+
+	for _, team := range squads.Teams {
+		getTeam(team.Index).Faction = team.Faction
+	}
+
+	for _, squad := range squads.Squads {
+		getTeam(squad.TeamIndex)
+		squadsByTeam[squad.TeamIndex][squad.Id] = &SnapshotSquad{
+			Squad: squad,
+		}
+	}
+
+	for _, player := range players.ActivePlayers {
+		if _, exists := teams[player.TeamIndex]; !exists {
+			snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf(
+				"team %d of player %s is not in the squad list",
+				player.TeamIndex,
+				player.Name,
+			))
+		}
+		team := getTeam(player.TeamIndex)
+
+		if player.SquadIndex == 0 {
+			team.Unassigned = append(team.Unassigned, player)
+			continue
+		}
+
+		squad, exists := squadsByTeam[player.TeamIndex][player.SquadIndex]
+		if !exists {
+			snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf(
+				"squad %d of team %d, of player %s, is not in the squad list",
+				player.SquadIndex,
+				player.TeamIndex,
+				player.Name,
+			))
+			squad = &SnapshotSquad{
+				Squad: Squad{
+					Id:        player.SquadIndex,
+					TeamIndex: player.TeamIndex,
+				},
+			}
+			squadsByTeam[player.TeamIndex][player.SquadIndex] = squad
+		}
+
+		squad.Members = append(squad.Members, player)
+		if player.IsSquadLead {
+			if squad.Leader != nil {
+				snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf(
+					"squad %d of team %d has multiple leaders: %s and %s",
+					squad.Id,
+					squad.TeamIndex,
+					squad.Leader.Name,
+					player.Name,
+				))
+			}
+			leader := player
+			squad.Leader = &leader
+		}
+	}
+
+	for teamIndex, team := range teams {
+		for _, squad := range squadsByTeam[teamIndex] {
+			if squad.Size != len(squad.Members) {
+				snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf(
+					"squad %d of team %d has size %d but %d members were found",
+					squad.Id,
+					squad.TeamIndex,
+					squad.Size,
+					len(squad.Members),
+				))
+			}
+
+			if squad.Leader == nil && len(squad.Members) != 0 {
+				snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf(
+					"squad %d of team %d has no leader",
+					squad.Id,
+					squad.TeamIndex,
+				))
+			}
+
+			team.Squads = append(team.Squads, *squad)
+		}
+
+		sort.Slice(team.Squads, func(i, j int) bool {
+			return team.Squads[i].Id < team.Squads[j].Id
+		})
+
+		snapshot.Teams = append(snapshot.Teams, *team)
+	}
+
+	sort.Slice(snapshot.Teams, func(i, j int) bool {
+		return snapshot.Teams[i].Index < snapshot.Teams[j].Index
+	})
+
+	// Iterating maps results in a random order, keep the warnings deterministic.
+	sort.Strings(snapshot.Warnings)
+
+	return snapshot
+}
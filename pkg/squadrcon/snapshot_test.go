@@ -0,0 +1,121 @@
+package squadrcon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+
+	jon := ActivePlayer{MatchId: 0, SteamId: 76561197999957991, Name: "Jon", TeamIndex: 1, SquadIndex: 1, IsSquadLead: true}
+	creaman := ActivePlayer{MatchId: 1, SteamId: 76561197989362395, Name: "creaman", TeamIndex: 1, SquadIndex: 1}
+	bob := ActivePlayer{MatchId: 2, EosId: "0002b2c3d4e5f60718293a4b5c6d7e8f", Name: "Bob", TeamIndex: 1}
+	ivan := ActivePlayer{MatchId: 3, SteamId: 76561198000000001, Name: "Ivan", TeamIndex: 2, SquadIndex: 2}
+	stray := ActivePlayer{MatchId: 4, SteamId: 76561198000000002, Name: "stray", TeamIndex: 3}
+
+	inf := Squad{Id: 1, TeamIndex: 1, Name: "INF", Size: 2, CreatorName: "Jon", CreatorSteamId: jon.SteamId}
+	empty := Squad{Id: 3, TeamIndex: 2, Name: "EMPTY", Size: 1, CreatorName: "Ivan", CreatorSteamId: ivan.SteamId}
+
+	snapshot := NewSnapshot(
+		PlayerList{ActivePlayers: []ActivePlayer{ivan, creaman, jon, bob, stray}},
+		SquadList{
+			Teams:  []Team{{Index: 2, Faction: "Russian Ground Forces"}, {Index: 1, Faction: "United States Army"}},
+			Squads: []Squad{empty, inf},
+		},
+		at,
+	)
+
+	expected := Snapshot{
+		Time: at,
+		Teams: []SnapshotTeam{
+			{
+				Team:       Team{Index: 1, Faction: "United States Army"},
+				Squads:     []SnapshotSquad{{Squad: inf, Leader: &jon, Members: []ActivePlayer{creaman, jon}}},
+				Unassigned: []ActivePlayer{bob},
+			},
+			{
+				Team: Team{Index: 2, Faction: "Russian Ground Forces"},
+				Squads: []SnapshotSquad{
+					// Placeholder for the squad of Ivan, which is missing from the squad list.
+					{Squad: Squad{Id: 2, TeamIndex: 2}, Members: []ActivePlayer{ivan}},
+					{Squad: empty},
+				},
+			},
+			{
+				// Placeholder for the team of stray, which is missing from the squad list.
+				Team:       Team{Index: 3},
+				Unassigned: []ActivePlayer{stray},
+			},
+		},
+		Warnings: []string{
+			"squad 2 of team 2 has no leader",
+			"squad 2 of team 2 has size 0 but 1 members were found",
+			"squad 2 of team 2, of player Ivan, is not in the squad list",
+			"squad 3 of team 2 has size 1 but 0 members were found",
+			"team 3 of player stray is not in the squad list",
+		},
+	}
+
+	if !reflect.DeepEqual(snapshot, expected) {
+		t.Errorf("got %+v, expected %+v", snapshot, expected)
+	}
+}
+
+func TestNewSnapshotMultipleLeaders(t *testing.T) {
+	jon := ActivePlayer{MatchId: 0, Name: "Jon", TeamIndex: 1, SquadIndex: 1, IsSquadLead: true}
+	creaman := ActivePlayer{MatchId: 1, Name: "creaman", TeamIndex: 1, SquadIndex: 1, IsSquadLead: true}
+
+	snapshot := NewSnapshot(
+		PlayerList{ActivePlayers: []ActivePlayer{jon, creaman}},
+		SquadList{
+			Teams:  []Team{{Index: 1, Faction: "United States Army"}, {Index: 2, Faction: "Russian Ground Forces"}},
+			Squads: []Squad{{Id: 1, TeamIndex: 1, Name: "INF", Size: 2}},
+		},
+		time.Unix(1700000000, 0),
+	)
+
+	if len(snapshot.Teams) != 2 || len(snapshot.Teams[1].Squads) != 0 {
+		t.Fatalf("got %+v, expected two teams and no squads on team 2", snapshot.Teams)
+	}
+	if leader := snapshot.Teams[0].Squads[0].Leader; leader == nil || leader.Name != "creaman" {
+		t.Errorf("got leader %+v, expected the last leader listed", leader)
+	}
+	expected := []string{"squad 1 of team 1 has multiple leaders: Jon and creaman"}
+	if !reflect.DeepEqual(snapshot.Warnings, expected) {
+		t.Errorf("got %q, expected %q", snapshot.Warnings, expected)
+	}
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	rcon := newFakeRcon(map[string]string{
+		"ListPlayers": "----- Active Players -----\n" +
+			"ID: 0 | SteamID: 76561197999957991 | Name: Jon | Team ID: 1 | Squad ID: 1 | Is Leader: True | Role: USA_SL_01\n" +
+			"ID: 1 | SteamID: 76561197989362395 | Name: creaman | Team ID: 2 | Squad ID: N/A | Is Leader: False | Role: RGF_Rifleman_01\n" +
+			"----- Recently Disconnected Players [Max of 15] -----\n",
+		"ListSquads": "----- Active Squads -----\n" +
+			"Team ID: 1 (United States Army)\n" +
+			"ID: 1 | Name: INF | Size: 1 | Locked: False | Creator Name: Jon | Creator Steam ID: 76561197999957991\n" +
+			"Team ID: 2 (Russian Ground Forces)\n",
+	})
+
+	before := time.Now()
+	snapshot, err := TakeSnapshot(rcon)
+	if err != nil {
+		t.Fatalf("could not take snapshot: %v", err)
+	}
+
+	if first, second := rcon.next(t), rcon.next(t); first != "ListPlayers" || second != "ListSquads" {
+		t.Errorf("got %s and %s, expected ListPlayers followed by ListSquads", first, second)
+	}
+	if snapshot.Time.Before(before) || len(snapshot.Warnings) != 0 || len(snapshot.Teams) != 2 {
+		t.Fatalf("got %+v, expected a consistent snapshot of two teams", snapshot)
+	}
+	if squads := snapshot.Teams[0].Squads; len(squads) != 1 || squads[0].Leader == nil || squads[0].Leader.Name != "Jon" {
+		t.Errorf("got %+v, expected squad 1 led by Jon", squads)
+	}
+	if unassigned := snapshot.Teams[1].Unassigned; len(unassigned) != 1 || unassigned[0].Name != "creaman" {
+		t.Errorf("got %+v, expected creaman to be unassigned", unassigned)
+	}
+}
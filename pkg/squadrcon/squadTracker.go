@@ -0,0 +1,392 @@
+package squadrcon
+
+import (
+	"sort"
+	"squad-rcon-go/pkg/rcon"
+	"sync"
+	"time"
+)
+
+// SquadEvent is implemented by all events emitted by the SquadTracker.
+type SquadEvent interface {
+	// GetSquad returns the squad as seen when the event was detected. For SquadDisbandedEvent,
+	// this is the last known state of the squad.
+	GetSquad() SnapshotSquad
+
+	GetTeam() Team
+
+	GetTime() time.Time
+}
+
+type squadEventBase struct {
+	Time  time.Time
+	Team  Team
+	Squad SnapshotSquad
+}
+
+func (e squadEventBase) GetSquad() SnapshotSquad {
+	return e.Squad
+}
+
+func (e squadEventBase) GetTeam() Team {
+	return e.Team
+}
+
+func (e squadEventBase) GetTime() time.Time {
+	return e.Time
+}
+
+type SquadCreatedEvent struct {
+	squadEventBase
+}
+
+type SquadDisbandedEvent struct {
+	squadEventBase
+}
+
+type SquadRenamedEvent struct {
+	squadEventBase
+	PreviousName string
+}
+
+type SquadLockedEvent struct {
+	squadEventBase
+}
+
+type SquadUnlockedEvent struct {
+	squadEventBase
+}
+
+// SquadLeaderChangedEvent is emitted when the squad leader changes. The new leader is available
+// as Squad.Leader and can be nil, e.g. when the leader left the server.
+type SquadLeaderChangedEvent struct {
+	squadEventBase
+	PreviousLeader *ActivePlayer
+}
+
+type SquadSizeChangedEvent struct {
+	squadEventBase
+	PreviousSize int
+}
+
+const (
+	defaultSquadPollInterval = 5 * time.Second
+)
+
+type SquadTrackerSettings struct {
+	// Interval between ListPlayers and ListSquads polls. Defaults to 5 seconds.
+	Interval time.Duration
+
+	// EventBus receives all events on the squad topic. Optional.
+	EventBus *EventBus
+
+	// OnError is called when polling fails. Optional.
+	OnError func(err error)
+}
+
+// SquadTracker polls the snapshot of the server and emits events for squads that changed between
+// polls. The first successful poll establishes the initial squads and does not emit events.
+//
+// Squad creation is detected sooner when the server's squad creation messages are passed to
+// HandleServerMessage.
+type SquadTracker struct {
+	rcon        rcon.Rcon
+	settings    SquadTrackerSettings
+	subscribers subscribers[SquadEvent]
+	done        chan struct{}
+	stopOnce    sync.Once
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// False until the first update.
+	initialized bool
+
+	// The current squads.
+	squads map[squadKey]SnapshotSquad
+
+	// The teams of the last snapshot, keyed by index.
+	teams map[int]Team
+
+	// The team index of each player in the last snapshot, keyed by player ID.
+	playerTeams map[string]int
+}
+
+// squadKey identifies a squad across polls. Squad IDs are reused after a squad is disbanded, the
+// creator distinguishes consecutive squads with the same ID.
+type squadKey struct {
+	teamIndex int
+	squadId   int
+	creator   string
+}
+
+// NewSquadTracker creates a SquadTracker and starts polling. Close stops polling.
+func NewSquadTracker(rcon rcon.Rcon, settings SquadTrackerSettings) *SquadTracker {
+	if settings.Interval <= 0 {
+		settings.Interval = defaultSquadPollInterval
+	}
+
+	t := &SquadTracker{
+		rcon:        rcon,
+		settings:    settings,
+		done:        make(chan struct{}),
+		squads:      make(map[squadKey]SnapshotSquad),
+		teams:       make(map[int]Team),
+		playerTeams: make(map[string]int),
+	}
+
+	go t.run()
+
+	return t
+}
+
+// Subscribe returns a subscription that receives all subsequent events. Events are dropped when
+// more than bufferSize events are pending.
+func (t *SquadTracker) Subscribe(bufferSize int) *Subscription[SquadEvent] {
+	return t.subscribers.subscribe(bufferSize, OverflowDropNewest)
+}
+
+// Squads returns the current squads ordered by team and ID.
+func (t *SquadTracker) Squads() []SnapshotSquad {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	squads := make([]SnapshotSquad, 0, len(t.squads))
+	for _, squad := range t.squads {
+		squads = append(squads, squad)
+	}
+
+	sort.Slice(squads, func(i, j int) bool {
+		if squads[i].TeamIndex != squads[j].TeamIndex {
+			return squads[i].TeamIndex < squads[j].TeamIndex
+		}
+		return squads[i].Id < squads[j].Id
+	})
+
+	return squads
+}
+
+// Close stops polling and closes all subscriptions.
+func (t *SquadTracker) Close() {
+	t.stopOnce.Do(func() {
+		close(t.done)
+		t.subscribers.close()
+	})
+}
+
+func (t *SquadTracker) run() {
+	ticker := time.NewTicker(t.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		t.poll()
+
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *SquadTracker) poll() {
+	snapshot, err := TakeSnapshot(t.rcon)
+	if err != nil {
+		if t.settings.OnError != nil {
+			t.settings.OnError(err)
+		}
+		return
+	}
+
+	t.Update(snapshot)
+}
+
+// HandleServerMessage emits SquadCreatedEvent for squad creation messages sent by the server,
+// without waiting for the next poll. Returns false if the message is not a squad creation message
+// or the team of the creator is not yet known.
+func (t *SquadTracker) HandleServerMessage(message string, at time.Time) bool {
+	created, ok := ParseSquadCreatedMessage(message)
+	if !ok {
+		return false
+	}
+
+	event, ok := t.applyCreated(created, at)
+	if event != nil {
+		publishAll(t.settings.EventBus, TopicSquad, []SquadEvent{event})
+	}
+
+	return ok
+}
+
+// applyCreated adds the created squad. Returns a nil event if the squad is already known.
+func (t *SquadTracker) applyCreated(created SquadCreatedMessage, at time.Time) (SquadEvent, bool) {
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// The message contains no match ID, creators without IDs cannot be told apart from others.
+	if !t.initialized || (created.CreatorSteamId == 0 && created.CreatorEosId == "") {
+		return nil, false
+	}
+
+	creator := ActivePlayer{
+		SteamId:     created.CreatorSteamId,
+		EosId:       created.CreatorEosId,
+		Name:        created.CreatorName,
+		SquadIndex:  created.SquadId,
+		IsSquadLead: true,
+	}
+	teamIndex, exists := t.playerTeams[PlayerKey(creator)]
+	if !exists {
+		return nil, false
+	}
+	creator.TeamIndex = teamIndex
+
+	squad := SnapshotSquad{
+		Squad: Squad{
+			Id:             created.SquadId,
+			TeamIndex:      teamIndex,
+			Name:           created.SquadName,
+			Size:           1,
+			CreatorName:    created.CreatorName,
+			CreatorSteamId: created.CreatorSteamId,
+			CreatorEosId:   created.CreatorEosId,
+		},
+		Leader:  &creator,
+		Members: []ActivePlayer{creator},
+	}
+
+	key := newSquadKey(squad.Squad)
+	if _, exists := t.squads[key]; exists {
+		return nil, true
+	}
+
+	t.squads[key] = squad
+	event := SquadCreatedEvent{squadEventBase{at, t.teams[teamIndex], squad}}
+	t.subscribers.publish(event)
+
+	return event, true
+}
+
+// Update applies a snapshot and emits the resulting events. It is called for every poll but can
+// also be used to apply snapshots obtained elsewhere.
+func (t *SquadTracker) Update(snapshot Snapshot) {
+	events := t.apply(snapshot)
+
+	// Published unlocked so that synchronous handlers can query the tracker.
+	publishAll(t.settings.EventBus, TopicSquad, events)
+}
+
+// apply updates the state and publishes the resulting events to the subscriptions.
+func (t *SquadTracker) apply(snapshot Snapshot) []SquadEvent {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	current := make(map[squadKey]SnapshotSquad)
+	t.teams = make(map[int]Team, len(snapshot.Teams))
+	t.playerTeams = make(map[string]int)
+
+	for _, team := range snapshot.Teams {
+		t.teams[team.Index] = team.Team
+
+		for _, squad := range team.Squads {
+			for _, member := range squad.Members {
+				t.playerTeams[PlayerKey(member)] = team.Index
+			}
+
+			if squad.CreatorSteamId == 0 && squad.CreatorEosId == "" {
+				// Placeholder for a squad missing from ListSquads, see NewSnapshot.
+				continue
+			}
+			current[newSquadKey(squad.Squad)] = squad
+		}
+
+		for _, player := range team.Unassigned {
+			t.playerTeams[PlayerKey(player)] = team.Index
+		}
+	}
+
+	var events []SquadEvent
+	if t.initialized {
+		events = t.diff(current, snapshot.Time)
+	}
+
+	t.squads = current
+	t.initialized = true
+
+	// Publishing does not block, doing so while locked keeps concurrent updates in order.
+	t.subscribers.publish(events...)
+
+	return events
+}
+
+func (t *SquadTracker) diff(current map[squadKey]SnapshotSquad, at time.Time) []SquadEvent {
+	var events []SquadEvent
+
+	for key, previous := range t.squads {
+		if _, exists := current[key]; !exists {
+			events = append(events, SquadDisbandedEvent{
+				squadEventBase{at, t.teams[key.teamIndex], previous},
+			})
+		}
+	}
+
+	for key, squad := range current {
+		base := squadEventBase{at, t.teams[key.teamIndex], squad}
+		previous, exists := t.squads[key]
+
+		if !exists {
+			events = append(events, SquadCreatedEvent{base})
+			continue
+		}
+
+		if previous.Name != squad.Name {
+			events = append(events, SquadRenamedEvent{base, previous.Name})
+		}
+
+		if !previous.Locked && squad.Locked {
+			events = append(events, SquadLockedEvent{base})
+		} else if previous.Locked && !squad.Locked {
+			events = append(events, SquadUnlockedEvent{base})
+		}
+
+		if !sameLeader(previous.Leader, squad.Leader) {
+			events = append(events, SquadLeaderChangedEvent{base, previous.Leader})
+		}
+
+		if previous.Size != squad.Size {
+			events = append(events, SquadSizeChangedEvent{base, previous.Size})
+		}
+	}
+
+	// Iterating maps results in a random order, keep the events ordered by squad. Disbanded
+	// squads precede squads that reuse their ID.
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i].GetSquad(), events[j].GetSquad()
+		if a.TeamIndex != b.TeamIndex {
+			return a.TeamIndex < b.TeamIndex
+		}
+		return a.Id < b.Id
+	})
+
+	return events
+}
+
+func newSquadKey(squad Squad) squadKey {
+	return squadKey{
+		teamIndex: squad.TeamIndex,
+		squadId:   squad.Id,
+		creator: PlayerKey(ActivePlayer{
+			SteamId: squad.CreatorSteamId,
+			EosId:   squad.CreatorEosId,
+		}),
+	}
+}
+
+func sameLeader(a *ActivePlayer, b *ActivePlayer) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return PlayerKey(*a) == PlayerKey(*b)
+}
@@ -0,0 +1,213 @@
+package squadrcon
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSquadTracker() *SquadTracker {
+	return &SquadTracker{
+		squads:      make(map[squadKey]SnapshotSquad),
+		teams:       make(map[int]Team),
+		playerTeams: make(map[string]int),
+	}
+}
+
+func TestSquadTrackerIgnoresPlaceholderSquads(t *testing.T) {
+	tracker := newTestSquadTracker()
+	start := time.Unix(1700000000, 0)
+
+	jon := ActivePlayer{MatchId: 0, SteamId: 76561197999957991, Name: "Jon", TeamIndex: 1, SquadIndex: 1, IsSquadLead: true}
+	squads := SquadList{
+		Teams:  []Team{{Index: 1, Faction: "III Corps"}, {Index: 2, Faction: "Local Insurgent Cell"}},
+		Squads: []Squad{{Id: 1, TeamIndex: 1, Name: "INF", Size: 1, CreatorName: "Jon", CreatorSteamId: 76561197999957991}},
+	}
+	tracker.apply(NewSnapshot(PlayerList{ActivePlayers: []ActivePlayer{jon}}, squads, start))
+
+	// Squad 2 is not in the squad list yet, NewSnapshot adds a placeholder for it.
+	creaman := ActivePlayer{MatchId: 1, SteamId: 76561197989362395, Name: "creaman", TeamIndex: 1, SquadIndex: 2, IsSquadLead: true}
+	snapshot := NewSnapshot(PlayerList{ActivePlayers: []ActivePlayer{jon, creaman}}, squads, start.Add(time.Second))
+	if len(snapshot.Teams[0].Squads) != 2 {
+		t.Fatalf("expected the snapshot to contain a placeholder squad, got %+v", snapshot.Teams[0].Squads)
+	}
+
+	if events := tracker.apply(snapshot); len(events) != 0 {
+		t.Errorf("got %+v, expected no events for the placeholder squad", events)
+	}
+	if squads := tracker.Squads(); len(squads) != 1 || squads[0].Id != 1 {
+		t.Errorf("got %+v, expected only squad 1 to be tracked", squads)
+	}
+}
+
+// describeSquadEvents returns the type, team, squad and previous value of each event.
+func describeSquadEvents(events []SquadEvent) []string {
+	var descriptions []string
+	for _, event := range events {
+		squad := event.GetSquad()
+		description := fmt.Sprintf("%T %d/%d", event, squad.TeamIndex, squad.Id)
+
+		switch event := event.(type) {
+		case SquadRenamedEvent:
+			description += " from " + event.PreviousName
+		case SquadLeaderChangedEvent:
+			if event.PreviousLeader != nil {
+				description += " from " + event.PreviousLeader.Name
+			}
+		case SquadSizeChangedEvent:
+			description += fmt.Sprintf(" from %d", event.PreviousSize)
+		}
+
+		descriptions = append(descriptions, strings.TrimPrefix(description, "squadrcon."))
+	}
+	return descriptions
+}
+
+func TestSquadTrackerDiff(t *testing.T) {
+	teams := []Team{{Index: 1, Faction: "United States Army"}, {Index: 2, Faction: "Russian Ground Forces"}}
+	jon := ActivePlayer{MatchId: 0, SteamId: 76561197999957991, Name: "Jon", TeamIndex: 1}
+	creaman := ActivePlayer{MatchId: 1, SteamId: 76561197989362395, Name: "creaman", TeamIndex: 1}
+	bob := ActivePlayer{MatchId: 2, EosId: "0002b2c3d4e5f60718293a4b5c6d7e8f", Name: "Bob", TeamIndex: 1}
+	ivan := ActivePlayer{MatchId: 3, SteamId: 76561198000000001, Name: "Ivan", TeamIndex: 2}
+
+	// member returns the player in the squad.
+	member := func(player ActivePlayer, squadId int, leader bool) ActivePlayer {
+		player.SquadIndex = squadId
+		player.IsSquadLead = leader
+		return player
+	}
+	squad := func(teamIndex int, id int, name string, size int, locked bool, creator ActivePlayer) Squad {
+		return Squad{
+			Id:             id,
+			TeamIndex:      teamIndex,
+			Name:           name,
+			Size:           size,
+			Locked:         locked,
+			CreatorName:    creator.Name,
+			CreatorSteamId: creator.SteamId,
+			CreatorEosId:   creator.EosId,
+		}
+	}
+
+	steps := []struct {
+		name     string
+		players  []ActivePlayer
+		squads   []Squad
+		expected []string
+	}{
+		{
+			name:    "initial squads",
+			players: []ActivePlayer{member(jon, 1, true), member(creaman, 1, false), member(bob, 2, true), ivan},
+			squads:  []Squad{squad(1, 1, "INF", 2, false, jon), squad(1, 2, "LOGI", 1, false, bob)},
+		},
+		{
+			name:     "renamed and locked",
+			players:  []ActivePlayer{member(jon, 1, true), member(creaman, 1, false), member(bob, 2, true), ivan},
+			squads:   []Squad{squad(1, 1, "ARMOR", 2, false, jon), squad(1, 2, "LOGI", 1, true, bob)},
+			expected: []string{"SquadRenamedEvent 1/1 from INF", "SquadLockedEvent 1/2"},
+		},
+		{
+			name:     "leader changed",
+			players:  []ActivePlayer{member(jon, 1, false), member(creaman, 1, true), member(bob, 2, true), ivan},
+			squads:   []Squad{squad(1, 1, "ARMOR", 2, false, jon), squad(1, 2, "LOGI", 1, true, bob)},
+			expected: []string{"SquadLeaderChangedEvent 1/1 from Jon"},
+		},
+		{
+			name:     "size changed and unlocked",
+			players:  []ActivePlayer{jon, member(creaman, 1, true), member(bob, 2, true), ivan},
+			squads:   []Squad{squad(1, 1, "ARMOR", 1, false, jon), squad(1, 2, "LOGI", 1, false, bob)},
+			expected: []string{"SquadSizeChangedEvent 1/1 from 2", "SquadUnlockedEvent 1/2"},
+		},
+		{
+			name:     "disbanded and created",
+			players:  []ActivePlayer{jon, member(creaman, 1, true), bob, member(ivan, 1, true)},
+			squads:   []Squad{squad(1, 1, "ARMOR", 1, false, jon), squad(2, 1, "CMD", 1, false, ivan)},
+			expected: []string{"SquadDisbandedEvent 1/2", "SquadCreatedEvent 2/1"},
+		},
+		{
+			name:     "squad ID reused",
+			players:  []ActivePlayer{member(jon, 1, true), creaman, bob, member(ivan, 1, true)},
+			squads:   []Squad{squad(1, 1, "ARMOR", 1, false, creaman), squad(2, 1, "CMD", 1, false, ivan)},
+			expected: []string{"SquadDisbandedEvent 1/1", "SquadCreatedEvent 1/1"},
+		},
+	}
+
+	tracker := newTestSquadTracker()
+	subscription := tracker.Subscribe(16)
+	start := time.Unix(1700000000, 0)
+
+	for i, step := range steps {
+		snapshot := NewSnapshot(PlayerList{ActivePlayers: step.players}, SquadList{Teams: teams, Squads: step.squads}, start.Add(time.Duration(i)*time.Second))
+		events := tracker.apply(snapshot)
+
+		if descriptions := describeSquadEvents(events); !reflect.DeepEqual(descriptions, step.expected) {
+			t.Errorf("%s: got %q, expected %q", step.name, descriptions, step.expected)
+		}
+
+		for _, event := range events {
+			if !event.GetTime().Equal(snapshot.Time) {
+				t.Errorf("%s: got time %s, expected the time of the snapshot", step.name, event.GetTime())
+			}
+			if received := <-subscription.Events(); !reflect.DeepEqual(received, event) {
+				t.Errorf("%s: subscription got %+v, expected %+v", step.name, received, event)
+			}
+		}
+	}
+
+	if squads := tracker.Squads(); len(squads) != 2 || squads[0].CreatorName != "creaman" || squads[1].TeamIndex != 2 {
+		t.Errorf("got %+v, expected the squads of the last snapshot", squads)
+	}
+}
+
+func TestSquadTrackerHandleServerMessage(t *testing.T) {
+	tracker := newTestSquadTracker()
+	subscription := tracker.Subscribe(16)
+	start := time.Unix(1700000000, 0)
+
+	created := "Jon (Online IDs: EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561197999957991) has created Squad 1 (Squad Name: INF) on United States Army"
+	if tracker.HandleServerMessage(created, start) {
+		t.Error("expected messages before the first snapshot to be ignored")
+	}
+
+	teams := []Team{{Index: 1, Faction: "United States Army"}, {Index: 2, Faction: "Russian Ground Forces"}}
+	jon := ActivePlayer{MatchId: 0, SteamId: 76561197999957991, EosId: "0002a10186d9414496bf20d22d3860ba", Name: "Jon", TeamIndex: 1}
+	tracker.apply(NewSnapshot(PlayerList{ActivePlayers: []ActivePlayer{jon}}, SquadList{Teams: teams}, start))
+
+	if !tracker.HandleServerMessage(created, start.Add(time.Second)) {
+		t.Fatal("expected the squad creation to be handled")
+	}
+	select {
+	case event := <-subscription.Events():
+		if created, ok := event.(SquadCreatedEvent); !ok || created.Squad.Id != 1 || created.Squad.Leader == nil || created.Squad.Leader.Name != "Jon" || created.Team.Faction != "United States Army" {
+			t.Errorf("got %+v, expected squad 1 created by Jon", event)
+		}
+	default:
+		t.Fatal("expected a SquadCreatedEvent")
+	}
+
+	// Repeated messages and the next poll do not create the squad again.
+	if !tracker.HandleServerMessage(created, start.Add(2*time.Second)) {
+		t.Error("expected the repeated message to be handled")
+	}
+	jon.SquadIndex = 1
+	jon.IsSquadLead = true
+	squads := SquadList{Teams: teams, Squads: []Squad{{Id: 1, TeamIndex: 1, Name: "INF", Size: 1, CreatorName: "Jon", CreatorSteamId: jon.SteamId, CreatorEosId: jon.EosId}}}
+	if events := tracker.apply(NewSnapshot(PlayerList{ActivePlayers: []ActivePlayer{jon}}, squads, start.Add(3*time.Second))); len(events) != 0 {
+		t.Errorf("got %q, expected no events for the known squad", describeSquadEvents(events))
+	}
+	select {
+	case event := <-subscription.Events():
+		t.Errorf("got %+v, expected no further events", event)
+	default:
+	}
+
+	unknown := "creaman (Steam ID: 76561197989362395) has created Squad 2 (Squad Name: LOGI) on United States Army"
+	if tracker.HandleServerMessage(unknown, start) {
+		t.Error("expected creators missing from the last snapshot to be ignored")
+	}
+	if tracker.HandleServerMessage("[ChatAll] [SteamID:76561197999957991] Jon : hello", start) {
+		t.Error("expected other messages to be ignored")
+	}
+}
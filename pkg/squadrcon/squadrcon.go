@@ -2,34 +2,130 @@ package squadrcon
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
 	"squad-rcon-go/pkg/rcon"
+	"strings"
 	"time"
 )
 
 var (
-	ErrNotConnected = errors.New("no connection, use .Connect to create a connection")
+	ErrNotConnected       = errors.New("no connection, use .Connect to create a connection")
+	ErrUnsupportedCommand = errors.New("command is not supported by the server")
 )
 
 type SquadRcon struct {
 	rcon rcon.Rcon
+
+	broadcaster *Broadcaster
+
+	// Receives debug output. Optional.
+	logger *log.Logger
+
+	// Commands supported by the server, keyed by lowercase command name. Nil if the supported
+	// commands are unknown, in which case all commands are assumed to be supported.
+	commands map[string]Command
 }
 
 type Settings struct {
+	// Broadcast configures the queue used by Broadcast.
+	Broadcast BroadcasterSettings
+
 	DialTimeout time.Duration
 
+	// Logger receives debug output. Optional.
+	Logger *log.Logger
+
+	// Middleware wraps Execute of the underlying connection, see rcon.Settings.Middleware. It
+	// applies to all commands, including those sent by the typed command functions. Optional.
+	Middleware []rcon.Middleware
+
+	// OnDisconnect is called when the connection is lost, see rcon.Settings.OnDisconnect.
+	// Optional.
+	OnDisconnect func(err error)
+
+	// OnPacketReceived and OnPacketSent are called for every packet, see
+	// rcon.Settings.OnPacketReceived and rcon.Settings.OnPacketSent. Optional.
+	OnPacketReceived func(id int32, packetType int32, body string)
+	OnPacketSent     func(id int32, packetType int32, body string)
+
+	// EventBus receives the messages pushed by the server on the chat and notification topics.
+	// Messages are published from the goroutine that reads packets, synchronous handlers and
+	// subscriptions using OverflowBlock must therefore not call Execute. Optional.
+	EventBus *EventBus
+
+	// OnServerMessage is called for every message pushed by the server, parsed using
+	// ServerMessageParser. See rcon.Settings.OnServerMessage for restrictions. Optional.
+	OnServerMessage func(message ServerMessage)
+
 	// PacketIdStart contains the first packet ID that will be used. Change it when multiple rcon
 	// connections are used. E.g. SquadJS uses ID 1 and 2, so these IDs shouldn't be used to prevent
 	// conflicts.
 	PacketIdStart int32
 
+	// Recorder receives every packet sent and received, see rcon.Settings.Recorder. Optional.
+	Recorder *rcon.Recorder
+
+	// ServerMessageParser is used to parse pushed messages. Defaults to the parser used by
+	// ParseServerMessage.
+	ServerMessageParser *ServerMessageParser
+
+	// SkipCommandProbe disables running ListCommands on connect. When skipped, all commands are
+	// assumed to be supported.
+	SkipCommandProbe bool
+
 	WriteTimeout time.Duration
 }
 
-func Connect(address string, password string, settings Settings) (rcon.Rcon, error) {
-	rc, err := rcon.Connect(address, password, rcon.Settings{
+func Connect(address string, password string, settings Settings) (*SquadRcon, error) {
+	return connect(settings, func(rconSettings rcon.Settings) (rcon.Rcon, error) {
+		return rcon.Connect(address, password, rconSettings)
+	})
+}
+
+// ConnectConn authenticates on an established connection, e.g. a connection returned by
+// rcon.NewReplayConn. DialTimeout is not used.
+func ConnectConn(conn net.Conn, password string, settings Settings) (*SquadRcon, error) {
+	return connect(settings, func(rconSettings rcon.Settings) (rcon.Rcon, error) {
+		return rcon.ConnectConn(conn, password, rconSettings)
+	})
+}
+
+// connect wraps the connection established by connectRcon.
+func connect(settings Settings, connectRcon func(rconSettings rcon.Settings) (rcon.Rcon, error)) (*SquadRcon, error) {
+	var onServerMessage func(message string)
+	if settings.OnServerMessage != nil || settings.EventBus != nil {
+		parser := settings.ServerMessageParser
+		if parser == nil {
+			parser = defaultServerMessageParser
+		}
+
+		onServerMessage = func(message string) {
+			parsed := parser.Parse(message)
+
+			if settings.EventBus != nil {
+				settings.EventBus.publishServerMessage(parsed)
+			}
+
+			if settings.OnServerMessage != nil {
+				settings.OnServerMessage(parsed)
+			}
+		}
+	}
+
+	rc, err := connectRcon(rcon.Settings{
 		ConfirmationCommand: "ShowCurrentMap",
 		DialTimeout:         settings.DialTimeout,
+		Logger:              settings.Logger,
+		Middleware:          settings.Middleware,
+		OnDisconnect:        settings.OnDisconnect,
+		OnPacketReceived:    settings.OnPacketReceived,
+		OnPacketSent:        settings.OnPacketSent,
+		OnServerMessage:     onServerMessage,
 		PacketIdStart:       settings.PacketIdStart,
+		Recorder:            settings.Recorder,
 		WriteTimeout:        settings.WriteTimeout,
 	})
 	if err != nil {
@@ -37,16 +133,101 @@ func Connect(address string, password string, settings Settings) (rcon.Rcon, err
 	}
 
 	squadRcon := &SquadRcon{
-		rcon: rc,
+		rcon:   rc,
+		logger: settings.Logger,
 	}
 
+	if !settings.SkipCommandProbe {
+		squadRcon.probeCommands()
+	}
+
+	squadRcon.broadcaster = NewBroadcaster(squadRcon, settings.Broadcast)
+
 	return squadRcon, nil
 }
 
 func (r *SquadRcon) Close() error {
+	r.broadcaster.Close()
 	return r.rcon.Close()
 }
 
+// Broadcast queues a message to be shown to all players using AdminBroadcast.
+// See Broadcaster.Broadcast.
+func (r *SquadRcon) Broadcast(message string, priority BroadcastPriority) bool {
+	return r.broadcaster.Broadcast(message, priority)
+}
+
 func (r *SquadRcon) Execute(command string) (string, error) {
 	return r.rcon.Execute(command)
 }
+
+// Commands returns the commands supported by the server as determined when connecting.
+// Returns false if the supported commands are unknown.
+func (r *SquadRcon) Commands() ([]Command, bool) {
+	if r.commands == nil {
+		return nil, false
+	}
+
+	commands := make([]Command, 0, len(r.commands))
+	for _, command := range r.commands {
+		commands = append(commands, command)
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+
+	return commands, true
+}
+
+// Supports returns whether the server supports the given command for the permission level of the
+// connection. Always true if the supported commands are unknown.
+func (r *SquadRcon) Supports(command string) bool {
+	if r.commands == nil {
+		return true
+	}
+
+	_, exists := r.commands[strings.ToLower(command)]
+	return exists
+}
+
+// probeCommands determines the commands supported by the server. Failure to determine the
+// commands is not fatal, it merely disables the capability check. A partially parsed list is
+// discarded, as commands missing from it would be rejected without contacting the server.
+func (r *SquadRcon) probeCommands() {
+	commands, err := ListCommands(r.rcon)
+	if err != nil {
+		if r.logger != nil {
+			r.logger.Printf("Could not determine supported commands: %v", err)
+		}
+		return
+	}
+	if len(commands) == 0 {
+		return
+	}
+
+	r.commands = make(map[string]Command, len(commands))
+	for _, command := range commands {
+		r.commands[strings.ToLower(command.Name)] = command
+	}
+}
+
+// commandSupporter is implemented by connections that know which commands the server supports.
+type commandSupporter interface {
+	Supports(command string) bool
+}
+
+// execute executes the command with the given arguments. If the connection knows which commands
+// are supported, ErrUnsupportedCommand is returned for unsupported commands without contacting the
+// server.
+func execute(rc rcon.Rcon, command string, arguments ...string) (string, error) {
+	if supporter, ok := rc.(commandSupporter); ok && !supporter.Supports(command) {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCommand, command)
+	}
+
+	if len(arguments) == 0 {
+		return rc.Execute(command)
+	}
+
+	return rc.Execute(command + " " + strings.Join(arguments, " "))
+}
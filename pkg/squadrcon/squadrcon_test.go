@@ -0,0 +1,56 @@
+package squadrcon
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProbeCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		known    bool
+	}{
+		{"complete list", "ListCommands [ShowDetails]\nListPlayers (List player ids with associated player name and SteamId)\n", true},
+		{"partial list", "ListCommands [ShowDetails]\n<not a command>\nListPlayers\n", false},
+		{"empty list", "", false},
+		{"not a command list", "Error: not permitted", false},
+	}
+
+	for _, test := range tests {
+		r := &SquadRcon{rcon: newFakeRcon(map[string]string{"ListCommands 1": test.response})}
+		r.probeCommands()
+
+		if _, known := r.Commands(); known != test.known {
+			t.Errorf("%s: got known commands %v, expected %v", test.name, known, test.known)
+		}
+
+		// Unknown commands are assumed to be supported, known commands are checked.
+		if supported := r.Supports("AdminKick"); supported == test.known {
+			t.Errorf("%s: got AdminKick supported %v, expected %v", test.name, supported, !test.known)
+		}
+		if !r.Supports("listplayers") {
+			t.Errorf("%s: expected ListPlayers to be supported", test.name)
+		}
+	}
+}
+
+func TestExecuteUnsupportedCommand(t *testing.T) {
+	rcon := newFakeRcon(map[string]string{
+		"ListCommands 1":    "ListCommands [ShowDetails]\nAdminWarn <NameOrSteamId> <WarnReason>\n",
+		"AdminWarn 0 hello": `Remote admin has warned player Jon. Message was "hello"`,
+	})
+	r := &SquadRcon{rcon: rcon}
+	r.probeCommands()
+	rcon.next(t)
+
+	if err := AdminKick(r, "0", "reason"); !errors.Is(err, ErrUnsupportedCommand) {
+		t.Errorf("got %v, expected %v", err, ErrUnsupportedCommand)
+	}
+	if err := AdminWarn(r, "0", "hello"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if command := rcon.next(t); command != "AdminWarn 0 hello" {
+		t.Errorf("got %q, expected only the supported command to be executed", command)
+	}
+}
@@ -0,0 +1,203 @@
+package squadrcon
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy determines what happens when an event is published to a subscription whose
+// buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest drops the published event.
+	OverflowDropNewest OverflowPolicy = iota
+
+	// OverflowDropOldest drops the oldest buffered event to make room for the published event.
+	OverflowDropOldest
+
+	// OverflowBlock blocks the publisher until there is room in the buffer or the subscription
+	// is closed.
+	OverflowBlock
+)
+
+// Subscription receives events through a buffered channel.
+type Subscription[T any] struct {
+	events   chan T
+	overflow OverflowPolicy
+	dropped  atomic.Uint64
+
+	// Closed on Unsubscribe to release a publisher blocked by OverflowBlock.
+	done     chan struct{}
+	doneOnce sync.Once
+
+	// Lock held while sending on or closing events, so events is never sent on once closed.
+	sendLock sync.Mutex
+	closed   bool
+
+	unsubscribe func()
+}
+
+// Events returns the channel on which events are delivered. It is closed on Unsubscribe or when
+// the publisher is closed.
+func (s *Subscription[T]) Events() <-chan T {
+	return s.events
+}
+
+// Dropped returns the amount of events that were dropped because the buffer was full.
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe stops the delivery of events and closes the events channel.
+func (s *Subscription[T]) Unsubscribe() {
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+	s.unsubscribe()
+}
+
+// closeEvents releases a blocked publisher and closes the events channel.
+func (s *Subscription[T]) closeEvents() {
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+}
+
+// deliver sends the events according to the overflow policy, unless the subscription is closed.
+func (s *Subscription[T]) deliver(events ...T) {
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	for _, event := range events {
+		s.send(event)
+	}
+}
+
+func (s *Subscription[T]) send(event T) {
+	switch s.overflow {
+	case OverflowBlock:
+		select {
+		case s.events <- event:
+		case <-s.done:
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.events <- event:
+				return
+			default:
+			}
+
+			select {
+			case <-s.events:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.events <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// subscribers keeps track of subscriptions and delivers events to them.
+type subscribers[T any] struct {
+	// Lock to be used before accessing subscriptions.
+	lock sync.Mutex
+
+	// Nil once closed.
+	subscriptions map[*Subscription[T]]struct{}
+
+	closed bool
+}
+
+func (s *subscribers[T]) subscribe(bufferSize int, overflow OverflowPolicy) *Subscription[T] {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+
+	subscription := &Subscription[T]{
+		events:   make(chan T, bufferSize),
+		overflow: overflow,
+		done:     make(chan struct{}),
+	}
+	subscription.unsubscribe = func() {
+		s.lock.Lock()
+		delete(s.subscriptions, subscription)
+		s.lock.Unlock()
+
+		subscription.closeEvents()
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		subscription.closeEvents()
+		return subscription
+	}
+
+	if s.subscriptions == nil {
+		s.subscriptions = make(map[*Subscription[T]]struct{})
+	}
+	s.subscriptions[subscription] = struct{}{}
+
+	return subscription
+}
+
+func (s *subscribers[T]) publish(events ...T) {
+	s.publishFiltered(nil, events...)
+}
+
+// publishFiltered delivers the events to the subscriptions accepted by filter. A nil filter
+// accepts all subscriptions. Events are delivered without holding the lock, so that a blocking
+// subscription does not prevent others from subscribing, unsubscribing or closing.
+func (s *subscribers[T]) publishFiltered(filter func(subscription *Subscription[T]) bool, events ...T) {
+	for _, subscription := range s.filter(filter) {
+		subscription.deliver(events...)
+	}
+}
+
+// filter returns the subscriptions accepted by filter. A nil filter accepts all subscriptions.
+func (s *subscribers[T]) filter(filter func(subscription *Subscription[T]) bool) []*Subscription[T] {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var accepted []*Subscription[T]
+	for subscription := range s.subscriptions {
+		if filter == nil || filter(subscription) {
+			accepted = append(accepted, subscription)
+		}
+	}
+
+	return accepted
+}
+
+// close closes all subscriptions. Later subscriptions are closed immediately.
+func (s *subscribers[T]) close() {
+	s.lock.Lock()
+	subscriptions := s.subscriptions
+	s.subscriptions = nil
+	s.closed = true
+	s.lock.Unlock()
+
+	for subscription := range subscriptions {
+		subscription.closeEvents()
+	}
+}
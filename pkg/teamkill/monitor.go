@@ -0,0 +1,282 @@
+package teamkill
+
+import (
+	"fmt"
+	"squad-rcon-go/pkg/rcon"
+	"squad-rcon-go/pkg/squadlog"
+	"squad-rcon-go/pkg/squadrcon"
+	"sync"
+	"time"
+)
+
+// TopicTeamKill carries the Action payloads of a Monitor.
+const TopicTeamKill squadrcon.Topic = "teamkill"
+
+// TeamKill is the evidence of a single team kill.
+type TeamKill struct {
+	Time     time.Time
+	Attacker squadrcon.ActivePlayer
+	Victim   squadrcon.ActivePlayer
+	Weapon   string
+
+	// Raw contains the log line of the wound.
+	Raw string
+}
+
+type ActionKind int
+
+const (
+	ActionWarn ActionKind = iota
+	ActionKick
+)
+
+func (k ActionKind) String() string {
+	switch k {
+	case ActionWarn:
+		return "warn"
+	case ActionKick:
+		return "kick"
+	default:
+		return fmt.Sprintf("ActionKind(%d)", int(k))
+	}
+}
+
+// Action is a warning or kick taken in response to a team kill.
+type Action struct {
+	Time time.Time
+	Kind ActionKind
+
+	// Player is the attacker the action was taken against.
+	Player squadrcon.ActivePlayer
+
+	// Message contains the warning message or kick reason.
+	Message string
+
+	// TeamKill is the team kill that caused the action.
+	TeamKill TeamKill
+
+	// Count is the amount of team kills of the player within the window, including TeamKill.
+	Count int
+
+	// Err is set if the command failed.
+	Err error
+}
+
+const (
+	defaultKickThreshold = 3
+	defaultWindow        = 10 * time.Minute
+	defaultKickReason    = "Too many team kills"
+)
+
+type MonitorSettings struct {
+	// KickThreshold is the amount of team kills within Window after which the player is kicked
+	// rather than warned. Defaults to 3.
+	KickThreshold int
+
+	// Window is the period in which team kills count towards KickThreshold. Defaults to 10 minutes.
+	Window time.Duration
+
+	// WarnMessage returns the warning sent to the attacker. Optional, a default message naming the
+	// victim is used if nil.
+	WarnMessage func(teamKill TeamKill, count int) string
+
+	// KickReason is shown to kicked players. Defaults to "Too many team kills".
+	KickReason string
+
+	// DryRun records actions without executing the commands.
+	DryRun bool
+
+	// EventBus is used to receive game log and match events, and receives all actions on the team
+	// kill topic. Optional, events can be passed to HandleEvent instead.
+	EventBus *squadrcon.EventBus
+
+	// OnAction is called for every action. Optional.
+	OnAction func(action Action)
+}
+
+// Monitor counts the team kills of every player per match, warns players on each team kill and
+// kicks them once they reach the threshold.
+//
+// Team kills are detected from squadlog.PlayerWoundedEvent, which must be correlated with the
+// RCON roster to determine the teams. Uncorrelated wounds are ignored.
+type Monitor struct {
+	rcon         rcon.Rcon
+	settings     MonitorSettings
+	subscription *squadrcon.Subscription[squadrcon.Event]
+
+	// Lock to be used before accessing the fields below.
+	lock sync.Mutex
+
+	// The team kills of the current match within the window, keyed by squadrcon.PlayerKey of the
+	// attacker.
+	teamKills map[string][]TeamKill
+
+	// All actions taken, oldest first.
+	actions []Action
+}
+
+// NewMonitor creates a Monitor. If an event bus is configured, events are received from it until
+// Close is called.
+func NewMonitor(rcon rcon.Rcon, settings MonitorSettings) *Monitor {
+	if settings.KickThreshold <= 0 {
+		settings.KickThreshold = defaultKickThreshold
+	}
+
+	if settings.Window <= 0 {
+		settings.Window = defaultWindow
+	}
+
+	if settings.KickReason == "" {
+		settings.KickReason = defaultKickReason
+	}
+
+	if settings.WarnMessage == nil {
+		settings.WarnMessage = defaultWarnMessage
+	}
+
+	m := &Monitor{
+		rcon:      rcon,
+		settings:  settings,
+		teamKills: make(map[string][]TeamKill),
+	}
+
+	if settings.EventBus != nil {
+		m.subscription = settings.EventBus.SubscribeFunc(squadrcon.SubscribeOptions{
+			Topics: []squadrcon.Topic{squadlog.TopicGameLog, squadrcon.TopicMatch},
+		}, func(event squadrcon.Event) {
+			m.HandleEvent(event.Payload)
+		})
+	}
+
+	return m
+}
+
+// HandleEvent processes a squadlog or match event. Wounds inflicted on team mates are handled as
+// team kills, new matches reset the counts. Other events are ignored.
+func (m *Monitor) HandleEvent(event any) {
+	switch e := event.(type) {
+	case squadlog.PlayerWoundedEvent:
+		if teamKill, ok := asTeamKill(e); ok {
+			m.HandleTeamKill(teamKill)
+		}
+	case squadlog.NewGameEvent, squadrcon.MatchStartedEvent:
+		m.ResetMatch()
+	}
+}
+
+// HandleTeamKill records a team kill and warns or kicks the attacker.
+func (m *Monitor) HandleTeamKill(teamKill TeamKill) {
+	key := squadrcon.PlayerKey(teamKill.Attacker)
+
+	m.lock.Lock()
+	var recent []TeamKill
+	for _, previous := range m.teamKills[key] {
+		if teamKill.Time.Sub(previous.Time) < m.settings.Window {
+			recent = append(recent, previous)
+		}
+	}
+	recent = append(recent, teamKill)
+	m.teamKills[key] = recent
+	m.lock.Unlock()
+
+	action := Action{
+		Time:     teamKill.Time,
+		Kind:     ActionWarn,
+		Player:   teamKill.Attacker,
+		TeamKill: teamKill,
+		Count:    len(recent),
+	}
+
+	if action.Count >= m.settings.KickThreshold {
+		action.Kind = ActionKick
+		action.Message = m.settings.KickReason
+	} else {
+		action.Message = m.settings.WarnMessage(teamKill, action.Count)
+	}
+
+	// Commands are executed unlocked so that a slow server does not block other events.
+	if !m.settings.DryRun {
+		switch action.Kind {
+		case ActionKick:
+			action.Err = squadrcon.AdminKickById(m.rcon, teamKill.Attacker.MatchId, action.Message)
+		default:
+			action.Err = squadrcon.AdminWarnById(m.rcon, teamKill.Attacker.MatchId, action.Message)
+		}
+	}
+
+	m.lock.Lock()
+	m.actions = append(m.actions, action)
+	m.lock.Unlock()
+
+	if m.settings.EventBus != nil {
+		m.settings.EventBus.Publish(TopicTeamKill, action)
+	}
+
+	if m.settings.OnAction != nil {
+		m.settings.OnAction(action)
+	}
+}
+
+// ResetMatch clears the team kill counts. Called automatically for new matches.
+func (m *Monitor) ResetMatch() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.teamKills = make(map[string][]TeamKill)
+}
+
+// TeamKills returns the team kills of the player in the current match that were within the window
+// of their last team kill, oldest first. All team kills are available through Actions.
+func (m *Monitor) TeamKills(player squadrcon.ActivePlayer) []TeamKill {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]TeamKill(nil), m.teamKills[squadrcon.PlayerKey(player)]...)
+}
+
+// Actions returns all actions taken since the monitor was created, oldest first.
+func (m *Monitor) Actions() []Action {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]Action(nil), m.actions...)
+}
+
+// Close stops receiving events from the event bus.
+func (m *Monitor) Close() {
+	if m.subscription != nil {
+		m.subscription.Unsubscribe()
+	}
+}
+
+// asTeamKill returns the team kill of a wound. Returns false if the wound is not a team kill or
+// the players could not be correlated.
+func asTeamKill(event squadlog.PlayerWoundedEvent) (TeamKill, bool) {
+	attacker := event.Attacker.Player
+	victim := event.Victim.Player
+
+	if attacker == nil || victim == nil {
+		return TeamKill{}, false
+	}
+
+	// Suicides are logged as wounds inflicted by the victim.
+	if squadrcon.PlayerKey(*attacker) == squadrcon.PlayerKey(*victim) {
+		return TeamKill{}, false
+	}
+
+	if attacker.TeamIndex != victim.TeamIndex {
+		return TeamKill{}, false
+	}
+
+	return TeamKill{
+		Time:     event.Time,
+		Attacker: *attacker,
+		Victim:   *victim,
+		Weapon:   event.Weapon,
+		Raw:      event.Raw,
+	}, true
+}
+
+func defaultWarnMessage(teamKill TeamKill, count int) string {
+	return fmt.Sprintf("You team killed %s (%d). Check your targets, repeated team kills result in a kick.", teamKill.Victim.Name, count)
+}
@@ -0,0 +1,140 @@
+package teamkill
+
+import (
+	"reflect"
+	"squad-rcon-go/pkg/squadlog"
+	"squad-rcon-go/pkg/squadrcon"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRcon records the executed commands.
+type fakeRcon struct {
+	lock     sync.Mutex
+	commands []string
+}
+
+func (r *fakeRcon) Execute(command string) (string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.commands = append(r.commands, command)
+	return "", nil
+}
+
+func (r *fakeRcon) Close() error {
+	return nil
+}
+
+func (r *fakeRcon) Commands() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]string(nil), r.commands...)
+}
+
+// wound returns a correlated wound of victim by attacker.
+func wound(at time.Time, attacker squadrcon.ActivePlayer, victim squadrcon.ActivePlayer) squadlog.PlayerWoundedEvent {
+	return squadlog.PlayerWoundedEvent{
+		LineHeader: squadlog.LineHeader{Time: at},
+		Attacker:   squadlog.PlayerRef{Player: &attacker},
+		Victim:     squadlog.PlayerRef{Player: &victim},
+		Weapon:     "BP_M4_M68",
+	}
+}
+
+var (
+	jon     = squadrcon.ActivePlayer{MatchId: 0, SteamId: 76561197999957991, Name: "Jon", TeamIndex: 1}
+	creaman = squadrcon.ActivePlayer{MatchId: 1, SteamId: 76561197989362395, Name: "creaman", TeamIndex: 1}
+	enemy   = squadrcon.ActivePlayer{MatchId: 2, EosId: "0002a10386f3487ba4b12b5e5f6a6a1a", Name: "enemy", TeamIndex: 2}
+)
+
+func TestMonitorWarnsAndKicks(t *testing.T) {
+	rcon := &fakeRcon{}
+	monitor := NewMonitor(rcon, MonitorSettings{
+		KickThreshold: 2,
+		WarnMessage: func(teamKill TeamKill, count int) string {
+			return "Stop"
+		},
+	})
+	start := time.Unix(1700000000, 0)
+
+	monitor.HandleEvent(wound(start, jon, creaman))
+	monitor.HandleEvent(wound(start.Add(time.Second), jon, enemy))
+	monitor.HandleEvent(wound(start.Add(2*time.Second), jon, jon))
+	monitor.HandleEvent(wound(start.Add(time.Minute), jon, creaman))
+
+	expected := []string{"AdminWarnById 0 Stop", "AdminKickById 0 Too many team kills"}
+	if commands := rcon.Commands(); !reflect.DeepEqual(commands, expected) {
+		t.Errorf("got %q, expected %q", commands, expected)
+	}
+
+	actions := monitor.Actions()
+	if len(actions) != 2 || actions[0].Kind != ActionWarn || actions[1].Kind != ActionKick || actions[1].Count != 2 {
+		t.Errorf("got %+v, expected a warning followed by a kick", actions)
+	}
+}
+
+func TestMonitorPlayersWithoutIds(t *testing.T) {
+	rcon := &fakeRcon{}
+	monitor := NewMonitor(rcon, MonitorSettings{})
+	start := time.Unix(1700000000, 0)
+
+	first := squadrcon.ActivePlayer{MatchId: 3, Name: "first", TeamIndex: 1}
+	second := squadrcon.ActivePlayer{MatchId: 4, Name: "second", TeamIndex: 1}
+
+	// Players without IDs must not be mistaken for the same player, which would be a suicide.
+	monitor.HandleEvent(wound(start, first, second))
+	monitor.HandleEvent(wound(start.Add(time.Second), second, first))
+
+	if teamKills := monitor.TeamKills(first); len(teamKills) != 1 || teamKills[0].Victim.MatchId != 4 {
+		t.Errorf("got %+v, expected one team kill of the first player", teamKills)
+	}
+	if teamKills := monitor.TeamKills(second); len(teamKills) != 1 || teamKills[0].Victim.MatchId != 3 {
+		t.Errorf("got %+v, expected one team kill of the second player", teamKills)
+	}
+
+	commands := rcon.Commands()
+	if len(commands) != 2 || !strings.HasPrefix(commands[0], "AdminWarnById 3 ") || !strings.HasPrefix(commands[1], "AdminWarnById 4 ") {
+		t.Errorf("got %q, expected both players to be warned by match ID", commands)
+	}
+}
+
+func TestMonitorIgnoresUncorrelatedWounds(t *testing.T) {
+	rcon := &fakeRcon{}
+	monitor := NewMonitor(rcon, MonitorSettings{})
+
+	event := wound(time.Unix(1700000000, 0), jon, creaman)
+	event.Attacker.Player = nil
+	monitor.HandleEvent(event)
+
+	if commands := rcon.Commands(); len(commands) != 0 {
+		t.Errorf("got %q, expected no commands", commands)
+	}
+}
+
+func TestMonitorWindowAndReset(t *testing.T) {
+	rcon := &fakeRcon{}
+	monitor := NewMonitor(rcon, MonitorSettings{Window: time.Minute, DryRun: true})
+	start := time.Unix(1700000000, 0)
+
+	monitor.HandleEvent(wound(start, jon, creaman))
+	monitor.HandleEvent(wound(start.Add(2*time.Minute), jon, creaman))
+	if teamKills := monitor.TeamKills(jon); len(teamKills) != 1 {
+		t.Errorf("got %d team kills, expected team kills outside the window to be dropped", len(teamKills))
+	}
+
+	monitor.HandleEvent(squadlog.NewGameEvent{})
+	if teamKills := monitor.TeamKills(jon); len(teamKills) != 0 {
+		t.Errorf("got %d team kills, expected a new game to reset the counts", len(teamKills))
+	}
+
+	if commands := rcon.Commands(); len(commands) != 0 {
+		t.Errorf("got %q, expected no commands in dry run mode", commands)
+	}
+	if actions := monitor.Actions(); len(actions) != 2 {
+		t.Errorf("got %d actions, expected dry run actions to be recorded", len(actions))
+	}
+}